@@ -0,0 +1,172 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Accumulator assembles a complete Response from stream events.
+type Accumulator struct {
+	message Response
+	inputs  map[int]*strings.Builder
+	done    bool
+}
+
+func NewAccumulator() *Accumulator {
+	return &Accumulator{inputs: map[int]*strings.Builder{}}
+}
+
+// Add applies event to the message being assembled. It returns an error if
+// a content block index is negative or more than one past the end, or if a
+// tool_use block finishes with input that is not valid JSON.
+func (a *Accumulator) Add(event Event) error {
+	switch e := event.(type) {
+	case MessageStartEvent:
+		if e.Message != nil {
+			a.message = *e.Message
+			a.message.Raw = nil
+			a.message.Content = append([]ContentBlock(nil), e.Message.Content...)
+			if e.Message.Usage != nil {
+				usage := *e.Message.Usage
+				a.message.Usage = &usage
+			}
+		}
+	case ContentBlockStartEvent:
+		block, err := a.block(e.Index)
+		if err != nil {
+			return err
+		}
+		if e.ContentBlock != nil {
+			*block = *e.ContentBlock
+		}
+		if e.ContentBlock != nil && (e.ContentBlock.Type == "tool_use" || e.ContentBlock.Type == "server_tool_use") {
+			a.inputs[e.Index] = &strings.Builder{}
+		}
+	case ContentBlockDeltaEvent:
+		block, err := a.block(e.Index)
+		if err != nil {
+			return err
+		}
+		switch d := e.Delta.(type) {
+		case TextDelta:
+			block.Text += d.Text
+		case ThinkingDelta:
+			block.Thinking += d.Thinking
+		case SignatureDelta:
+			block.Signature += d.Signature
+		case CitationsDelta:
+			if d.Citation != nil {
+				block.Citations = append(block.Citations, *d.Citation)
+			}
+		case InputJSONDelta:
+			input, ok := a.inputs[e.Index]
+			if !ok {
+				input = &strings.Builder{}
+				a.inputs[e.Index] = input
+			}
+			input.WriteString(d.PartialJSON)
+		}
+	case ContentBlockStopEvent:
+		input, ok := a.inputs[e.Index]
+		if !ok {
+			return nil
+		}
+		delete(a.inputs, e.Index)
+		block, err := a.block(e.Index)
+		if err != nil {
+			return err
+		}
+		raw := strings.TrimSpace(input.String())
+		if raw == "" {
+			if len(block.Input) == 0 {
+				block.Input = json.RawMessage("{}")
+			}
+			return nil
+		}
+		if !json.Valid([]byte(raw)) {
+			return fmt.Errorf("anthropic: invalid tool input for content block %d: %q", e.Index, raw)
+		}
+		block.Input = json.RawMessage(raw)
+	case MessageDeltaEvent:
+		if e.StopReason != "" {
+			a.message.StopReason = e.StopReason
+		}
+		if e.StopSequence != "" {
+			a.message.StopSequence = e.StopSequence
+		}
+		if e.Usage != nil {
+			a.mergeUsage(e.Usage)
+		}
+	case MessageStopEvent:
+		a.done = true
+	}
+	return nil
+}
+
+// block returns the content block at index, appending it if index is one
+// past the end. The block's Raw map is dropped because it no longer reflects
+// the block.
+func (a *Accumulator) block(index int) (*ContentBlock, error) {
+	if index < 0 || index > len(a.message.Content) {
+		return nil, fmt.Errorf("anthropic: content block index %d out of range", index)
+	}
+	if index == len(a.message.Content) {
+		a.message.Content = append(a.message.Content, ContentBlock{})
+	}
+	block := &a.message.Content[index]
+	block.Raw = nil
+	return block, nil
+}
+
+// mergeUsage applies message_delta usage, whose counts are cumulative and
+// only present when they changed since message_start.
+func (a *Accumulator) mergeUsage(u *Usage) {
+	if a.message.Usage == nil {
+		usage := *u
+		a.message.Usage = &usage
+		return
+	}
+	dst := a.message.Usage
+	if u.InputTokens != 0 {
+		dst.InputTokens = u.InputTokens
+	}
+	if u.OutputTokens != 0 {
+		dst.OutputTokens = u.OutputTokens
+	}
+	if u.CacheCreationInputTokens != 0 {
+		dst.CacheCreationInputTokens = u.CacheCreationInputTokens
+	}
+	if u.CacheReadInputTokens != 0 {
+		dst.CacheReadInputTokens = u.CacheReadInputTokens
+	}
+	if u.CacheCreation != nil {
+		dst.CacheCreation = u.CacheCreation
+	}
+	if u.ServerToolUse != nil {
+		dst.ServerToolUse = u.ServerToolUse
+	}
+	if u.ServiceTier != "" {
+		dst.ServiceTier = u.ServiceTier
+	}
+	if u.Cost != 0 {
+		dst.Cost = u.Cost
+	}
+	if u.IsBYOK != nil {
+		dst.IsBYOK = u.IsBYOK
+	}
+	if u.CostDetails != nil {
+		dst.CostDetails = u.CostDetails
+	}
+}
+
+// Message returns the message assembled so far.
+func (a *Accumulator) Message() *Response {
+	res := a.message
+	return &res
+}
+
+// Done reports whether message_stop has been seen.
+func (a *Accumulator) Done() bool {
+	return a.done
+}
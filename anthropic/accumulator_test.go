@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// eventStream returns a stream that yields events as server-sent events.
+func eventStream(events ...string) *Stream {
+	var body strings.Builder
+	for _, event := range events {
+		body.WriteString("data: " + event + "\n\n")
+	}
+	return NewStream(&http.Response{Body: io.NopCloser(strings.NewReader(body.String()))})
+}
+
+func TestAccumulator(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"usage":{"input_tokens":10,"output_tokens":1,"cache_read_input_tokens":90}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"check."}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Looking up."}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_1","name":"lookup","input":{}}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":""}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"q\": "}}`,
+		`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"\"x\"}"}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":42}}`,
+		`{"type":"message_stop"}`,
+	}
+	stream := eventStream(events...)
+	defer stream.Close()
+
+	acc := NewAccumulator()
+	var sawPing bool
+	for {
+		event, err := stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected recv error: %v", err)
+		}
+		if _, ok := event.(PingEvent); ok {
+			sawPing = true
+		}
+		if err := acc.Add(event); err != nil {
+			t.Fatalf("unexpected accumulate error: %v", err)
+		}
+	}
+	if !acc.Done() || !sawPing {
+		t.Fatalf("expected message_stop and ping, done=%v ping=%v", acc.Done(), sawPing)
+	}
+
+	msg := acc.Message()
+	if msg.ID != "msg_1" || msg.StopReason != "tool_use" || len(msg.Content) != 3 {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if thinking := msg.Content[0]; thinking.Thinking != "Let me check." || thinking.Signature != "sig-1" {
+		t.Fatalf("unexpected thinking block: %+v", thinking)
+	}
+	if msg.Content[1].Text != "Looking up." {
+		t.Fatalf("unexpected text block: %+v", msg.Content[1])
+	}
+	if tool := msg.Content[2]; tool.ID != "toolu_1" || string(tool.Input) != `{"q": "x"}` {
+		t.Fatalf("unexpected tool_use block: %+v (input %s)", tool, tool.Input)
+	}
+	if msg.Usage == nil || msg.Usage.InputTokens != 10 || msg.Usage.OutputTokens != 42 || msg.Usage.CacheReadInputTokens != 90 {
+		t.Fatalf("unexpected usage: %+v", msg.Usage)
+	}
+}
+
+func TestAccumulator_InvalidToolInput(t *testing.T) {
+	acc := NewAccumulator()
+	events := []Event{
+		ContentBlockStartEvent{Index: 0, ContentBlock: &ContentBlock{Type: "tool_use", ID: "toolu_1", Name: "lookup"}},
+		ContentBlockDeltaEvent{Index: 0, Delta: InputJSONDelta{PartialJSON: `{"q":`}},
+	}
+	for _, event := range events {
+		if err := acc.Add(event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := acc.Add(ContentBlockStopEvent{Index: 0}); err == nil {
+		t.Fatal("expected invalid tool input error")
+	}
+}
+
+func TestAccumulator_BadIndexes(t *testing.T) {
+	acc := NewAccumulator()
+	for i, event := range []Event{
+		ContentBlockStartEvent{Index: -1, ContentBlock: &ContentBlock{Type: "text"}},
+		ContentBlockDeltaEvent{Index: 1 << 30, Delta: TextDelta{Text: "x"}},
+		ContentBlockStartEvent{Index: 1},
+	} {
+		if err := acc.Add(event); err == nil {
+			t.Fatalf("event %d: expected an out of range error", i)
+		}
+	}
+	if n := len(acc.Message().Content); n != 0 {
+		t.Fatalf("rejected events grew the content to %d blocks", n)
+	}
+}
@@ -0,0 +1,405 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/iamwavecut/gopenrouter/internal/jsonx"
+)
+
+const (
+	BlockTypeText             = "text"
+	BlockTypeImage            = "image"
+	BlockTypeDocument         = "document"
+	BlockTypeToolUse          = "tool_use"
+	BlockTypeToolResult       = "tool_result"
+	BlockTypeThinking         = "thinking"
+	BlockTypeRedactedThinking = "redacted_thinking"
+)
+
+const (
+	SourceTypeBase64 = "base64"
+	SourceTypeURL    = "url"
+	SourceTypeText   = "text"
+)
+
+// ContentBlock is a single block of message content. Type selects which of
+// the fields are meaningful; use the New*Block constructors to build one.
+// Blocks of other types (server tool results, for example) are re-encoded
+// from Raw so they survive a round trip unchanged.
+//
+// The citations and content keys change shape with the type: citations
+// decode into Citations on text blocks and into CitationsConfig elsewhere,
+// and content decodes into Content on tool_result blocks and is kept as
+// RawContent elsewhere.
+type ContentBlock struct {
+	Type            string            `json:"type"`
+	Text            string            `json:"text,omitempty"`
+	Citations       []Citation        `json:"citations,omitempty"`
+	CitationsConfig *CitationsConfig  `json:"-"`
+	Source          *Source           `json:"source,omitempty"`
+	Title           string            `json:"title,omitempty"`
+	Context         string            `json:"context,omitempty"`
+	ID              string            `json:"id,omitempty"`
+	Name            string            `json:"name,omitempty"`
+	Input           json.RawMessage   `json:"input,omitempty"`
+	ToolUseID       string            `json:"tool_use_id,omitempty"`
+	Content         ToolResultContent `json:"content,omitempty"`
+	RawContent      json.RawMessage   `json:"-"`
+	IsError         bool              `json:"is_error,omitempty"`
+	Thinking        string            `json:"thinking,omitempty"`
+	Signature       string            `json:"signature,omitempty"`
+	Data            string            `json:"data,omitempty"`
+	CacheControl    *CacheControl     `json:"cache_control,omitempty"`
+	Raw             map[string]any    `json:"-"`
+}
+
+func (b ContentBlock) MarshalJSON() ([]byte, error) {
+	type alias ContentBlock
+	switch b.Type {
+	case BlockTypeText:
+		// An empty text block still needs its text key.
+		return json.Marshal(struct {
+			alias
+			Text string `json:"text"`
+		}{alias(b), b.Text})
+	case BlockTypeThinking:
+		return json.Marshal(struct {
+			alias
+			Thinking  string `json:"thinking"`
+			Signature string `json:"signature"`
+		}{alias(b), b.Thinking, b.Signature})
+	case BlockTypeImage, BlockTypeToolUse, BlockTypeToolResult, BlockTypeRedactedThinking:
+		return json.Marshal(alias(b))
+	case BlockTypeDocument:
+	default:
+		if b.Raw != nil {
+			return json.Marshal(b.Raw)
+		}
+	}
+	return json.Marshal(struct {
+		alias
+		Citations *CitationsConfig `json:"citations,omitempty"`
+		Content   json.RawMessage  `json:"content,omitempty"`
+	}{alias(b), b.CitationsConfig, b.RawContent})
+}
+
+func (b *ContentBlock) UnmarshalJSON(data []byte) error {
+	type alias ContentBlock
+	var decoded struct {
+		alias
+		Citations json.RawMessage `json:"citations"`
+		Content   json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	raw, err := jsonx.MarshalMap(json.RawMessage(data))
+	if err != nil {
+		return err
+	}
+	*b = ContentBlock(decoded.alias)
+	b.Raw = raw
+	if citations := decoded.Citations; len(citations) > 0 && string(citations) != "null" {
+		if b.Type == BlockTypeText {
+			err = json.Unmarshal(citations, &b.Citations)
+		} else {
+			err = json.Unmarshal(citations, &b.CitationsConfig)
+		}
+		if err != nil {
+			return fmt.Errorf("anthropic: decode %s citations: %w", b.Type, err)
+		}
+	}
+	if content := decoded.Content; len(content) > 0 && string(content) != "null" {
+		if b.Type != BlockTypeToolResult {
+			b.RawContent = content
+		} else if err := json.Unmarshal(content, &b.Content); err != nil {
+			return fmt.Errorf("anthropic: decode tool_result content: %w", err)
+		}
+	}
+	return nil
+}
+
+// ToolResultContent is the content of a tool_result block. It decodes from
+// either a plain string or an array of blocks.
+type ToolResultContent []ContentBlock
+
+func (c *ToolResultContent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*c = ToolResultContent{NewTextBlock(text)}
+		return nil
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
+}
+
+type Source struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+func Base64Source(mediaType, data string) *Source {
+	return &Source{Type: SourceTypeBase64, MediaType: mediaType, Data: data}
+}
+
+func URLSource(url string) *Source {
+	return &Source{Type: SourceTypeURL, URL: url}
+}
+
+// TextSource is a plain-text document source.
+func TextSource(text string) *Source {
+	return &Source{Type: SourceTypeText, MediaType: "text/plain", Data: text}
+}
+
+// CitationsConfig enables citations on a document or search result block.
+type CitationsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+const (
+	CitationTypeCharLocation            = "char_location"
+	CitationTypePageLocation            = "page_location"
+	CitationTypeContentBlockLocation    = "content_block_location"
+	CitationTypeSearchResultLocation    = "search_result_location"
+	CitationTypeWebSearchResultLocation = "web_search_result_location"
+)
+
+// Citation locates cited text in a document or search result. Which location
+// fields are set depends on Type.
+type Citation struct {
+	Type            string `json:"type"`
+	CitedText       string `json:"cited_text,omitempty"`
+	DocumentIndex   int    `json:"document_index,omitempty"`
+	DocumentTitle   string `json:"document_title,omitempty"`
+	StartCharIndex  int    `json:"start_char_index,omitempty"`
+	EndCharIndex    int    `json:"end_char_index,omitempty"`
+	StartPageNumber int    `json:"start_page_number,omitempty"`
+	EndPageNumber   int    `json:"end_page_number,omitempty"`
+	StartBlockIndex int    `json:"start_block_index,omitempty"`
+	EndBlockIndex   int    `json:"end_block_index,omitempty"`
+	FileID          string `json:"file_id,omitempty"`
+	URL             string `json:"url,omitempty"`
+	Title           string `json:"title,omitempty"`
+	EncryptedIndex  string `json:"encrypted_index,omitempty"`
+	// SearchResultIndex and Source locate a search_result_location.
+	SearchResultIndex int    `json:"search_result_index,omitempty"`
+	Source            string `json:"source,omitempty"`
+}
+
+// MarshalJSON writes the location fields of each citation type even when
+// they are zero, since zero is the first document or character.
+func (c Citation) MarshalJSON() ([]byte, error) {
+	type alias Citation
+	switch c.Type {
+	case CitationTypeCharLocation:
+		return json.Marshal(struct {
+			alias
+			DocumentIndex  int `json:"document_index"`
+			StartCharIndex int `json:"start_char_index"`
+			EndCharIndex   int `json:"end_char_index"`
+		}{alias(c), c.DocumentIndex, c.StartCharIndex, c.EndCharIndex})
+	case CitationTypePageLocation:
+		return json.Marshal(struct {
+			alias
+			DocumentIndex   int `json:"document_index"`
+			StartPageNumber int `json:"start_page_number"`
+			EndPageNumber   int `json:"end_page_number"`
+		}{alias(c), c.DocumentIndex, c.StartPageNumber, c.EndPageNumber})
+	case CitationTypeContentBlockLocation:
+		return json.Marshal(struct {
+			alias
+			DocumentIndex   int `json:"document_index"`
+			StartBlockIndex int `json:"start_block_index"`
+			EndBlockIndex   int `json:"end_block_index"`
+		}{alias(c), c.DocumentIndex, c.StartBlockIndex, c.EndBlockIndex})
+	case CitationTypeSearchResultLocation:
+		return json.Marshal(struct {
+			alias
+			SearchResultIndex int `json:"search_result_index"`
+			StartBlockIndex   int `json:"start_block_index"`
+			EndBlockIndex     int `json:"end_block_index"`
+		}{alias(c), c.SearchResultIndex, c.StartBlockIndex, c.EndBlockIndex})
+	}
+	return json.Marshal(alias(c))
+}
+
+func NewTextBlock(text string) ContentBlock {
+	return ContentBlock{Type: BlockTypeText, Text: text}
+}
+
+func NewImageBlock(source *Source) ContentBlock {
+	return ContentBlock{Type: BlockTypeImage, Source: source}
+}
+
+func NewDocumentBlock(source *Source, title string) ContentBlock {
+	return ContentBlock{Type: BlockTypeDocument, Source: source, Title: title}
+}
+
+// NewToolUseBlock encodes input as the tool_use input object.
+func NewToolUseBlock(id, name string, input any) (ContentBlock, error) {
+	raw, ok := input.(json.RawMessage)
+	if !ok {
+		if input == nil {
+			input = map[string]any{}
+		}
+		var err error
+		raw, err = json.Marshal(input)
+		if err != nil {
+			return ContentBlock{}, fmt.Errorf("anthropic: encode tool input: %w", err)
+		}
+	}
+	return ContentBlock{Type: BlockTypeToolUse, ID: id, Name: name, Input: raw}, nil
+}
+
+func NewToolResultBlock(toolUseID string, content ...ContentBlock) ContentBlock {
+	return ContentBlock{Type: BlockTypeToolResult, ToolUseID: toolUseID, Content: content}
+}
+
+func NewToolErrorBlock(toolUseID, message string) ContentBlock {
+	return ContentBlock{Type: BlockTypeToolResult, ToolUseID: toolUseID, Content: ToolResultContent{NewTextBlock(message)}, IsError: true}
+}
+
+func NewThinkingBlock(thinking, signature string) ContentBlock {
+	return ContentBlock{Type: BlockTypeThinking, Thinking: thinking, Signature: signature}
+}
+
+func NewRedactedThinkingBlock(data string) ContentBlock {
+	return ContentBlock{Type: BlockTypeRedactedThinking, Data: data}
+}
+
+// WithCacheControl returns a copy of b marked as a cache breakpoint.
+func (b ContentBlock) WithCacheControl(cacheControl *CacheControl) ContentBlock {
+	b.CacheControl = cacheControl
+	return b
+}
+
+func NewUserMessage(blocks ...ContentBlock) Message {
+	return Message{Role: "user", Content: blocks}
+}
+
+func NewAssistantMessage(blocks ...ContentBlock) Message {
+	return Message{Role: "assistant", Content: blocks}
+}
+
+// Blocks returns the message content as blocks. A string content becomes a
+// single text block; decoded JSON content is converted block by block.
+func (m Message) Blocks() ([]ContentBlock, error) {
+	switch content := m.Content.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []ContentBlock{NewTextBlock(content)}, nil
+	case []ContentBlock:
+		return content, nil
+	case ContentBlock:
+		return []ContentBlock{content}, nil
+	}
+	var blocks []ContentBlock
+	if err := remarshal(m.Content, &blocks); err != nil {
+		return nil, fmt.Errorf("anthropic: decode message content: %w", err)
+	}
+	return blocks, nil
+}
+
+// SystemBlock is a block of the system prompt.
+type SystemBlock struct {
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	Citations    []Citation    `json:"citations,omitempty"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+func NewSystemBlock(text string) SystemBlock {
+	return SystemBlock{Type: BlockTypeText, Text: text}
+}
+
+// WithCacheControl returns a copy of b marked as a cache breakpoint.
+func (b SystemBlock) WithCacheControl(cacheControl *CacheControl) SystemBlock {
+	b.CacheControl = cacheControl
+	return b
+}
+
+// SystemBlocks returns the system prompt as blocks. A string prompt becomes
+// a single text block.
+func (r Request) SystemBlocks() ([]SystemBlock, error) {
+	switch system := r.System.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []SystemBlock{NewSystemBlock(system)}, nil
+	case []SystemBlock:
+		return system, nil
+	}
+	var blocks []SystemBlock
+	if err := remarshal(r.System, &blocks); err != nil {
+		return nil, fmt.Errorf("anthropic: decode system prompt: %w", err)
+	}
+	return blocks, nil
+}
+
+const (
+	ToolChoiceAuto = "auto"
+	ToolChoiceAny  = "any"
+	ToolChoiceTool = "tool"
+	ToolChoiceNone = "none"
+)
+
+type ToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+func AutoToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceAuto}
+}
+
+func AnyToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceAny}
+}
+
+func NoToolChoice() *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceNone}
+}
+
+func NamedToolChoice(name string) *ToolChoice {
+	return &ToolChoice{Type: ToolChoiceTool, Name: name}
+}
+
+const (
+	ThinkingEnabled  = "enabled"
+	ThinkingDisabled = "disabled"
+)
+
+type ThinkingConfig struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+func EnabledThinking(budgetTokens int) *ThinkingConfig {
+	return &ThinkingConfig{Type: ThinkingEnabled, BudgetTokens: budgetTokens}
+}
+
+func DisabledThinking() *ThinkingConfig {
+	return &ThinkingConfig{Type: ThinkingDisabled}
+}
+
+func remarshal(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
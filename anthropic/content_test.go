@@ -0,0 +1,219 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestContentBlocks_RoundTrip(t *testing.T) {
+	toolUse, err := NewToolUseBlock("toolu_1", "lookup", map[string]any{"q": "x"})
+	if err != nil {
+		t.Fatalf("NewToolUseBlock: %v", err)
+	}
+	req := Request{
+		Model:      "anthropic/claude-sonnet-4",
+		MaxTokens:  1024,
+		System:     []SystemBlock{NewSystemBlock("Be terse.").WithCacheControl(&CacheControl{Type: "ephemeral"})},
+		ToolChoice: NamedToolChoice("lookup"),
+		Thinking:   EnabledThinking(2048),
+		Messages: []Message{
+			NewUserMessage(
+				NewTextBlock("Read this."),
+				NewImageBlock(Base64Source("image/png", "AAAA")),
+				NewDocumentBlock(URLSource("https://example.com/a.pdf"), "a.pdf"),
+			),
+			NewAssistantMessage(
+				NewThinkingBlock("Need to look it up.", "sig"),
+				NewRedactedThinkingBlock("opaque"),
+				toolUse,
+			),
+			NewUserMessage(
+				NewToolResultBlock("toolu_1", NewTextBlock("found")),
+				NewToolErrorBlock("toolu_2", "boom"),
+			),
+		},
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	var wire map[string]any
+	if err := json.Unmarshal(b, &wire); err != nil {
+		t.Fatalf("unmarshal wire: %v", err)
+	}
+	if wire["thinking"].(map[string]any)["budget_tokens"] != float64(2048) {
+		t.Fatalf("unexpected thinking: %v", wire["thinking"])
+	}
+	if choice := wire["tool_choice"].(map[string]any); choice["type"] != "tool" || choice["name"] != "lookup" {
+		t.Fatalf("unexpected tool choice: %v", choice)
+	}
+
+	var decoded Request
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	system, err := decoded.SystemBlocks()
+	if err != nil {
+		t.Fatalf("SystemBlocks: %v", err)
+	}
+	if !reflect.DeepEqual(system, req.System) {
+		t.Fatalf("system did not round-trip: %+v", system)
+	}
+	for i, message := range decoded.Messages {
+		got, err := message.Blocks()
+		if err != nil {
+			t.Fatalf("Blocks: %v", err)
+		}
+		want, _ := req.Messages[i].Blocks()
+		for j := range got {
+			got[j].Raw = nil
+			for k := range got[j].Content {
+				got[j].Content[k].Raw = nil
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("message %d did not round-trip:\n got %+v\nwant %+v", i, got, want)
+		}
+	}
+}
+
+func TestContentBlocks_Decode(t *testing.T) {
+	var msg Message
+	payload := `{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"plain text","is_error":true},{"type":"web_search_tool_result","tool_use_id":"srvtoolu_1","content":[{"type":"web_search_result","url":"https://example.com","encrypted_content":"e"}]}]}`
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		t.Fatalf("unmarshal message: %v", err)
+	}
+	blocks, err := msg.Blocks()
+	if err != nil {
+		t.Fatalf("Blocks: %v", err)
+	}
+	if len(blocks) != 2 || !blocks[0].IsError || len(blocks[0].Content) != 1 || blocks[0].Content[0].Text != "plain text" {
+		t.Fatalf("unexpected tool_result block: %+v", blocks)
+	}
+
+	// Blocks this package does not model are re-encoded verbatim.
+	b, err := json.Marshal(blocks[1])
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	if string(b) != `{"content":[{"encrypted_content":"e","type":"web_search_result","url":"https://example.com"}],"tool_use_id":"srvtoolu_1","type":"web_search_tool_result"}` {
+		t.Fatalf("unexpected server tool block: %s", b)
+	}
+
+	var text Message
+	if err := json.Unmarshal([]byte(`{"role":"user","content":"hello"}`), &text); err != nil {
+		t.Fatalf("unmarshal message: %v", err)
+	}
+	if blocks, err := text.Blocks(); err != nil || len(blocks) != 1 || blocks[0].Text != "hello" {
+		t.Fatalf("unexpected string content blocks: %+v %v", blocks, err)
+	}
+}
+
+func TestContentBlocks_ServerToolResults(t *testing.T) {
+	payload := `{
+		"id": "msg_01",
+		"type": "message",
+		"role": "assistant",
+		"model": "anthropic/claude-sonnet-4",
+		"content": [
+			{"type": "server_tool_use", "id": "srvtoolu_1", "name": "web_search", "input": {"query": "weather"}},
+			{"type": "web_search_tool_result", "tool_use_id": "srvtoolu_1", "content": {"type": "web_search_tool_result_error", "error_code": "max_uses_exceeded"}},
+			{"type": "server_tool_use", "id": "srvtoolu_2", "name": "code_execution", "input": {"code": "print(1)"}},
+			{"type": "code_execution_tool_result", "tool_use_id": "srvtoolu_2", "content": {"type": "code_execution_result", "stdout": "1\n", "stderr": "", "return_code": 0, "content": []}},
+			{"type": "text", "text": "Done."}
+		],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 10, "output_tokens": 5}
+	}`
+	var resp Response
+	if err := json.Unmarshal([]byte(payload), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if len(resp.Content) != 5 {
+		t.Fatalf("unexpected blocks: %+v", resp.Content)
+	}
+	for _, i := range []int{1, 3} {
+		block := resp.Content[i]
+		if block.Content != nil || len(block.RawContent) == 0 {
+			t.Fatalf("block %d content not kept raw: %+v", i, block)
+		}
+		// Without Raw the block is rebuilt from its fields.
+		block.Raw = nil
+		b, err := json.Marshal(block)
+		if err != nil {
+			t.Fatalf("marshal block %d: %v", i, err)
+		}
+		var got, want map[string]any
+		json.Unmarshal(b, &got)
+		json.Unmarshal(block.RawContent, &want)
+		if !reflect.DeepEqual(got["content"], want) || got["tool_use_id"] != block.ToolUseID {
+			t.Fatalf("block %d did not round-trip: %s", i, b)
+		}
+	}
+	var result struct {
+		Stdout     string `json:"stdout"`
+		ReturnCode int    `json:"return_code"`
+	}
+	if err := json.Unmarshal(resp.Content[3].RawContent, &result); err != nil || result.Stdout != "1\n" {
+		t.Fatalf("unexpected code execution result: %+v %v", result, err)
+	}
+}
+
+func TestContentBlocks_DocumentCitations(t *testing.T) {
+	block := NewDocumentBlock(TextSource("The sky is blue."), "sky")
+	block.CitationsConfig = &CitationsConfig{Enabled: true}
+	b, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	var wire map[string]any
+	json.Unmarshal(b, &wire)
+	if !reflect.DeepEqual(wire["citations"], map[string]any{"enabled": true}) {
+		t.Fatalf("unexpected citations: %s", b)
+	}
+
+	var decoded ContentBlock
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unmarshal block: %v", err)
+	}
+	if decoded.CitationsConfig == nil || !decoded.CitationsConfig.Enabled || decoded.Citations != nil {
+		t.Fatalf("unexpected decoded citations: %+v", decoded)
+	}
+}
+
+func TestCitations_ZeroLocations(t *testing.T) {
+	block := NewTextBlock("")
+	block.Citations = []Citation{
+		{Type: CitationTypeCharLocation, CitedText: "a", DocumentIndex: 0, StartCharIndex: 0, EndCharIndex: 1},
+		{Type: CitationTypePageLocation, CitedText: "b", DocumentIndex: 0, StartPageNumber: 1, EndPageNumber: 2},
+		{Type: CitationTypeContentBlockLocation, CitedText: "c", StartBlockIndex: 0, EndBlockIndex: 1},
+		{Type: CitationTypeSearchResultLocation, CitedText: "d", Source: "s", SearchResultIndex: 0, StartBlockIndex: 0, EndBlockIndex: 0},
+	}
+	b, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"type":"text","citations":[` +
+		`{"type":"char_location","cited_text":"a","document_index":0,"start_char_index":0,"end_char_index":1},` +
+		`{"type":"page_location","cited_text":"b","document_index":0,"start_page_number":1,"end_page_number":2},` +
+		`{"type":"content_block_location","cited_text":"c","document_index":0,"start_block_index":0,"end_block_index":1},` +
+		`{"type":"search_result_location","cited_text":"d","source":"s","search_result_index":0,"start_block_index":0,"end_block_index":0}],` +
+		`"text":""}`
+	if string(b) != want {
+		t.Fatalf("unexpected JSON:\n got %s\nwant %s", b, want)
+	}
+	var decoded ContentBlock
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	again, _ := json.Marshal(decoded)
+	if string(again) != want {
+		t.Fatalf("citations did not round-trip:\n got %s\nwant %s", again, want)
+	}
+
+	if b, _ := json.Marshal(NewThinkingBlock("", "")); string(b) != `{"type":"thinking","thinking":"","signature":""}` {
+		t.Fatalf("unexpected thinking block: %s", b)
+	}
+}
@@ -0,0 +1,166 @@
+package anthropic
+
+// Event is a typed messages stream event. Decoding yields one of the
+// concrete types in this file; unrecognized event types decode to
+// UnknownEvent.
+type Event interface {
+	EventType() string
+	isEvent()
+}
+
+type EventHeader struct {
+	Type string
+}
+
+func (h EventHeader) EventType() string { return h.Type }
+func (EventHeader) isEvent()            {}
+
+type MessageStartEvent struct {
+	EventHeader
+	Message *Response
+}
+
+type ContentBlockStartEvent struct {
+	EventHeader
+	Index        int
+	ContentBlock *ContentBlock
+}
+
+type ContentBlockDeltaEvent struct {
+	EventHeader
+	Index int
+	Delta Delta
+}
+
+type ContentBlockStopEvent struct {
+	EventHeader
+	Index int
+}
+
+type MessageDeltaEvent struct {
+	EventHeader
+	StopReason   string
+	StopSequence string
+	Usage        *Usage
+}
+
+type MessageStopEvent struct {
+	EventHeader
+}
+
+type PingEvent struct {
+	EventHeader
+}
+
+type ErrorEvent struct {
+	EventHeader
+	Error *APIError
+}
+
+type UnknownEvent struct {
+	EventHeader
+	Raw map[string]any
+}
+
+// Delta is the payload of a ContentBlockDeltaEvent.
+type Delta interface {
+	DeltaType() string
+	isDelta()
+}
+
+type TextDelta struct {
+	Text string
+}
+
+type InputJSONDelta struct {
+	PartialJSON string
+}
+
+type ThinkingDelta struct {
+	Thinking string
+}
+
+type SignatureDelta struct {
+	Signature string
+}
+
+type CitationsDelta struct {
+	Citation *Citation
+}
+
+type UnknownDelta struct {
+	Type string
+}
+
+func (TextDelta) DeltaType() string      { return "text_delta" }
+func (InputJSONDelta) DeltaType() string { return "input_json_delta" }
+func (ThinkingDelta) DeltaType() string  { return "thinking_delta" }
+func (SignatureDelta) DeltaType() string { return "signature_delta" }
+func (CitationsDelta) DeltaType() string { return "citations_delta" }
+func (d UnknownDelta) DeltaType() string { return d.Type }
+
+func (TextDelta) isDelta()      {}
+func (InputJSONDelta) isDelta() {}
+func (ThinkingDelta) isDelta()  {}
+func (SignatureDelta) isDelta() {}
+func (CitationsDelta) isDelta() {}
+func (UnknownDelta) isDelta()   {}
+
+// Typed returns the typed variant of e.
+func (e StreamEvent) Typed() Event {
+	h := EventHeader{Type: e.Type}
+	switch e.Type {
+	case "message_start":
+		return MessageStartEvent{EventHeader: h, Message: e.Message}
+	case "content_block_start":
+		return ContentBlockStartEvent{EventHeader: h, Index: e.Index, ContentBlock: e.ContentBlock}
+	case "content_block_delta":
+		return ContentBlockDeltaEvent{EventHeader: h, Index: e.Index, Delta: e.Delta.typed()}
+	case "content_block_stop":
+		return ContentBlockStopEvent{EventHeader: h, Index: e.Index}
+	case "message_delta":
+		event := MessageDeltaEvent{EventHeader: h, Usage: e.Usage}
+		if e.Delta != nil {
+			event.StopReason = e.Delta.StopReason
+			event.StopSequence = e.Delta.StopSequence
+		}
+		return event
+	case "message_stop":
+		return MessageStopEvent{EventHeader: h}
+	case "ping":
+		return PingEvent{EventHeader: h}
+	case "error":
+		return ErrorEvent{EventHeader: h, Error: e.Error}
+	default:
+		return UnknownEvent{EventHeader: h, Raw: e.Raw}
+	}
+}
+
+func (d *StreamDelta) typed() Delta {
+	if d == nil {
+		return UnknownDelta{}
+	}
+	switch d.Type {
+	case "text_delta":
+		return TextDelta{Text: d.Text}
+	case "input_json_delta":
+		return InputJSONDelta{PartialJSON: d.PartialJSON}
+	case "thinking_delta":
+		return ThinkingDelta{Thinking: d.Thinking}
+	case "signature_delta":
+		return SignatureDelta{Signature: d.Signature}
+	case "citations_delta":
+		return CitationsDelta{Citation: d.Citation}
+	default:
+		return UnknownDelta{Type: d.Type}
+	}
+}
+
+// RecvTyped is like Recv but returns the typed variant of the event.
+func (s *Stream) RecvTyped() (Event, error) {
+	event, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return event.Typed(), nil
+}
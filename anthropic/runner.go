@@ -0,0 +1,201 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	ErrMaxIterations = errors.New("anthropic: runner reached max iterations")
+	ErrMaxToolCalls  = errors.New("anthropic: runner reached max tool calls")
+	ErrCostLimit     = errors.New("anthropic: runner reached cost limit")
+)
+
+const defaultMaxIterations = 10
+
+// ToolFunc executes a tool_use block. input is the JSON the model sent.
+type ToolFunc func(ctx context.Context, input json.RawMessage) (string, error)
+
+// RunnerConfig configures a Runner.
+type RunnerConfig struct {
+	// Tools maps tool names to their handlers.
+	Tools map[string]ToolFunc
+	// MaxIterations bounds the number of model requests. Zero means 10.
+	MaxIterations int
+	// MaxToolCalls bounds the number of tool calls executed over the whole
+	// run. Zero means no limit.
+	MaxToolCalls int
+	// MaxCost stops the run once the accumulated cost reaches it. Zero
+	// disables the limit.
+	MaxCost float64
+	// OnEvent receives every stream event when the request has Stream set.
+	OnEvent func(Event)
+}
+
+// Runner drives a tool-calling loop: it sends the request, executes the
+// tool_use blocks in the response and sends tool_result blocks back until
+// the model stops for another reason. A pause_turn stop is resumed by
+// resending the conversation.
+type Runner struct {
+	backend backend
+	config  RunnerConfig
+}
+
+func NewRunner(backend backend, config RunnerConfig) *Runner {
+	return &Runner{backend: backend, config: config}
+}
+
+// RunResult is the outcome of a run. Messages holds the full conversation,
+// including the final assistant message.
+type RunResult struct {
+	Response   *Response
+	Messages   []Message
+	Iterations int
+	ToolCalls  int
+	Usage      NormalizedUsage
+}
+
+// Run executes the loop. When a limit is reached it returns the result so far
+// together with ErrMaxIterations, ErrMaxToolCalls or ErrCostLimit.
+// Disabling parallel tool use in the tool choice makes tools run one at a
+// time.
+func (r *Runner) Run(ctx context.Context, req Request) (*RunResult, error) {
+	maxIterations := r.config.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxIterations
+	}
+	parallel := req.ToolChoice == nil || !req.ToolChoice.DisableParallelToolUse
+
+	result := &RunResult{Messages: append([]Message(nil), req.Messages...)}
+	for {
+		if result.Iterations >= maxIterations {
+			return result, ErrMaxIterations
+		}
+		req.Messages = result.Messages
+		res, err := r.create(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		result.Iterations++
+		result.Response = res
+		if res.Usage != nil {
+			result.Usage = result.Usage.Add(res.Usage.Normalized())
+		}
+		result.Messages = append(result.Messages, NewAssistantMessage(res.Content...))
+
+		var calls []ContentBlock
+		for _, block := range res.Content {
+			if block.Type == BlockTypeToolUse {
+				calls = append(calls, block)
+			}
+		}
+		if res.StopReason == StopReasonPauseTurn {
+			// Resuming is another request, so it is held to the same
+			// limits; the iteration limit is checked at the top of the loop.
+			if r.config.MaxCost > 0 && result.Usage.Cost >= r.config.MaxCost {
+				return result, ErrCostLimit
+			}
+			continue
+		}
+		if res.StopReason != StopReasonToolUse || len(calls) == 0 {
+			return result, nil
+		}
+		if r.config.MaxToolCalls > 0 && result.ToolCalls+len(calls) > r.config.MaxToolCalls {
+			return result, ErrMaxToolCalls
+		}
+		if r.config.MaxCost > 0 && result.Usage.Cost >= r.config.MaxCost {
+			return result, ErrCostLimit
+		}
+		results, err := r.execute(ctx, calls, parallel)
+		if err != nil {
+			return result, err
+		}
+		result.ToolCalls += len(calls)
+		result.Messages = append(result.Messages, NewUserMessage(results...))
+	}
+}
+
+func (r *Runner) create(ctx context.Context, req Request) (*Response, error) {
+	if !req.Stream {
+		return r.backend.CreateAnthropicMessage(ctx, req)
+	}
+	stream, err := r.backend.CreateAnthropicMessageStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	acc := NewAccumulator()
+	for !acc.Done() {
+		event, err := stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.config.OnEvent != nil {
+			r.config.OnEvent(event)
+		}
+		if err := acc.Add(event); err != nil {
+			return nil, err
+		}
+	}
+	if !acc.Done() {
+		// The stream ended without its final event.
+		return nil, io.ErrUnexpectedEOF
+	}
+	return acc.Message(), nil
+}
+
+// execute runs the tool calls, concurrently when parallel is set, and
+// returns their tool_result blocks in call order. Handler errors and unknown
+// tools are reported to the model; only context cancellation aborts the run.
+func (r *Runner) execute(ctx context.Context, calls []ContentBlock, parallel bool) ([]ContentBlock, error) {
+	results := make([]ContentBlock, len(calls))
+	run := func(i int) {
+		results[i] = r.call(ctx, calls[i])
+	}
+	if parallel && len(calls) > 1 {
+		var wg sync.WaitGroup
+		wg.Add(len(calls))
+		for i := range calls {
+			go func() {
+				defer wg.Done()
+				run(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range calls {
+			run(i)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *Runner) call(ctx context.Context, call ContentBlock) ContentBlock {
+	fn, ok := r.config.Tools[call.Name]
+	if !ok {
+		return NewToolErrorBlock(call.ID, fmt.Sprintf("unknown tool %q", call.Name))
+	}
+	input := call.Input
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+	output, err := fn(ctx, input)
+	if err != nil {
+		return NewToolErrorBlock(call.ID, err.Error())
+	}
+	if output == "" {
+		// The API rejects empty text blocks.
+		return NewToolResultBlock(call.ID)
+	}
+	return NewToolResultBlock(call.ID, NewTextBlock(output))
+}
@@ -0,0 +1,198 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// scriptedBackend replies to successive requests with the given bodies,
+// decoded as a response or, for streaming requests, read as server-sent
+// events. It records every request as a JSON object.
+type scriptedBackend struct {
+	replies  []string
+	requests []map[string]any
+}
+
+func (b *scriptedBackend) next(req Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", err
+	}
+	b.requests = append(b.requests, body)
+	if len(b.requests) > len(b.replies) {
+		return "", fmt.Errorf("unexpected request %d", len(b.requests))
+	}
+	return b.replies[len(b.requests)-1], nil
+}
+
+func (b *scriptedBackend) CreateAnthropicMessage(ctx context.Context, req Request) (*Response, error) {
+	reply, err := b.next(req)
+	if err != nil {
+		return nil, err
+	}
+	var res Response
+	if err := json.Unmarshal([]byte(reply), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (b *scriptedBackend) CreateAnthropicMessageStream(ctx context.Context, req Request) (*Stream, error) {
+	reply, err := b.next(req)
+	if err != nil {
+		return nil, err
+	}
+	return NewStream(&http.Response{Body: io.NopCloser(strings.NewReader(reply))}), nil
+}
+
+func echoTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ City string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.City == "" {
+		return "", errors.New("city is required")
+	}
+	return "sunny in " + in.City, nil
+}
+
+func TestRunner(t *testing.T) {
+	backend := &scriptedBackend{replies: []string{
+		`{"id":"msg_1","type":"message","role":"assistant","content":[
+			{"type":"text","text":"Checking."},
+			{"type":"tool_use","id":"toolu_1","name":"weather","input":{"city":"Paris"}},
+			{"type":"tool_use","id":"toolu_2","name":"missing","input":{}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5,"cost":0.01}}`,
+		`{"id":"msg_2","type":"message","role":"assistant","content":[{"type":"text","text":"Sunny."}],"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":2}}`,
+	}}
+	runner := NewRunner(backend, RunnerConfig{Tools: map[string]ToolFunc{"weather": echoTool}})
+
+	result, err := runner.Run(context.Background(), Request{
+		Model:      "anthropic/claude-sonnet-4",
+		MaxTokens:  1024,
+		ToolChoice: &ToolChoice{Type: ToolChoiceAuto, DisableParallelToolUse: true},
+		Messages:   []Message{NewUserMessage(NewTextBlock("Weather in Paris?"))},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Response.ID != "msg_2" || result.Iterations != 2 || result.ToolCalls != 2 || len(result.Messages) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Usage.InputTokens != 30 || result.Usage.OutputTokens != 7 {
+		t.Fatalf("unexpected usage: %+v", result.Usage)
+	}
+
+	messages := backend.requests[1]["messages"].([]any)
+	results := messages[2].(map[string]any)["content"].([]any)
+	ok, missing := results[0].(map[string]any), results[1].(map[string]any)
+	if ok["type"] != "tool_result" || ok["tool_use_id"] != "toolu_1" || ok["is_error"] != nil {
+		t.Fatalf("unexpected tool result: %v", ok)
+	}
+	if missing["tool_use_id"] != "toolu_2" || missing["is_error"] != true {
+		t.Fatalf("expected error tool result: %v", missing)
+	}
+}
+
+func TestRunner_EmptyToolOutput(t *testing.T) {
+	backend := &scriptedBackend{replies: []string{
+		`{"id":"msg_1","type":"message","role":"assistant","content":[
+			{"type":"tool_use","id":"toolu_1","name":"noop","input":{}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5}}`,
+		`{"id":"msg_2","type":"message","role":"assistant","content":[{"type":"text","text":"Done."}],"stop_reason":"end_turn","usage":{"input_tokens":20,"output_tokens":2}}`,
+	}}
+	noop := func(ctx context.Context, args json.RawMessage) (string, error) { return "", nil }
+	runner := NewRunner(backend, RunnerConfig{Tools: map[string]ToolFunc{"noop": noop}})
+
+	if _, err := runner.Run(context.Background(), Request{
+		Model:     "anthropic/claude-sonnet-4",
+		MaxTokens: 1024,
+		Messages:  []Message{NewUserMessage(NewTextBlock("Do nothing."))},
+	}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	messages := backend.requests[1]["messages"].([]any)
+	result := messages[2].(map[string]any)["content"].([]any)[0].(map[string]any)
+	if result["type"] != "tool_result" || result["tool_use_id"] != "toolu_1" {
+		t.Fatalf("unexpected tool result: %v", result)
+	}
+	if content, ok := result["content"]; ok {
+		t.Fatalf("expected no content for an empty output, got %v", content)
+	}
+}
+
+func TestRunner_StreamAndLimits(t *testing.T) {
+	stream := strings.Join([]string{
+		`event: message_start` + "\n" + `data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[],"usage":{"input_tokens":5,"output_tokens":1}}}`,
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"weather","input":{}}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Oslo\"}"}}`,
+		`event: content_block_stop` + "\n" + `data: {"type":"content_block_stop","index":0}`,
+		`event: message_delta` + "\n" + `data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":3,"cost":2}}`,
+		`event: message_stop` + "\n" + `data: {"type":"message_stop"}`,
+	}, "\n\n") + "\n\n"
+	backend := &scriptedBackend{replies: []string{stream}}
+	var seen int
+	runner := NewRunner(backend, RunnerConfig{
+		Tools:   map[string]ToolFunc{"weather": echoTool},
+		MaxCost: 1,
+		OnEvent: func(Event) { seen++ },
+	})
+
+	result, err := runner.Run(context.Background(), Request{
+		Model:     "anthropic/claude-sonnet-4",
+		MaxTokens: 1024,
+		Stream:    true,
+		Messages:  []Message{NewUserMessage(NewTextBlock("Weather?"))},
+	})
+	if !errors.Is(err, ErrCostLimit) {
+		t.Fatalf("expected cost limit, got %v", err)
+	}
+	if seen != 6 || string(result.Response.Content[0].Input) != `{"city":"Oslo"}` || result.ToolCalls != 0 {
+		t.Fatalf("unexpected stream result: seen %d, %+v", seen, result)
+	}
+}
+
+func TestRunner_PauseTurnLimits(t *testing.T) {
+	paused := `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Searching."}],"stop_reason":"pause_turn","usage":{"input_tokens":10,"output_tokens":5,"cost":2}}`
+	req := Request{
+		Model:     "anthropic/claude-sonnet-4",
+		MaxTokens: 1024,
+		Messages:  []Message{NewUserMessage(NewTextBlock("Search the web."))},
+	}
+
+	backend := &scriptedBackend{replies: []string{paused}}
+	result, err := NewRunner(backend, RunnerConfig{MaxCost: 1}).Run(context.Background(), req)
+	if !errors.Is(err, ErrCostLimit) || len(backend.requests) != 1 || result.Response.ID != "msg_1" {
+		t.Fatalf("expected the cost limit before resuming, got %v after %d requests", err, len(backend.requests))
+	}
+
+	backend = &scriptedBackend{replies: []string{paused, paused}}
+	if _, err := NewRunner(backend, RunnerConfig{MaxIterations: 2}).Run(context.Background(), req); !errors.Is(err, ErrMaxIterations) || len(backend.requests) != 2 {
+		t.Fatalf("expected the iteration limit before resuming, got %v after %d requests", err, len(backend.requests))
+	}
+}
+
+func TestRunner_TruncatedStream(t *testing.T) {
+	stream := `event: message_start` + "\n" + `data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[]}}` + "\n\n" +
+		`event: content_block_start` + "\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}` + "\n\n"
+	backend := &scriptedBackend{replies: []string{stream}}
+	_, err := NewRunner(backend, RunnerConfig{}).Run(context.Background(), Request{
+		Model:     "anthropic/claude-sonnet-4",
+		MaxTokens: 1024,
+		Stream:    true,
+		Messages:  []Message{NewUserMessage(NewTextBlock("Hi"))},
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected an unexpected EOF, got %v", err)
+	}
+}
@@ -14,6 +14,9 @@ type (
 	CacheControl        = shared.CacheControl
 	Provider            = shared.Provider
 	ProviderPreferences = shared.ProviderPreferences
+	CostDetails         = shared.CostDetails
+	ServerToolUse       = shared.ServerToolUse
+	NormalizedUsage     = shared.NormalizedUsage
 )
 
 type Request struct {
@@ -28,8 +31,8 @@ type Request struct {
 	TopP          *float64             `json:"top_p,omitempty"`
 	TopK          *float64             `json:"top_k,omitempty"`
 	Tools         []Tool               `json:"tools,omitempty"`
-	ToolChoice    any                  `json:"tool_choice,omitempty"`
-	Thinking      any                  `json:"thinking,omitempty"`
+	ToolChoice    *ToolChoice          `json:"tool_choice,omitempty"`
+	Thinking      *ThinkingConfig      `json:"thinking,omitempty"`
 	ServiceTier   string               `json:"service_tier,omitempty"`
 	Provider      *ProviderPreferences `json:"provider,omitempty"`
 	ExtraBody     map[string]any       `json:"-"`
@@ -67,6 +70,13 @@ type Tool struct {
 	CacheControl   *CacheControl  `json:"cache_control,omitempty"`
 }
 
+const (
+	StopReasonEndTurn   = "end_turn"
+	StopReasonMaxTokens = "max_tokens"
+	StopReasonToolUse   = "tool_use"
+	StopReasonPauseTurn = "pause_turn"
+)
+
 type Response struct {
 	ID           string         `json:"id,omitempty"`
 	Type         string         `json:"type,omitempty"`
@@ -75,7 +85,7 @@ type Response struct {
 	Content      []ContentBlock `json:"content,omitempty"`
 	StopReason   string         `json:"stop_reason,omitempty"`
 	StopSequence string         `json:"stop_sequence,omitempty"`
-	Usage        map[string]any `json:"usage,omitempty"`
+	Usage        *Usage         `json:"usage,omitempty"`
 	Raw          map[string]any `json:"-"`
 }
 
@@ -94,27 +104,41 @@ func (r *Response) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-type ContentBlock struct {
-	Type string         `json:"type,omitempty"`
-	Text string         `json:"text,omitempty"`
-	ID   string         `json:"id,omitempty"`
-	Name string         `json:"name,omitempty"`
-	Raw  map[string]any `json:"-"`
+// Usage reports Anthropic-style token counts, where InputTokens excludes
+// cache reads and writes.
+type Usage struct {
+	InputTokens              int            `json:"input_tokens"`
+	OutputTokens             int            `json:"output_tokens"`
+	CacheCreationInputTokens int            `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int            `json:"cache_read_input_tokens,omitempty"`
+	CacheCreation            *CacheCreation `json:"cache_creation,omitempty"`
+	ServerToolUse            *ServerToolUse `json:"server_tool_use,omitempty"`
+	ServiceTier              string         `json:"service_tier,omitempty"`
+	InferenceGeo             string         `json:"inference_geo,omitempty"`
+	Cost                     float64        `json:"cost,omitempty"`
+	IsBYOK                   *bool          `json:"is_byok,omitempty"`
+	CostDetails              *CostDetails   `json:"cost_details,omitempty"`
 }
 
-func (b *ContentBlock) UnmarshalJSON(data []byte) error {
-	type alias ContentBlock
-	var decoded alias
-	if err := json.Unmarshal(data, &decoded); err != nil {
-		return err
+type CacheCreation struct {
+	Ephemeral5mInputTokens int `json:"ephemeral_5m_input_tokens,omitempty"`
+	Ephemeral1hInputTokens int `json:"ephemeral_1h_input_tokens,omitempty"`
+}
+
+// Normalized returns the dialect-independent view of u.
+func (u Usage) Normalized() NormalizedUsage {
+	out := NormalizedUsage{
+		InputTokens:      u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens,
+		OutputTokens:     u.OutputTokens,
+		CachedTokens:     u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
 	}
-	raw, err := jsonx.MarshalMap(json.RawMessage(data))
-	if err != nil {
-		return err
+	out.TotalTokens = out.InputTokens + out.OutputTokens
+	if u.ServerToolUse != nil {
+		out.ServerToolUse = *u.ServerToolUse
 	}
-	*b = ContentBlock(decoded)
-	b.Raw = raw
-	return nil
+	out.SetCost(u.Cost, u.IsBYOK, u.CostDetails)
+	return out
 }
 
 type Stream struct {
@@ -126,11 +150,27 @@ func NewStream(resp *http.Response) *Stream {
 }
 
 type StreamEvent struct {
-	Event string
-	Type  string         `json:"type,omitempty"`
-	Index int            `json:"index,omitempty"`
-	Error *APIError      `json:"error,omitempty"`
-	Raw   map[string]any `json:"-"`
+	Event        string
+	Type         string         `json:"type,omitempty"`
+	Index        int            `json:"index,omitempty"`
+	Message      *Response      `json:"message,omitempty"`
+	ContentBlock *ContentBlock  `json:"content_block,omitempty"`
+	Delta        *StreamDelta   `json:"delta,omitempty"`
+	Usage        *Usage         `json:"usage,omitempty"`
+	Error        *APIError      `json:"error,omitempty"`
+	Raw          map[string]any `json:"-"`
+}
+
+// StreamDelta holds the delta of a content_block_delta or message_delta event.
+type StreamDelta struct {
+	Type         string    `json:"type,omitempty"`
+	Text         string    `json:"text,omitempty"`
+	PartialJSON  string    `json:"partial_json,omitempty"`
+	Thinking     string    `json:"thinking,omitempty"`
+	Signature    string    `json:"signature,omitempty"`
+	Citation     *Citation `json:"citation,omitempty"`
+	StopReason   string    `json:"stop_reason,omitempty"`
+	StopSequence string    `json:"stop_sequence,omitempty"`
 }
 
 func (e *StreamEvent) UnmarshalJSON(data []byte) error {
@@ -0,0 +1,514 @@
+// Package batch runs large sets of requests from JSONL files. Each input
+// line is a Record with a custom ID; each finished request becomes one
+// Result line in the output. Runs are resumable: IDs that already have a
+// successful result in the output are skipped.
+package batch
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+type backend interface {
+	CreateChatCompletion(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error)
+	CreateResponse(ctx context.Context, req responses.Request) (*responses.Response, error)
+	CreateEmbeddings(ctx context.Context, req embeddings.Request) (*embeddings.Response, error)
+}
+
+// Endpoints a Record can target. A "/v1" prefix is accepted as well.
+const (
+	EndpointChat       = "/chat/completions"
+	EndpointResponses  = "/responses"
+	EndpointEmbeddings = "/embeddings"
+)
+
+// maxLineSize bounds one input or output line.
+const maxLineSize = 64 << 20
+
+// Record is one input line. Body is a ChatCompletionRequest, a
+// responses.Request or an embeddings.Request, depending on URL.
+type Record struct {
+	CustomID string          `json:"custom_id"`
+	URL      string          `json:"url,omitempty"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// Result is one output line. Response holds the endpoint's response on
+// success; Error is set on failure.
+type Result struct {
+	CustomID  string                  `json:"custom_id"`
+	URL       string                  `json:"url,omitempty"`
+	Model     string                  `json:"model,omitempty"`
+	Response  json.RawMessage         `json:"response,omitempty"`
+	Usage     *shared.NormalizedUsage `json:"usage,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+	Status    int                     `json:"status,omitempty"`
+	Attempts  int                     `json:"attempts"`
+	LatencyMS int64                   `json:"latency_ms"`
+}
+
+// Config configures a Runner.
+type Config struct {
+	// Concurrency bounds the requests in flight. Zero means 8.
+	Concurrency int
+	// ModelConcurrency bounds the requests in flight per model; models not
+	// listed are only bound by Concurrency.
+	ModelConcurrency map[string]int
+	// Endpoint is used for records without a URL. Zero means EndpointChat.
+	Endpoint string
+	// MaxAttempts bounds the attempts per record. Zero means 3.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles after each
+	// attempt. Zero means 1s.
+	RetryDelay time.Duration
+	// Retryable reports whether a failed attempt should be retried. Nil
+	// retries rate limits, timeouts, server errors and transport failures.
+	Retryable func(error) bool
+	// Budget stops the run once the reported cost reaches it, in USD. Zero
+	// means no budget. Requests in flight still finish, so the total can
+	// exceed Budget by up to Concurrency requests.
+	Budget float64
+	// OnResult is called after each result is written. Calls are
+	// serialized.
+	OnResult func(Result)
+}
+
+// Summary describes a run.
+type Summary struct {
+	// Read counts the input records; Skipped the ones that already had a
+	// result; Unfinished the ones left for a later run by the budget or a
+	// cancelled context.
+	Read       int
+	Skipped    int
+	Succeeded  int
+	Failed     int
+	Unfinished int
+	// BudgetExceeded is set when the run stopped at the budget.
+	BudgetExceeded bool
+	Usage          shared.NormalizedUsage
+	Duration       time.Duration
+	// Latency percentiles of the successful requests, retries included.
+	LatencyP50 time.Duration
+	LatencyP90 time.Duration
+	LatencyP99 time.Duration
+	LatencyMax time.Duration
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("read %d, skipped %d, succeeded %d, failed %d, unfinished %d\n"+
+		"tokens %d in, %d out, %d total; cost $%.6f\n"+
+		"latency p50 %s, p90 %s, p99 %s, max %s; took %s",
+		s.Read, s.Skipped, s.Succeeded, s.Failed, s.Unfinished,
+		s.Usage.InputTokens, s.Usage.OutputTokens, s.Usage.TotalTokens, s.Usage.Cost,
+		s.LatencyP50, s.LatencyP90, s.LatencyP99, s.LatencyMax, s.Duration.Round(time.Millisecond))
+}
+
+// Runner executes records with bounded concurrency.
+type Runner struct {
+	backend backend
+	config  Config
+}
+
+// New returns a Runner that sends requests through backend.
+func New(backend backend, config Config) *Runner {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = EndpointChat
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	if config.Retryable == nil {
+		config.Retryable = Retryable
+	}
+	return &Runner{backend: backend, config: config}
+}
+
+// RunFile runs the records of the input file and appends results to the
+// output file, skipping custom IDs that already succeeded there.
+func (r *Runner) RunFile(ctx context.Context, inPath, outPath string) (*Summary, error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	done, err := Completed(out)
+	if err != nil {
+		return nil, err
+	}
+	// A crash can leave a partial last line; start on a fresh one.
+	if info, err := out.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := out.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := out.Write([]byte("\n")); err != nil {
+				return nil, err
+			}
+		}
+	}
+	summary, err := r.Run(ctx, in, out, done)
+	if syncErr := out.Sync(); err == nil {
+		err = syncErr
+	}
+	return summary, err
+}
+
+// Completed reads an output stream and returns the custom IDs that
+// succeeded. Lines that do not parse, such as one cut short by a crash, are
+// ignored.
+func Completed(out io.Reader) (map[string]bool, error) {
+	done := make(map[string]bool)
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		var res Result
+		if json.Unmarshal(scanner.Bytes(), &res) == nil && res.Error == "" && res.CustomID != "" {
+			done[res.CustomID] = true
+		}
+	}
+	return done, scanner.Err()
+}
+
+type job struct {
+	record Record
+	url    string
+	model  string
+	call   func(context.Context) (any, *shared.NormalizedUsage, error)
+}
+
+type run struct {
+	*Runner
+	ctx       context.Context
+	out       *json.Encoder
+	models    map[string]chan struct{}
+	global    chan struct{}
+	mu        sync.Mutex
+	summary   Summary
+	latencies []time.Duration
+	err       error
+	stopped   bool
+}
+
+// Run executes the records read from in and writes a Result line to out
+// for each finished one. Records whose custom ID is in done are skipped.
+// A record that cannot be decoded gets a failed result. When ctx is done
+// or the budget is spent, records not yet finished are left out of the
+// output so a later run picks them up.
+func (r *Runner) Run(ctx context.Context, in io.Reader, out io.Writer, done map[string]bool) (*Summary, error) {
+	start := time.Now()
+	rn := &run{
+		Runner: r,
+		ctx:    ctx,
+		out:    json.NewEncoder(out),
+		models: make(map[string]chan struct{}),
+		global: make(chan struct{}, r.config.Concurrency),
+	}
+	seen := make(map[string]bool)
+	// queued bounds the records started but not finished, so the input is
+	// not read ahead without limit. A record waiting for its model's slot
+	// holds a queued slot too: a run of records for a saturated model can
+	// fill the queue and delay the records after it until it drains.
+	queued := make(chan struct{}, 4*r.config.Concurrency)
+	var wg sync.WaitGroup
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		rn.mu.Lock()
+		rn.summary.Read++
+		stopped := rn.stopped
+		rn.mu.Unlock()
+		if stopped || ctx.Err() != nil {
+			rn.unfinished()
+			continue
+		}
+
+		j, err := r.decode(scanner.Bytes())
+		switch {
+		case err != nil:
+			rn.write(Result{CustomID: j.record.CustomID, URL: j.url, Error: fmt.Sprintf("line %d: %v", line, err)}, 0)
+			continue
+		case done[j.record.CustomID]:
+			rn.mu.Lock()
+			rn.summary.Skipped++
+			rn.mu.Unlock()
+			continue
+		case seen[j.record.CustomID]:
+			rn.write(Result{CustomID: j.record.CustomID, URL: j.url, Model: j.model, Error: fmt.Sprintf("line %d: duplicate custom_id", line)}, 0)
+			continue
+		}
+		seen[j.record.CustomID] = true
+
+		select {
+		case queued <- struct{}{}:
+		case <-ctx.Done():
+			rn.unfinished()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-queued }()
+			rn.execute(j)
+		}()
+	}
+	wg.Wait()
+
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	s := rn.summary
+	s.Duration = time.Since(start)
+	slices.Sort(rn.latencies)
+	s.LatencyP50 = percentile(rn.latencies, 50)
+	s.LatencyP90 = percentile(rn.latencies, 90)
+	s.LatencyP99 = percentile(rn.latencies, 99)
+	if n := len(rn.latencies); n > 0 {
+		s.LatencyMax = rn.latencies[n-1]
+	}
+	err := rn.err
+	if err == nil {
+		err = scanner.Err()
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return &s, err
+}
+
+// decode parses one input line. The returned job carries what is known
+// even on error, for the failed result.
+func (r *Runner) decode(data []byte) (job, error) {
+	var j job
+	if err := json.Unmarshal(data, &j.record); err != nil {
+		return j, fmt.Errorf("batch: invalid record: %w", err)
+	}
+	if j.record.CustomID == "" {
+		return j, errors.New("batch: missing custom_id")
+	}
+	j.url = cmp.Or(j.record.URL, r.config.Endpoint)
+	switch strings.TrimPrefix(j.url, "/v1") {
+	case EndpointChat:
+		var req gopenrouter.ChatCompletionRequest
+		if err := json.Unmarshal(j.record.Body, &req); err != nil {
+			return j, fmt.Errorf("batch: invalid chat completion request: %w", err)
+		}
+		j.model = req.Model
+		j.call = func(ctx context.Context) (any, *shared.NormalizedUsage, error) {
+			res, err := r.backend.CreateChatCompletion(ctx, req)
+			if err != nil {
+				return nil, nil, err
+			}
+			usage := res.Usage.Normalized()
+			return res, &usage, nil
+		}
+	case EndpointResponses:
+		var req responses.Request
+		if err := json.Unmarshal(j.record.Body, &req); err != nil {
+			return j, fmt.Errorf("batch: invalid responses request: %w", err)
+		}
+		j.model = req.Model
+		j.call = func(ctx context.Context) (any, *shared.NormalizedUsage, error) {
+			res, err := r.backend.CreateResponse(ctx, req)
+			if err != nil {
+				return nil, nil, err
+			}
+			var usage *shared.NormalizedUsage
+			if res.Usage != nil {
+				u := res.Usage.Normalized()
+				usage = &u
+			}
+			return res, usage, nil
+		}
+	case EndpointEmbeddings:
+		var req embeddings.Request
+		if err := json.Unmarshal(j.record.Body, &req); err != nil {
+			return j, fmt.Errorf("batch: invalid embeddings request: %w", err)
+		}
+		j.model = req.Model
+		j.call = func(ctx context.Context) (any, *shared.NormalizedUsage, error) {
+			res, err := r.backend.CreateEmbeddings(ctx, req)
+			if err != nil {
+				return nil, nil, err
+			}
+			var usage *shared.NormalizedUsage
+			if res.Usage != nil {
+				u := res.Usage.Normalized()
+				usage = &u
+			}
+			return res, usage, nil
+		}
+	default:
+		return j, fmt.Errorf("batch: unsupported url %q", j.url)
+	}
+	return j, nil
+}
+
+func (rn *run) modelSlots(model string) chan struct{} {
+	n := rn.config.ModelConcurrency[model]
+	if n <= 0 {
+		return nil
+	}
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	slots, ok := rn.models[model]
+	if !ok {
+		slots = make(chan struct{}, n)
+		rn.models[model] = slots
+	}
+	return slots
+}
+
+func acquire(ctx context.Context, slots chan struct{}) bool {
+	if slots == nil {
+		return true
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
+
+func (rn *run) execute(j job) {
+	modelSlots := rn.modelSlots(j.model)
+	if !acquire(rn.ctx, modelSlots) {
+		rn.unfinished()
+		return
+	}
+	defer release(modelSlots)
+	if !acquire(rn.ctx, rn.global) {
+		rn.unfinished()
+		return
+	}
+	defer release(rn.global)
+
+	start := time.Now()
+	delay := rn.config.RetryDelay
+	res := Result{CustomID: j.record.CustomID, URL: j.url, Model: j.model}
+	for {
+		if rn.overBudget() {
+			rn.unfinished()
+			return
+		}
+		res.Attempts++
+		body, usage, err := j.call(rn.ctx)
+		if err == nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Response, res.Usage = data, usage
+			}
+			break
+		}
+		if rn.ctx.Err() != nil {
+			rn.unfinished()
+			return
+		}
+		if res.Attempts >= rn.config.MaxAttempts || !rn.config.Retryable(err) {
+			res.Error, res.Status = err.Error(), shared.StatusCode(err)
+			break
+		}
+		wait := shared.Jitter(delay)
+		delay *= 2
+		select {
+		case <-time.After(wait):
+		case <-rn.ctx.Done():
+			rn.unfinished()
+			return
+		}
+	}
+	rn.write(res, time.Since(start))
+}
+
+func (rn *run) overBudget() bool {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	if rn.config.Budget > 0 && rn.summary.Usage.Cost >= rn.config.Budget {
+		rn.stopped = true
+		rn.summary.BudgetExceeded = true
+	}
+	return rn.stopped
+}
+
+func (rn *run) unfinished() {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	rn.summary.Unfinished++
+}
+
+// write records res in the summary and appends it to the output.
+func (rn *run) write(res Result, latency time.Duration) {
+	res.LatencyMS = latency.Milliseconds()
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+	if res.Error != "" {
+		rn.summary.Failed++
+	} else {
+		rn.summary.Succeeded++
+		rn.latencies = append(rn.latencies, latency)
+	}
+	if res.Usage != nil {
+		rn.summary.Usage = rn.summary.Usage.Add(*res.Usage)
+	}
+	if err := rn.out.Encode(res); err != nil && rn.err == nil {
+		// Results that cannot be written are lost; stop the run.
+		rn.err = fmt.Errorf("batch: write result: %w", err)
+		rn.stopped = true
+	}
+	if rn.config.OnResult != nil {
+		rn.config.OnResult(res)
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p + 99) / 100
+	return sorted[max(i, 1)-1]
+}
+
+// Retryable reports whether a failed request is worth another attempt:
+// rate limited, timed out, a server error or a transport failure.
+func Retryable(err error) bool {
+	return shared.Retryable(err)
+}
@@ -0,0 +1,287 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// fakeBackend answers every request after a short delay with a cost of
+// 0.5, tracking concurrency per model. Prompts starting with "flaky" fail
+// once with 429; prompts starting with "bad" fail with 400.
+type fakeBackend struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+	peak     map[string]int
+	calls    map[string]int
+	total    atomic.Int32
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{inFlight: map[string]int{}, peak: map[string]int{}, calls: map[string]int{}}
+}
+
+func (b *fakeBackend) enter(model, key string) int {
+	b.total.Add(1)
+	b.mu.Lock()
+	b.inFlight[model]++
+	b.peak[model] = max(b.peak[model], b.inFlight[model])
+	b.calls[key]++
+	n := b.calls[key]
+	b.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+	b.mu.Lock()
+	b.inFlight[model]--
+	b.mu.Unlock()
+	return n
+}
+
+func (b *fakeBackend) CreateChatCompletion(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error) {
+	prompt := req.Messages[0].Content
+	n := b.enter(req.Model, prompt)
+	switch {
+	case strings.HasPrefix(prompt, "flaky") && n == 1:
+		return nil, &shared.APIError{Message: "rate limited", Code: float64(429)}
+	case strings.HasPrefix(prompt, "bad"):
+		return nil, &shared.APIError{Message: "invalid model", Code: float64(400)}
+	}
+	return &gopenrouter.ChatCompletionResponse{
+		ID:    "gen-" + prompt,
+		Model: req.Model,
+		Usage: gopenrouter.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Cost: 0.5},
+	}, nil
+}
+
+func (b *fakeBackend) CreateResponse(ctx context.Context, req responses.Request) (*responses.Response, error) {
+	b.enter(req.Model, "responses")
+	return &responses.Response{ID: "resp-1", Model: req.Model, Usage: &responses.Usage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10, Cost: 0.5}}, nil
+}
+
+func (b *fakeBackend) CreateEmbeddings(ctx context.Context, req embeddings.Request) (*embeddings.Response, error) {
+	b.enter(req.Model, "embeddings")
+	return &embeddings.Response{Model: req.Model, Usage: &embeddings.Usage{PromptTokens: 4, TotalTokens: 4, Cost: 0.5}}, nil
+}
+
+func chatLine(id, model, prompt string) string {
+	return fmt.Sprintf(`{"custom_id":%q,"body":{"model":%q,"messages":[{"role":"user","content":%q}]}}`, id, model, prompt)
+}
+
+func readResults(t *testing.T, data []byte) map[string]Result {
+	t.Helper()
+	results := map[string]Result{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var res Result
+		if err := json.Unmarshal(scanner.Bytes(), &res); err != nil {
+			t.Fatalf("invalid output line %q: %v", scanner.Text(), err)
+		}
+		// Keep the success when an ID also has a failed result.
+		if prev, ok := results[res.CustomID]; !ok || prev.Error != "" {
+			results[res.CustomID] = res
+		}
+	}
+	return results
+}
+
+func TestRun(t *testing.T) {
+	var lines []string
+	for i := range 12 {
+		lines = append(lines, chatLine(fmt.Sprintf("a-%d", i), "model/a", fmt.Sprintf("a%d", i)))
+		lines = append(lines, chatLine(fmt.Sprintf("b-%d", i), "model/b", fmt.Sprintf("b%d", i)))
+	}
+	lines = append(lines,
+		chatLine("flaky", "model/b", "flaky"),
+		chatLine("bad", "model/b", "bad"),
+		chatLine("a-0", "model/a", "again"),
+		`{"custom_id":"resp","url":"/v1/responses","body":{"model":"model/r","input":"hi"}}`,
+		`{"custom_id":"emb","url":"/embeddings","body":{"model":"model/e","input":["x"]}}`,
+		`{"custom_id":"odd","url":"/completions","body":{}}`,
+		`not json`,
+		"",
+		chatLine("done", "model/a", "skipped"),
+	)
+	backend := newFakeBackend()
+	var out bytes.Buffer
+	var delivered atomic.Int32
+	runner := New(backend, Config{
+		Concurrency:      4,
+		ModelConcurrency: map[string]int{"model/a": 1},
+		RetryDelay:       time.Millisecond,
+		OnResult:         func(Result) { delivered.Add(1) },
+	})
+	summary, err := runner.Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out, map[string]bool{"done": true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if summary.Read != 32 || summary.Skipped != 1 || summary.Succeeded != 27 || summary.Failed != 4 || summary.Unfinished != 0 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	if summary.Usage.InputTokens != 25*10+7+4 || summary.Usage.Cost != 13.5 {
+		t.Fatalf("unexpected usage: %+v", summary.Usage)
+	}
+	if summary.LatencyP50 <= 0 || summary.LatencyP99 < summary.LatencyP50 || summary.LatencyMax < summary.LatencyP99 {
+		t.Fatalf("unexpected latencies: %+v", summary)
+	}
+	if backend.peak["model/a"] != 1 || backend.peak["model/b"] > 4 {
+		t.Fatalf("concurrency exceeded: %v", backend.peak)
+	}
+	if int(delivered.Load()) != 31 {
+		t.Fatalf("OnResult called %d times", delivered.Load())
+	}
+
+	results := readResults(t, out.Bytes())
+	if res := results["flaky"]; res.Error != "" || res.Attempts != 2 {
+		t.Fatalf("flaky should succeed on retry: %+v", res)
+	}
+	if res := results["bad"]; res.Status != 400 || res.Attempts != 1 || !strings.Contains(res.Error, "invalid model") {
+		t.Fatalf("bad should fail without retry: %+v", res)
+	}
+	if res := results["odd"]; !strings.Contains(res.Error, "unsupported url") {
+		t.Fatalf("unexpected result for unknown url: %+v", res)
+	}
+	if res := results[""]; !strings.Contains(res.Error, "line 31") {
+		t.Fatalf("unexpected result for invalid line: %+v", res)
+	}
+	if res := results["a-0"]; res.Error != "" {
+		t.Fatalf("the duplicate must not replace the first result: %+v", res)
+	}
+	var chat gopenrouter.ChatCompletionResponse
+	if err := json.Unmarshal(results["b-3"].Response, &chat); err != nil || chat.ID != "gen-b3" || results["b-3"].Model != "model/b" {
+		t.Fatalf("unexpected response: %s (%v)", results["b-3"].Response, err)
+	}
+	if res := results["resp"]; res.Error != "" || res.Usage.OutputTokens != 3 || res.URL != "/v1/responses" {
+		t.Fatalf("unexpected responses result: %+v", res)
+	}
+}
+
+func TestRunSaturatedModel(t *testing.T) {
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, chatLine(fmt.Sprintf("a-%d", i), "model/a", fmt.Sprintf("a%d", i)))
+	}
+	for i := range 4 {
+		lines = append(lines, chatLine(fmt.Sprintf("b-%d", i), "model/b", fmt.Sprintf("b%d", i)))
+	}
+	backend := newFakeBackend()
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	runner := New(backend, Config{
+		Concurrency:      2,
+		ModelConcurrency: map[string]int{"model/a": 1},
+		OnResult: func(res Result) {
+			mu.Lock()
+			order = append(order, res.Model)
+			mu.Unlock()
+		},
+	})
+	summary, err := runner.Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &bytes.Buffer{}, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if summary.Succeeded != 24 || backend.peak["model/a"] != 1 || backend.peak["model/b"] > 2 {
+		t.Fatalf("unexpected run: %+v, peak %v", summary, backend.peak)
+	}
+	// The queue holds 4*Concurrency records, all of them model/a until
+	// enough of those finish to let the first model/b record in.
+	if first := slices.Index(order, "model/b"); first < 20-4*2 {
+		t.Fatalf("model/b finished after %d model/a records, want at least %d", first, 20-4*2)
+	}
+}
+
+func TestRunBudget(t *testing.T) {
+	var lines []string
+	for i := range 10 {
+		lines = append(lines, chatLine(fmt.Sprintf("id-%d", i), "model/a", fmt.Sprintf("p%d", i)))
+	}
+	var out bytes.Buffer
+	summary, err := New(newFakeBackend(), Config{Concurrency: 1, Budget: 1.5}).Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out, nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !summary.BudgetExceeded || summary.Succeeded != 3 || summary.Unfinished != 7 || summary.Usage.Cost != 1.5 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if n := len(readResults(t, out.Bytes())); n != 3 {
+		t.Fatalf("unfinished records must not be written, got %d results", n)
+	}
+}
+
+func TestRunFileResumes(t *testing.T) {
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.jsonl"), filepath.Join(dir, "out.jsonl")
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, chatLine(fmt.Sprintf("id-%d", i), "model/a", fmt.Sprintf("p%d", i)))
+	}
+	if err := os.WriteFile(in, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first run crashes after five results, leaving a partial line.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n atomic.Int32
+	first := New(newFakeBackend(), Config{Concurrency: 2, OnResult: func(Result) {
+		if n.Add(1) == 5 {
+			cancel()
+		}
+	}})
+	summary, err := first.RunFile(ctx, in, out)
+	if err == nil || summary.Succeeded < 5 || summary.Succeeded+summary.Unfinished != 20 {
+		t.Fatalf("expected an interrupted run, got %+v, %v", summary, err)
+	}
+	f, err := os.OpenFile(out, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"custom_id":"id-19","resp`)
+	f.Close()
+
+	backend := newFakeBackend()
+	second, err := New(backend, Config{Concurrency: 4}).RunFile(context.Background(), in, out)
+	if err != nil {
+		t.Fatalf("RunFile: %v", err)
+	}
+	if second.Skipped != summary.Succeeded || second.Succeeded != 20-summary.Succeeded || int(backend.total.Load()) != second.Succeeded {
+		t.Fatalf("resume should only run the rest: first %+v, second %+v", summary, second)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, _ := os.Open(out)
+	defer f2.Close()
+	done, err := Completed(f2)
+	if err != nil || len(done) != 20 {
+		t.Fatalf("expected 20 completed IDs, got %d (%v)\n%s", len(done), err, data)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	if percentile(sorted, 50) != 50 || percentile(sorted, 99) != 99 || percentile(sorted[:1], 90) != 1 || percentile(nil, 50) != 0 {
+		t.Fatal("unexpected percentiles")
+	}
+}
@@ -0,0 +1,20 @@
+package catalog
+
+// Cost is the exact price of a request with the given token counts: prompt
+// and completion tokens at their per-token prices plus the per-request fee.
+// Discount is not applied.
+func (p Pricing) Cost(promptTokens, completionTokens int64) (Decimal, error) {
+	prompt, err := p.Prompt.Decimal()
+	if err != nil {
+		return Decimal{}, err
+	}
+	completion, err := p.Completion.Decimal()
+	if err != nil {
+		return Decimal{}, err
+	}
+	request, err := p.Request.Decimal()
+	if err != nil {
+		return Decimal{}, err
+	}
+	return prompt.MulInt(promptTokens).Add(completion.MulInt(completionTokens)).Add(request), nil
+}
@@ -0,0 +1,14 @@
+package catalog
+
+import "testing"
+
+func TestPricingCost(t *testing.T) {
+	pricing := Pricing{Prompt: "0.0000025", Completion: "0.00001", Request: "0.001"}
+	cost, err := pricing.Cost(1_234_567, 89_012)
+	if err != nil || cost.String() != "3.9775375" {
+		t.Fatalf("Cost: %s, %v", cost, err)
+	}
+	if _, err := (Pricing{Prompt: "n/a"}).Cost(1, 0); err == nil {
+		t.Fatal("expected error for an invalid price")
+	}
+}
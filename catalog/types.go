@@ -4,6 +4,7 @@ import "github.com/iamwavecut/gopenrouter/shared"
 
 type (
 	BigNumber       = shared.BigNumber
+	Decimal         = shared.Decimal
 	Quantization    = shared.Quantization
 	PercentileStats = shared.PercentileStats
 )
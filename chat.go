@@ -251,16 +251,6 @@ type Usage struct {
 	CompletionTokensDetails *TokensDetails `json:"completion_tokens_details,omitempty"`
 }
 
-type CostDetails struct {
-	UpstreamInferenceCost       float64 `json:"upstream_inference_cost,omitempty"`
-	UpstreamInferenceInputCost  float64 `json:"upstream_inference_input_cost,omitempty"`
-	UpstreamInferenceOutputCost float64 `json:"upstream_inference_output_cost,omitempty"`
-}
-
-type ServerToolUse struct {
-	WebSearchRequests int `json:"web_search_requests,omitempty"`
-}
-
 type TokensDetails struct {
 	CachedTokens             int `json:"cached_tokens,omitempty"`
 	CacheWriteTokens         int `json:"cache_write_tokens,omitempty"`
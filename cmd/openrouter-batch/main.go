@@ -0,0 +1,106 @@
+// Command openrouter-batch runs a JSONL file of requests against OpenRouter
+// and appends one result line per request to an output file. Rerunning with
+// the same output file resumes an interrupted run.
+//
+//	OPENROUTER_API_KEY=... openrouter-batch -in prompts.jsonl -out results.jsonl \
+//		-concurrency 16 -model-concurrency openai/gpt-4o=4 -budget 25
+//
+// Each input line is {"custom_id": "...", "url": "/chat/completions", "body": {...}};
+// url may also be /responses or /embeddings and defaults to -endpoint.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/batch"
+)
+
+func main() {
+	var (
+		in               = flag.String("in", "", "input JSONL file of requests")
+		out              = flag.String("out", "", "output JSONL file of results; appended to and used to resume")
+		concurrency      = flag.Int("concurrency", 8, "maximum requests in flight")
+		modelConcurrency = flag.String("model-concurrency", "", "per-model limits as model=n[,model=n...]")
+		endpoint         = flag.String("endpoint", batch.EndpointChat, "endpoint of records without a url")
+		attempts         = flag.Int("attempts", 3, "maximum attempts per request")
+		retryDelay       = flag.Duration("retry-delay", time.Second, "wait before the first retry")
+		budget           = flag.Float64("budget", 0, "stop once this many USD are spent (0 for no budget)")
+		quiet            = flag.Bool("quiet", false, "do not print progress")
+	)
+	flag.Parse()
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	limits, err := parseLimits(*modelConcurrency)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	key := os.Getenv("OPENROUTER_API_KEY")
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "OPENROUTER_API_KEY is not set")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var finished int
+	runner := batch.New(gopenrouter.NewClient(key), batch.Config{
+		Concurrency:      *concurrency,
+		ModelConcurrency: limits,
+		Endpoint:         *endpoint,
+		MaxAttempts:      *attempts,
+		RetryDelay:       *retryDelay,
+		Budget:           *budget,
+		OnResult: func(res batch.Result) {
+			finished++
+			if *quiet {
+				return
+			}
+			if res.Error != "" {
+				fmt.Fprintf(os.Stderr, "%d\t%s\tFAILED after %d attempts: %s\n", finished, res.CustomID, res.Attempts, res.Error)
+			} else if finished%100 == 0 {
+				fmt.Fprintf(os.Stderr, "%d finished\n", finished)
+			}
+		},
+	})
+	summary, err := runner.RunFile(ctx, *in, *out)
+	if summary != nil {
+		fmt.Println(summary)
+		if summary.BudgetExceeded {
+			fmt.Println("stopped at the budget; rerun to continue")
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseLimits(s string) (map[string]int, error) {
+	limits := map[string]int{}
+	if s == "" {
+		return limits, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		model, n, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		limit, err := strconv.Atoi(n)
+		if !ok || model == "" || err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid -model-concurrency entry %q", pair)
+		}
+		limits[model] = limit
+	}
+	return limits, nil
+}
@@ -17,6 +17,9 @@ type ActivityItem = managementpkg.ActivityItem
 // Deprecated: use management.APIKeysListParams from package github.com/iamwavecut/gopenrouter/management.
 type APIKeysListParams = managementpkg.APIKeysListParams
 
+// Deprecated: use management.PageParams from package github.com/iamwavecut/gopenrouter/management.
+type PageParams = managementpkg.PageParams
+
 // Deprecated: use management.APIKeysResponse from package github.com/iamwavecut/gopenrouter/management.
 type APIKeysResponse = managementpkg.APIKeysResponse
 
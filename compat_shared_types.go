@@ -163,3 +163,9 @@ type APIError = shared.APIError
 
 // Deprecated: use shared.RequestError from package github.com/iamwavecut/gopenrouter/shared.
 type RequestError = shared.RequestError
+
+// Deprecated: use shared.CostDetails from package github.com/iamwavecut/gopenrouter/shared.
+type CostDetails = shared.CostDetails
+
+// Deprecated: use shared.ServerToolUse from package github.com/iamwavecut/gopenrouter/shared.
+type ServerToolUse = shared.ServerToolUse
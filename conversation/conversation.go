@@ -0,0 +1,352 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/catalog"
+)
+
+type backend interface {
+	CreateChatCompletion(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error)
+}
+
+type modelLister interface {
+	ListModels(ctx context.Context) (*catalog.ModelsList, error)
+}
+
+// Limits are the token limits of a model.
+type Limits struct {
+	ContextSize         int
+	MaxCompletionTokens int
+}
+
+// LimitsFromModel reads the limits of a catalog entry, preferring the top
+// provider's context length when it is smaller.
+func LimitsFromModel(m catalog.Model) Limits {
+	limits := Limits{ContextSize: m.ContextSize}
+	if tp := m.TopProvider; tp != nil {
+		if tp.ContextLength > 0 && (limits.ContextSize == 0 || tp.ContextLength < limits.ContextSize) {
+			limits.ContextSize = tp.ContextLength
+		}
+		limits.MaxCompletionTokens = tp.MaxCompletionTokens
+	}
+	return limits
+}
+
+// LookupLimits finds model in the catalog.
+func LookupLimits(ctx context.Context, lister modelLister, model string) (Limits, error) {
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return Limits{}, err
+	}
+	for _, m := range models.Data {
+		if m.ID == model || m.CanonicalSlug == model {
+			return LimitsFromModel(m), nil
+		}
+	}
+	return Limits{}, fmt.Errorf("conversation: model %q not found in catalog", model)
+}
+
+// Config configures a Conversation.
+type Config struct {
+	// Model is used for requests that leave Model empty.
+	Model  string
+	Limits Limits
+	// Policy fits the history into the context window. Nil means DropOldest.
+	Policy Policy
+	// Count estimates the tokens of a message. Nil means EstimateTokens; the
+	// CountMessage method of a tokens.Counter is a closer fit per model.
+	Count func(gopenrouter.ChatCompletionMessage) int
+	// Store persists every message under SessionID, which is also sent
+	// with requests that leave SessionID empty. Nil keeps the history in
+	// memory only.
+	Store     Store
+	SessionID string
+}
+
+// Conversation is a chat history that is fitted into the model's context
+// window before every request. It is safe for concurrent use; sends are
+// serialized so turns never interleave.
+type Conversation struct {
+	backend backend
+	config  Config
+
+	send     sync.Mutex
+	mu       sync.RWMutex
+	messages []gopenrouter.ChatCompletionMessage
+	tokens   []int
+	// version is the number of persisted entries; unsaved entries are
+	// written by the next Send or Save.
+	version int
+	unsaved []Entry
+}
+
+func New(backend backend, config Config) *Conversation {
+	if config.Policy == nil {
+		config.Policy = DropOldest{}
+	}
+	if config.Count == nil {
+		config.Count = EstimateTokens
+	}
+	return &Conversation{backend: backend, config: config}
+}
+
+// Resume loads the session config.SessionID from config.Store, or starts
+// it empty when the store does not have it yet. The whole transcript is
+// loaded; it is fitted into the context window on the next send.
+func Resume(ctx context.Context, backend backend, config Config) (*Conversation, error) {
+	if config.Store == nil {
+		return nil, errors.New("conversation: Resume needs a Store")
+	}
+	c := New(backend, config)
+	t, err := config.Store.Load(ctx, config.SessionID)
+	if errors.Is(err, ErrNotFound) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.appendLocked(t.Messages()...)
+	c.version = t.Version()
+	return c, nil
+}
+
+// Fork saves the conversation, copies its first n persisted messages into
+// the session newID and resumes that session with the same config.
+func (c *Conversation) Fork(ctx context.Context, newID string, n int) (*Conversation, error) {
+	if c.config.Store == nil {
+		return nil, errors.New("conversation: Fork needs a Store")
+	}
+	if err := c.Save(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.config.Store.Fork(ctx, c.config.SessionID, newID, n); err != nil {
+		return nil, err
+	}
+	config := c.config
+	config.SessionID = newID
+	return Resume(ctx, c.backend, config)
+}
+
+// Append adds messages to the history without sending them.
+func (c *Conversation) Append(messages ...gopenrouter.ChatCompletionMessage) {
+	entries := make([]Entry, len(messages))
+	for i, msg := range messages {
+		entries[i] = Entry{Message: msg}
+	}
+	c.appendEntries(entries...)
+}
+
+func (c *Conversation) appendEntries(entries ...Entry) {
+	now := time.Now().UTC()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range entries {
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = now
+		}
+		c.appendLocked(entry.Message)
+		c.unsaved = append(c.unsaved, entry)
+	}
+}
+
+func (c *Conversation) appendLocked(messages ...gopenrouter.ChatCompletionMessage) {
+	for _, msg := range messages {
+		c.messages = append(c.messages, msg)
+		c.tokens = append(c.tokens, c.config.Count(msg))
+	}
+}
+
+// Messages returns a copy of the history.
+func (c *Conversation) Messages() []gopenrouter.ChatCompletionMessage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]gopenrouter.ChatCompletionMessage(nil), c.messages...)
+}
+
+// Tokens returns the estimated token count of the history.
+func (c *Conversation) Tokens() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var n int
+	for _, tokens := range c.tokens {
+		n += tokens
+	}
+	return n
+}
+
+// Send appends req.Messages to the history, fits the history into the
+// context window and sends it with the rest of req. The first choice's
+// message is appended to the history on success; on failure the appended
+// messages stay so the send can be retried with no new messages.
+//
+// With a Store, the new messages are persisted after the response arrives,
+// the reply with its model, generation ID and usage. A failed save returns
+// the response together with the error; ErrConflict means another writer
+// changed the session and the conversation should be resumed.
+func (c *Conversation) Send(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error) {
+	c.send.Lock()
+	defer c.send.Unlock()
+
+	entries := make([]Entry, len(req.Messages))
+	for i, msg := range req.Messages {
+		entries[i] = Entry{Message: msg, Metadata: req.Metadata}
+	}
+	c.appendEntries(entries...)
+	if err := c.fit(ctx, req); err != nil {
+		return nil, err
+	}
+	req.Messages = c.Messages()
+	if req.Model == "" {
+		req.Model = c.config.Model
+	}
+	if req.SessionID == "" {
+		req.SessionID = c.config.SessionID
+	}
+	res, err := c.backend.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Choices) > 0 {
+		usage := res.Usage
+		c.appendEntries(Entry{
+			Message:      res.Choices[0].Message,
+			Model:        res.Model,
+			GenerationID: res.ID,
+			Usage:        &usage,
+			Metadata:     req.Metadata,
+		})
+	}
+	if err := c.save(ctx); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// Save persists messages appended since the last save. It is a no-op
+// without a Store.
+func (c *Conversation) Save(ctx context.Context) error {
+	c.send.Lock()
+	defer c.send.Unlock()
+	return c.save(ctx)
+}
+
+func (c *Conversation) save(ctx context.Context) error {
+	c.mu.Lock()
+	if c.config.Store == nil {
+		c.unsaved = nil
+	}
+	pending := append([]Entry(nil), c.unsaved...)
+	c.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	version, err := c.config.Store.Append(ctx, c.config.SessionID, c.version, pending...)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Keep entries appended while the store was written.
+	c.version, c.unsaved = version, c.unsaved[len(pending):]
+	return nil
+}
+
+// Fit applies the policy when the history exceeds the budget left by req:
+// the context size minus the completion reserve and the tool definitions.
+// Send calls it before every request.
+func (c *Conversation) Fit(ctx context.Context, req gopenrouter.ChatCompletionRequest) error {
+	c.send.Lock()
+	defer c.send.Unlock()
+	return c.fit(ctx, req)
+}
+
+func (c *Conversation) fit(ctx context.Context, req gopenrouter.ChatCompletionRequest) error {
+	budget, ok := c.budget(req)
+	if !ok {
+		return nil
+	}
+	c.mu.RLock()
+	window := Window{
+		Messages: append([]gopenrouter.ChatCompletionMessage(nil), c.messages...),
+		Tokens:   append([]int(nil), c.tokens...),
+		Budget:   budget,
+		Count:    c.config.Count,
+	}
+	c.mu.RUnlock()
+	if window.total() <= budget {
+		return nil
+	}
+	fitted, err := c.config.Policy.Fit(ctx, window)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Keep messages appended while the policy ran.
+	added := c.messages[len(window.Messages):]
+	c.messages, c.tokens = nil, nil
+	c.appendLocked(fitted...)
+	c.appendLocked(added...)
+	return nil
+}
+
+func (c *Conversation) budget(req gopenrouter.ChatCompletionRequest) (int, bool) {
+	limits := c.config.Limits
+	if limits.ContextSize <= 0 {
+		return 0, false
+	}
+	reserve := req.MaxTokens
+	if req.MaxCompletionTokens != nil {
+		reserve = *req.MaxCompletionTokens
+	}
+	if reserve <= 0 {
+		reserve = limits.MaxCompletionTokens
+	}
+	if reserve <= 0 || reserve >= limits.ContextSize {
+		reserve = limits.ContextSize / 4
+	}
+	budget := limits.ContextSize - reserve
+	if len(req.Tools) > 0 {
+		if b, err := json.Marshal(req.Tools); err == nil {
+			budget -= len(b)/4 + 1
+		}
+	}
+	return budget, true
+}
+
+// EstimateTokens is a conservative estimate of a message's prompt tokens:
+// about four bytes of text per token plus a per-message overhead, and a flat
+// cost for images and files.
+func EstimateTokens(msg gopenrouter.ChatCompletionMessage) int {
+	const (
+		messageOverhead = 4
+		imageTokens     = 765
+		fileTokens      = 1024
+	)
+	n := messageOverhead + textTokens(msg.Content) + textTokens(msg.Name) + textTokens(msg.Reasoning)
+	for _, part := range msg.MultiContent {
+		switch {
+		case part.ImageURL != nil:
+			n += imageTokens
+		case part.File != nil || part.InputAudio != nil || part.VideoURL != nil:
+			n += fileTokens
+		default:
+			n += textTokens(part.Text)
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		n += messageOverhead + textTokens(call.Function.Name) + textTokens(call.Function.Arguments)
+	}
+	return n
+}
+
+func textTokens(s string) int {
+	return (len(s) + 3) / 4
+}
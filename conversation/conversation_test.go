@@ -0,0 +1,213 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+// fixedCount counts every message as 10 tokens.
+func fixedCount(gopenrouter.ChatCompletionMessage) int { return 10 }
+
+func roles(messages []gopenrouter.ChatCompletionMessage) string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, string(msg.Role)+":"+msg.Content+msg.ToolCallID)
+	}
+	return strings.Join(out, " ")
+}
+
+func toolHistory() []gopenrouter.ChatCompletionMessage {
+	return []gopenrouter.ChatCompletionMessage{
+		{Role: gopenrouter.RoleSystem, Content: "sys"},
+		{Role: gopenrouter.RoleUser, Content: "u1"},
+		{Role: gopenrouter.RoleAssistant, ToolCalls: []gopenrouter.ToolCall{{ID: "c1", Type: "function", Function: gopenrouter.Function{Name: "f"}}}},
+		{Role: gopenrouter.RoleTool, ToolCallID: "c1", Content: "r1"},
+		{Role: gopenrouter.RoleAssistant, Content: "a1"},
+		{Role: gopenrouter.RoleUser, Content: "u2"},
+		{Role: gopenrouter.RoleAssistant, Content: "a2"},
+		{Role: gopenrouter.RoleUser, Content: "u3"},
+	}
+}
+
+func window(messages []gopenrouter.ChatCompletionMessage, budget int) Window {
+	w := Window{Messages: messages, Budget: budget, Count: fixedCount}
+	for _, msg := range messages {
+		w.Tokens = append(w.Tokens, fixedCount(msg))
+	}
+	return w
+}
+
+func TestDropOldest(t *testing.T) {
+	ctx := context.Background()
+	got, err := DropOldest{}.Fit(ctx, window(toolHistory(), 40))
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if want := "system:sys user:u2 assistant:a2 user:u3"; roles(got) != want {
+		t.Fatalf("got %q, want %q", roles(got), want)
+	}
+
+	got, err = DropOldest{}.Fit(ctx, window(toolHistory(), 70))
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if want := "system:sys user:u2 assistant:a2 user:u3"; roles(got) != want {
+		t.Fatalf("tool results must go with their calls: got %q", roles(got))
+	}
+
+	if _, err := (DropOldest{}).Fit(ctx, window(toolHistory(), 10)); !errors.Is(err, ErrContextOverflow) {
+		t.Fatalf("expected overflow, got %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var dropped []gopenrouter.ChatCompletionMessage
+	policy := Summarize{Reserve: 10, Summarizer: func(ctx context.Context, messages []gopenrouter.ChatCompletionMessage) (gopenrouter.ChatCompletionMessage, error) {
+		dropped = messages
+		return gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, Content: "summary"}, nil
+	}}
+	got, err := policy.Fit(context.Background(), window(toolHistory(), 50))
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if want := "system:sys user:summary user:u2 assistant:a2 user:u3"; roles(got) != want {
+		t.Fatalf("got %q, want %q", roles(got), want)
+	}
+	if len(dropped) != 4 || dropped[2].ToolCallID != "c1" {
+		t.Fatalf("unexpected dropped messages: %q", roles(dropped))
+	}
+
+	if _, err := (Summarize{}).Fit(context.Background(), window(toolHistory(), 50)); err == nil {
+		t.Fatal("expected an error without a Summarizer")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	text := EstimateTokens(gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, Content: strings.Repeat("a", 400)})
+	if text != 104 {
+		t.Fatalf("unexpected text estimate %d", text)
+	}
+	image := EstimateTokens(gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, MultiContent: []gopenrouter.ChatCompletionMessagePart{
+		{Type: "image_url", ImageURL: &gopenrouter.ImageURL{URL: "https://example.com/a.png"}},
+	}})
+	if image <= 700 {
+		t.Fatalf("images must be costed, got %d", image)
+	}
+}
+
+func TestConversationSend(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []gopenrouter.ChatCompletionRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			fmt.Fprint(w, `{"data":[{"id":"test/model","name":"Test","context_length":200,"top_provider":{"context_length":100,"max_completion_tokens":30}}]}`)
+			return
+		}
+		var req gopenrouter.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		n := len(requests)
+		mu.Unlock()
+		fmt.Fprintf(w, `{"id":"gen-%d","model":%q,"choices":[{"message":{"role":"assistant","content":"reply %d"},"finish_reason":"stop"}]}`, n, req.Model, n)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := gopenrouter.NewClientWithConfig(cfg)
+	ctx := context.Background()
+
+	limits, err := LookupLimits(ctx, client, "test/model")
+	if err != nil {
+		t.Fatalf("LookupLimits: %v", err)
+	}
+	if limits.ContextSize != 100 || limits.MaxCompletionTokens != 30 {
+		t.Fatalf("unexpected limits: %+v", limits)
+	}
+	if _, err := LookupLimits(ctx, client, "missing/model"); err == nil {
+		t.Fatal("expected lookup error")
+	}
+
+	conv := New(client, Config{Model: "test/model", Limits: limits, Count: fixedCount})
+	conv.Append(gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleSystem, Content: "sys"})
+	for i := range 5 {
+		_, err := conv.Send(ctx, gopenrouter.ChatCompletionRequest{Messages: []gopenrouter.ChatCompletionMessage{
+			{Role: gopenrouter.RoleUser, Content: fmt.Sprintf("q%d", i)},
+		}})
+		if err != nil {
+			t.Fatalf("Send %d: %v", i, err)
+		}
+	}
+	// Budget is 100 - 30 = 70 tokens: the system message and three turns.
+	last := requests[len(requests)-1]
+	if last.Model != "test/model" || len(last.Messages) != 6 || last.Messages[0].Role != gopenrouter.RoleSystem || last.Messages[1].Content != "q2" {
+		t.Fatalf("unexpected fitted request: %q", roles(last.Messages))
+	}
+	if got := conv.Messages(); len(got) != 7 || got[6].Content != "reply 5" || conv.Tokens() != 70 {
+		t.Fatalf("unexpected history: %q (%d tokens)", roles(got), conv.Tokens())
+	}
+
+	maxTokens := 60
+	_, err = conv.Send(ctx, gopenrouter.ChatCompletionRequest{
+		MaxCompletionTokens: &maxTokens,
+		Messages:            []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "long"}},
+	})
+	if err != nil {
+		t.Fatalf("Send with reserve: %v", err)
+	}
+	if last := requests[len(requests)-1]; len(last.Messages) != 4 {
+		t.Fatalf("expected the completion reserve to shrink the window: %q", roles(last.Messages))
+	}
+}
+
+func TestConversationConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"gen","choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	conv := New(gopenrouter.NewClientWithConfig(cfg), Config{Model: "m", Limits: Limits{ContextSize: 100, MaxCompletionTokens: 20}, Count: fixedCount})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := conv.Send(context.Background(), gopenrouter.ChatCompletionRequest{Messages: []gopenrouter.ChatCompletionMessage{
+				{Role: gopenrouter.RoleUser, Content: fmt.Sprint(i)},
+			}})
+			if err != nil {
+				t.Errorf("Send: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = conv.Messages()
+			_ = conv.Tokens()
+		}()
+	}
+	wg.Wait()
+	messages := conv.Messages()
+	if len(messages) != 8 || conv.Tokens() > 80 {
+		t.Fatalf("unexpected history after concurrent sends: %q", roles(messages))
+	}
+	for i := 0; i < len(messages); i += 2 {
+		if messages[i].Role != gopenrouter.RoleUser || messages[i+1].Role != gopenrouter.RoleAssistant {
+			t.Fatalf("turns interleaved: %q", roles(messages))
+		}
+	}
+}
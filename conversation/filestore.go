@@ -0,0 +1,172 @@
+package conversation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore keeps one JSONL file per session in a directory. The first line
+// describes the session and every following line is an entry. Writes are
+// serialized within the process; share a directory between processes only
+// when a single writer owns each session.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// fileHeader is the first line of a session file.
+type fileHeader struct {
+	SessionID string `json:"session_id"`
+	ParentID  string `json:"parent_id,omitempty"`
+	ForkedAt  int    `json:"forked_at,omitempty"`
+}
+
+// NewFileStore creates dir if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(sessionID string) string {
+	return filepath.Join(s.dir, url.PathEscape(sessionID)+".jsonl")
+}
+
+func (s *FileStore) Load(ctx context.Context, sessionID string) (*Transcript, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(sessionID)
+}
+
+func (s *FileStore) load(sessionID string) (*Transcript, error) {
+	data, err := os.ReadFile(s.path(sessionID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		t      *Transcript
+		line   int
+		reader = bufio.NewScanner(bytes.NewReader(data))
+	)
+	reader.Buffer(nil, len(data)+1)
+	for reader.Scan() {
+		line++
+		if len(bytes.TrimSpace(reader.Bytes())) == 0 {
+			continue
+		}
+		if t == nil {
+			var header fileHeader
+			if err := json.Unmarshal(reader.Bytes(), &header); err != nil {
+				return nil, fmt.Errorf("conversation: %s:%d: %w", s.path(sessionID), line, err)
+			}
+			t = &Transcript{SessionID: header.SessionID, ParentID: header.ParentID, ForkedAt: header.ForkedAt}
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(reader.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("conversation: %s:%d: %w", s.path(sessionID), line, err)
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("conversation: %s: missing session header", s.path(sessionID))
+	}
+	return t, nil
+}
+
+func (s *FileStore) Append(ctx context.Context, sessionID string, version int, entries ...Entry) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.load(sessionID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		if version != 0 {
+			return 0, ErrConflict
+		}
+		if err := s.create(fileHeader{SessionID: sessionID}, nil); err != nil {
+			return 0, err
+		}
+		t = &Transcript{SessionID: sessionID}
+	case err != nil:
+		return 0, err
+	}
+	if t.Version() != version {
+		return 0, ErrConflict
+	}
+	f, err := os.OpenFile(s.path(sessionID), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeLines(f, entries); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+	return version + len(entries), nil
+}
+
+func (s *FileStore) Fork(ctx context.Context, sessionID, newID string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, err := s.load(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := checkFork(t, n); err != nil {
+		return err
+	}
+	return s.create(fileHeader{SessionID: newID, ParentID: sessionID, ForkedAt: n}, t.Entries[:n])
+}
+
+// create writes a new session file; it fails with ErrConflict if the file
+// exists.
+func (s *FileStore) create(header fileHeader, entries []Entry) error {
+	f, err := os.OpenFile(s.path(header.SessionID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+	lines := []any{header}
+	for _, entry := range entries {
+		lines = append(lines, entry)
+	}
+	if err := writeLines(f, lines); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeLines encodes every value on its own line with a single write, so a
+// failed encode leaves the file untouched.
+func writeLines[T any](f *os.File, values []T) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	_, err := f.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,163 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+// ErrContextOverflow is returned when the pinned messages and the latest
+// turn alone do not fit the context window.
+var ErrContextOverflow = errors.New("conversation: history does not fit the context window")
+
+// Window is the history handed to a Policy.
+type Window struct {
+	Messages []gopenrouter.ChatCompletionMessage
+	// Tokens holds the estimated token count of each message.
+	Tokens []int
+	// Budget is the number of tokens available for messages.
+	Budget int
+	// Count is the estimator that produced Tokens.
+	Count func(gopenrouter.ChatCompletionMessage) int
+}
+
+func (w Window) total() int {
+	var n int
+	for _, tokens := range w.Tokens {
+		n += tokens
+	}
+	return n
+}
+
+// Policy shrinks a history that exceeds its budget. The returned messages
+// replace the conversation history.
+type Policy interface {
+	Fit(ctx context.Context, w Window) ([]gopenrouter.ChatCompletionMessage, error)
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(ctx context.Context, w Window) ([]gopenrouter.ChatCompletionMessage, error)
+
+func (f PolicyFunc) Fit(ctx context.Context, w Window) ([]gopenrouter.ChatCompletionMessage, error) {
+	return f(ctx, w)
+}
+
+// DropOldest drops whole turns, oldest first, until the history fits.
+// System and developer messages stay pinned and the latest turn is always
+// kept. A turn starts at a user message, so tool results are dropped
+// together with the assistant message that requested them.
+type DropOldest struct{}
+
+func (DropOldest) Fit(ctx context.Context, w Window) ([]gopenrouter.ChatCompletionMessage, error) {
+	kept, _, err := dropOldest(w, w.Budget)
+	if err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// Summarizer condenses dropped messages into one message. It should return a
+// user or assistant message so the summary itself can be dropped or
+// summarized later.
+type Summarizer func(ctx context.Context, dropped []gopenrouter.ChatCompletionMessage) (gopenrouter.ChatCompletionMessage, error)
+
+// Summarize drops the oldest turns like DropOldest and replaces them with a
+// summary placed after the leading system messages. Summarizer is required.
+type Summarize struct {
+	Summarizer Summarizer
+	// Reserve is the part of the budget left for the summary. Zero means a
+	// quarter of the budget.
+	Reserve int
+}
+
+func (s Summarize) Fit(ctx context.Context, w Window) ([]gopenrouter.ChatCompletionMessage, error) {
+	if s.Summarizer == nil {
+		return nil, errors.New("conversation: Summarize needs a Summarizer")
+	}
+	reserve := s.Reserve
+	if reserve <= 0 {
+		reserve = w.Budget / 4
+	}
+	kept, dropped, err := dropOldest(w, w.Budget-reserve)
+	if err != nil {
+		return nil, err
+	}
+	if len(dropped) == 0 {
+		return kept, nil
+	}
+	summary, err := s.Summarizer(ctx, dropped)
+	if err != nil {
+		return nil, err
+	}
+	keptTokens := 0
+	for _, msg := range kept {
+		keptTokens += w.Count(msg)
+	}
+	if keptTokens+w.Count(summary) > w.Budget {
+		return nil, ErrContextOverflow
+	}
+	at := 0
+	for at < len(kept) && pinned(kept[at]) {
+		at++
+	}
+	out := make([]gopenrouter.ChatCompletionMessage, 0, len(kept)+1)
+	out = append(out, kept[:at]...)
+	out = append(out, summary)
+	return append(out, kept[at:]...), nil
+}
+
+// pinned reports whether msg is a system or developer message.
+func pinned(msg gopenrouter.ChatCompletionMessage) bool {
+	return msg.Role == gopenrouter.RoleSystem || msg.Role == gopenrouter.RoleDeveloper
+}
+
+// dropOldest returns the messages kept within budget, in order, and the
+// unpinned messages dropped.
+func dropOldest(w Window, budget int) (kept, dropped []gopenrouter.ChatCompletionMessage, err error) {
+	total := w.total()
+	if total <= budget {
+		return w.Messages, nil, nil
+	}
+	turns := splitTurns(w.Messages)
+	drop := make([]bool, len(w.Messages))
+	for _, turn := range turns[:len(turns)-1] {
+		if total <= budget {
+			break
+		}
+		for _, i := range turn {
+			drop[i] = true
+			total -= w.Tokens[i]
+		}
+	}
+	if total > budget {
+		return nil, nil, ErrContextOverflow
+	}
+	for i, msg := range w.Messages {
+		if drop[i] {
+			dropped = append(dropped, msg)
+		} else {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, dropped, nil
+}
+
+// splitTurns groups the indexes of unpinned messages into turns. A turn
+// begins at a user message and runs to the next one.
+func splitTurns(messages []gopenrouter.ChatCompletionMessage) [][]int {
+	var turns [][]int
+	for i, msg := range messages {
+		if pinned(msg) {
+			continue
+		}
+		if msg.Role == gopenrouter.RoleUser || len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], i)
+	}
+	if len(turns) == 0 {
+		turns = append(turns, nil)
+	}
+	return turns
+}
@@ -0,0 +1,234 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLStore keeps sessions in two tables through database/sql: one row per
+// session and one row per entry, keyed by session and sequence number. The
+// entry is stored as JSON next to a few columns for querying. Concurrent
+// appends are detected inside a transaction and by the primary key.
+type SQLStore struct {
+	db *sql.DB
+	// Sessions and Entries are the table names. Defaults are
+	// "conversation_sessions" and "conversation_entries".
+	Sessions string
+	Entries  string
+	// Dollar selects $1-style placeholders, e.g. for PostgreSQL; the
+	// default is ?.
+	Dollar bool
+}
+
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db, Sessions: "conversation_sessions", Entries: "conversation_entries"}
+}
+
+// Schema returns portable CREATE TABLE statements for the store.
+func (s *SQLStore) Schema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.Sessions + ` (
+	id TEXT PRIMARY KEY,
+	parent_id TEXT NOT NULL DEFAULT '',
+	forked_at INTEGER NOT NULL DEFAULT 0
+)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.Entries + ` (
+	session_id TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	role TEXT NOT NULL,
+	model TEXT NOT NULL DEFAULT '',
+	generation_id TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	data TEXT NOT NULL,
+	PRIMARY KEY (session_id, seq)
+)`,
+	}
+}
+
+// CreateTables executes Schema.
+func (s *SQLStore) CreateTables(ctx context.Context) error {
+	for _, stmt := range s.Schema() {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// query rewrites ? placeholders for the configured dialect.
+func (s *SQLStore) query(q string) string {
+	if !s.Dollar {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *SQLStore) Load(ctx context.Context, sessionID string) (*Transcript, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return s.load(ctx, tx, sessionID)
+}
+
+func (s *SQLStore) load(ctx context.Context, tx *sql.Tx, sessionID string) (*Transcript, error) {
+	t := &Transcript{SessionID: sessionID}
+	err := tx.QueryRowContext(ctx, s.query(`SELECT parent_id, forked_at FROM `+s.Sessions+` WHERE id = ?`), sessionID).Scan(&t.ParentID, &t.ForkedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, s.query(`SELECT seq, data FROM `+s.Entries+` WHERE session_id = ? ORDER BY seq`), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seq  int
+			data string
+		)
+		if err := rows.Scan(&seq, &data); err != nil {
+			return nil, err
+		}
+		if seq != t.Version()+1 {
+			return nil, fmt.Errorf("conversation: session %q has a gap at entry %d", sessionID, t.Version()+1)
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("conversation: session %q entry %d: %w", sessionID, seq, err)
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	return t, rows.Err()
+}
+
+func (s *SQLStore) count(ctx context.Context, tx *sql.Tx, sessionID string) (n int, exists bool, err error) {
+	err = tx.QueryRowContext(ctx, s.query(`SELECT COUNT(*) FROM `+s.Sessions+` WHERE id = ?`), sessionID).Scan(&n)
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	err = tx.QueryRowContext(ctx, s.query(`SELECT COUNT(*) FROM `+s.Entries+` WHERE session_id = ?`), sessionID).Scan(&n)
+	return n, true, err
+}
+
+func (s *SQLStore) Append(ctx context.Context, sessionID string, version int, entries ...Entry) (int, error) {
+	if err := s.append(ctx, sessionID, version, entries); err != nil {
+		if !errors.Is(err, ErrConflict) {
+			err = s.conflict(ctx, sessionID, version, err)
+		}
+		return 0, err
+	}
+	return version + len(entries), nil
+}
+
+func (s *SQLStore) append(ctx context.Context, sessionID string, version int, entries []Entry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	n, exists, err := s.count(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if n != version || (!exists && version != 0) {
+		return ErrConflict
+	}
+	if !exists {
+		if err := s.insertSession(ctx, tx, sessionID, "", 0); err != nil {
+			return err
+		}
+	}
+	if err := s.insertEntries(ctx, tx, sessionID, version, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conflict reports ErrConflict when a failed write lost a race: another
+// writer created the session or moved it past version in the meantime.
+func (s *SQLStore) conflict(ctx context.Context, sessionID string, version int, err error) error {
+	tx, txErr := s.db.BeginTx(ctx, nil)
+	if txErr != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if n, exists, countErr := s.count(ctx, tx, sessionID); countErr == nil && exists && (n != version || version == 0) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (s *SQLStore) Fork(ctx context.Context, sessionID, newID string, n int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := s.load(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+	if err := checkFork(t, n); err != nil {
+		return err
+	}
+	if _, exists, err := s.count(ctx, tx, newID); err != nil {
+		return err
+	} else if exists {
+		return ErrConflict
+	}
+	if err := s.insertSession(ctx, tx, newID, sessionID, n); err != nil {
+		return err
+	}
+	if err := s.insertEntries(ctx, tx, newID, 0, t.Entries[:n]); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLStore) insertSession(ctx context.Context, tx *sql.Tx, id, parentID string, forkedAt int) error {
+	_, err := tx.ExecContext(ctx, s.query(`INSERT INTO `+s.Sessions+` (id, parent_id, forked_at) VALUES (?, ?, ?)`), id, parentID, forkedAt)
+	return err
+}
+
+func (s *SQLStore) insertEntries(ctx context.Context, tx *sql.Tx, sessionID string, version int, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(ctx, s.query(`INSERT INTO `+s.Entries+` (session_id, seq, role, model, generation_id, created_at, data) VALUES (?, ?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		createdAt := entry.CreatedAt.UTC().Format(time.RFC3339Nano)
+		if _, err := stmt.ExecContext(ctx, sessionID, version+i+1, string(entry.Message.Role), entry.Model, entry.GenerationID, createdAt, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
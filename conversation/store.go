@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+var (
+	// ErrNotFound is returned when a session does not exist.
+	ErrNotFound = errors.New("conversation: session not found")
+	// ErrConflict is returned when a session changed since the version the
+	// caller last saw, or when a fork target already exists.
+	ErrConflict = errors.New("conversation: session was modified concurrently")
+)
+
+// Entry is one persisted message and the turn data that came with it.
+// Assistant entries carry the response's model, generation ID and usage;
+// Metadata is the request metadata of the turn.
+type Entry struct {
+	Message      gopenrouter.ChatCompletionMessage `json:"message"`
+	Model        string                            `json:"model,omitempty"`
+	GenerationID string                            `json:"generation_id,omitempty"`
+	Usage        *gopenrouter.Usage                `json:"usage,omitempty"`
+	Metadata     map[string]string                 `json:"metadata,omitempty"`
+	CreatedAt    time.Time                         `json:"created_at"`
+}
+
+// Transcript is the full, append-only history of a session.
+type Transcript struct {
+	SessionID string
+	// ParentID and ForkedAt are set on sessions created by Fork: the first
+	// ForkedAt entries were copied from ParentID.
+	ParentID string
+	ForkedAt int
+	Entries  []Entry
+}
+
+// Version is the number of entries, which is what Append checks against.
+func (t *Transcript) Version() int {
+	return len(t.Entries)
+}
+
+// Messages returns the messages of all entries.
+func (t *Transcript) Messages() []gopenrouter.ChatCompletionMessage {
+	messages := make([]gopenrouter.ChatCompletionMessage, len(t.Entries))
+	for i, entry := range t.Entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// Store persists sessions. Sessions are append-only: Append succeeds only
+// when version equals the current number of entries, so concurrent writers
+// detect each other with ErrConflict instead of interleaving turns.
+type Store interface {
+	// Load returns the transcript of a session or ErrNotFound.
+	Load(ctx context.Context, sessionID string) (*Transcript, error)
+	// Append adds entries after the first version entries and returns the
+	// new version. Version 0 creates the session if it does not exist.
+	Append(ctx context.Context, sessionID string, version int, entries ...Entry) (int, error)
+	// Fork creates newID with the first n entries of sessionID.
+	Fork(ctx context.Context, sessionID, newID string, n int) error
+}
+
+// MemoryStore keeps sessions in memory.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Transcript
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*Transcript)}
+}
+
+func (s *MemoryStore) Load(ctx context.Context, sessionID string) (*Transcript, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := *t
+	out.Entries = slices.Clone(t.Entries)
+	return &out, nil
+}
+
+func (s *MemoryStore) Append(ctx context.Context, sessionID string, version int, entries ...Entry) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.sessions[sessionID]
+	if !ok {
+		if version != 0 {
+			return 0, ErrConflict
+		}
+		t = &Transcript{SessionID: sessionID}
+		s.sessions[sessionID] = t
+	}
+	if t.Version() != version {
+		return 0, ErrConflict
+	}
+	t.Entries = append(t.Entries, entries...)
+	return t.Version(), nil
+}
+
+func (s *MemoryStore) Fork(ctx context.Context, sessionID, newID string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.sessions[sessionID]
+	if !ok {
+		return ErrNotFound
+	}
+	if err := checkFork(t, n); err != nil {
+		return err
+	}
+	if _, exists := s.sessions[newID]; exists {
+		return ErrConflict
+	}
+	s.sessions[newID] = &Transcript{
+		SessionID: newID,
+		ParentID:  sessionID,
+		ForkedAt:  n,
+		Entries:   slices.Clone(t.Entries[:n]),
+	}
+	return nil
+}
+
+func checkFork(t *Transcript, n int) error {
+	if n < 0 || n > t.Version() {
+		return fmt.Errorf("conversation: cannot fork session %q at %d of %d entries", t.SessionID, n, t.Version())
+	}
+	return nil
+}
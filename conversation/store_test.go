@@ -0,0 +1,381 @@
+package conversation
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+func testEntries() []Entry {
+	index := 0
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []Entry{
+		{Message: gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, Content: "Think about it."}, Metadata: map[string]string{"turn": "1"}, CreatedAt: created},
+		{
+			Message: gopenrouter.ChatCompletionMessage{
+				Role:      gopenrouter.RoleAssistant,
+				Content:   "Done.",
+				Reasoning: "Thinking...",
+				ReasoningDetails: []gopenrouter.ReasoningDetail{
+					{Type: "reasoning.text", Text: "Thinking...", Signature: "sig-abc", Format: "anthropic-claude-v1", Index: &index},
+					{Type: "reasoning.encrypted", Data: "ZW5jcnlwdGVk", Encrypted: "ZW5jcnlwdGVk", ID: "rs_1"},
+				},
+			},
+			Model:        "anthropic/claude-sonnet-4",
+			GenerationID: "gen-1",
+			Usage:        &gopenrouter.Usage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30, Cost: 0.001},
+			Metadata:     map[string]string{"turn": "1"},
+			CreatedAt:    created.Add(time.Second),
+		},
+	}
+}
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	files, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	db, err := sql.Open("conversationfake", t.Name())
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	sqlStore := NewSQLStore(db)
+	if err := sqlStore.CreateTables(context.Background()); err != nil {
+		t.Fatalf("CreateTables: %v", err)
+	}
+	return map[string]Store{"memory": NewMemoryStore(), "file": files, "sql": sqlStore}
+}
+
+func TestStores(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			const id = "session/1"
+			if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+			entries := testEntries()
+			if v, err := store.Append(ctx, id, 0, entries...); err != nil || v != 2 {
+				t.Fatalf("Append: %d, %v", v, err)
+			}
+			if _, err := store.Append(ctx, id, 0, entries[0]); !errors.Is(err, ErrConflict) {
+				t.Fatalf("stale version: expected ErrConflict, got %v", err)
+			}
+			if _, err := store.Append(ctx, "missing", 3, entries[0]); !errors.Is(err, ErrConflict) {
+				t.Fatalf("missing session: expected ErrConflict, got %v", err)
+			}
+
+			got, err := store.Load(ctx, id)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got.SessionID != id || !reflect.DeepEqual(got.Entries, entries) {
+				t.Fatalf("entries not preserved:\n got %+v\nwant %+v", got.Entries, entries)
+			}
+			if v, err := store.Append(ctx, id, 2, Entry{Message: gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, Content: "More."}}); err != nil || v != 3 {
+				t.Fatalf("Append: %d, %v", v, err)
+			}
+
+			if err := store.Fork(ctx, id, "fork", 2); err != nil {
+				t.Fatalf("Fork: %v", err)
+			}
+			fork, err := store.Load(ctx, "fork")
+			if err != nil {
+				t.Fatalf("Load fork: %v", err)
+			}
+			if fork.ParentID != id || fork.ForkedAt != 2 || fork.Version() != 2 || !reflect.DeepEqual(fork.Entries, entries) {
+				t.Fatalf("unexpected fork: %+v", fork)
+			}
+			if _, err := store.Append(ctx, "fork", 2, entries[0]); err != nil {
+				t.Fatalf("Append to fork: %v", err)
+			}
+			if original, _ := store.Load(ctx, id); original.Version() != 3 {
+				t.Fatalf("fork changed the original: %d entries", original.Version())
+			}
+			if err := store.Fork(ctx, id, "fork", 1); !errors.Is(err, ErrConflict) {
+				t.Fatalf("existing fork target: expected ErrConflict, got %v", err)
+			}
+			if err := store.Fork(ctx, id, "other", 4); err == nil {
+				t.Fatal("expected error forking past the end")
+			}
+			if err := store.Fork(ctx, "missing", "other", 0); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			var (
+				wg        sync.WaitGroup
+				mu        sync.Mutex
+				won, lost int
+			)
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := store.Append(ctx, id, 3, entries[0])
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						won++
+					case errors.Is(err, ErrConflict):
+						lost++
+					default:
+						t.Errorf("Append: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if won != 1 || lost != 7 {
+				t.Fatalf("expected exactly one concurrent append to win, got %d won, %d lost", won, lost)
+			}
+		})
+	}
+}
+
+func TestSQLStoreDollarPlaceholders(t *testing.T) {
+	s := &SQLStore{Dollar: true}
+	if got := s.query("INSERT INTO t (a, b) VALUES (?, ?)"); got != "INSERT INTO t (a, b) VALUES ($1, $2)" {
+		t.Fatalf("unexpected query %q", got)
+	}
+}
+
+func TestConversationStore(t *testing.T) {
+	var sessions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req gopenrouter.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		sessions = append(sessions, req.SessionID)
+		n := len(sessions)
+		fmt.Fprintf(w, `{"id":"gen-%d","model":"test/model","choices":[{"message":{"role":"assistant","content":"reply %d","reasoning_details":[{"type":"reasoning.encrypted","data":"blob-%d"}]}}],"usage":{"prompt_tokens":%d,"completion_tokens":5,"total_tokens":%d}}`, n, n, n, n*10, n*10+5)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := gopenrouter.NewClientWithConfig(cfg)
+	ctx := context.Background()
+	store := NewMemoryStore()
+	config := Config{Model: "test/model", Store: store, SessionID: "chat-1"}
+
+	conv, err := Resume(ctx, client, config)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	conv.Append(gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleSystem, Content: "sys"})
+	for i := range 2 {
+		_, err := conv.Send(ctx, gopenrouter.ChatCompletionRequest{
+			Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: fmt.Sprint("q", i)}},
+			Metadata: map[string]string{"turn": fmt.Sprint(i)},
+		})
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if strings.Join(sessions, ",") != "chat-1,chat-1" {
+		t.Fatalf("session id not sent: %v", sessions)
+	}
+
+	transcript, err := store.Load(ctx, "chat-1")
+	if err != nil || transcript.Version() != 5 {
+		t.Fatalf("unexpected transcript: %+v, %v", transcript, err)
+	}
+	reply := transcript.Entries[4]
+	if reply.GenerationID != "gen-2" || reply.Model != "test/model" || reply.Usage.PromptTokens != 20 || reply.Metadata["turn"] != "1" {
+		t.Fatalf("turn data not persisted: %+v", reply)
+	}
+	if reply.Message.ReasoningDetails[0].Data != "blob-2" || transcript.Entries[3].Metadata["turn"] != "1" || transcript.Entries[0].CreatedAt.IsZero() {
+		t.Fatalf("unexpected entries: %+v", transcript.Entries)
+	}
+
+	resumed, err := Resume(ctx, client, config)
+	if err != nil || !reflect.DeepEqual(resumed.Messages(), conv.Messages()) {
+		t.Fatalf("Resume: %v", err)
+	}
+	fork, err := conv.Fork(ctx, "chat-2", 3)
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if got := fork.Messages(); len(got) != 3 || got[2].Content != "reply 1" {
+		t.Fatalf("unexpected fork history: %q", roles(got))
+	}
+
+	if _, err := conv.Send(ctx, gopenrouter.ChatCompletionRequest{Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "a"}}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	res, err := resumed.Send(ctx, gopenrouter.ChatCompletionRequest{Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "b"}}})
+	if !errors.Is(err, ErrConflict) || res == nil {
+		t.Fatalf("expected ErrConflict with the response, got %v, %v", res, err)
+	}
+}
+
+// fakeDriver is a minimal database/sql driver that understands the
+// statements SQLStore issues. Transactions are serialized and roll back by
+// restoring a snapshot.
+type fakeDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+type fakeDB struct {
+	mu       sync.Mutex
+	sessions map[string][2]driver.Value
+	entries  map[string]map[int64]string
+}
+
+func init() {
+	sql.Register("conversationfake", &fakeDriver{dbs: map[string]*fakeDB{}})
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeDB{sessions: map[string][2]driver.Value{}, entries: map[string]map[int64]string{}}
+		d.dbs[name] = db
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db       *fakeDB
+	snapshot *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	c.snapshot = &fakeDB{sessions: map[string][2]driver.Value{}, entries: map[string]map[int64]string{}}
+	for k, v := range c.db.sessions {
+		c.snapshot.sessions[k] = v
+	}
+	for k, v := range c.db.entries {
+		rows := map[int64]string{}
+		for seq, data := range v {
+			rows[seq] = data
+		}
+		c.snapshot.entries[k] = rows
+	}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.snapshot = nil
+	c.db.mu.Unlock()
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.sessions, c.db.entries = c.snapshot.sessions, c.snapshot.entries
+	c.snapshot = nil
+	c.db.mu.Unlock()
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+	case strings.HasPrefix(s.query, "INSERT INTO conversation_sessions"):
+		id := args[0].(string)
+		if _, exists := db.sessions[id]; exists {
+			return nil, errors.New("fake: duplicate session")
+		}
+		db.sessions[id] = [2]driver.Value{args[1], args[2]}
+	case strings.HasPrefix(s.query, "INSERT INTO conversation_entries"):
+		id, seq := args[0].(string), args[1].(int64)
+		if db.entries[id] == nil {
+			db.entries[id] = map[int64]string{}
+		}
+		if _, exists := db.entries[id][seq]; exists {
+			return nil, errors.New("fake: duplicate entry")
+		}
+		db.entries[id][seq] = args[6].(string)
+	default:
+		return nil, fmt.Errorf("fake: unsupported exec %q", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	id := args[0].(string)
+	rows := &fakeRows{}
+	switch s.query {
+	case "SELECT parent_id, forked_at FROM conversation_sessions WHERE id = ?":
+		rows.columns = []string{"parent_id", "forked_at"}
+		if session, ok := db.sessions[id]; ok {
+			rows.values = append(rows.values, session[:])
+		}
+	case "SELECT COUNT(*) FROM conversation_sessions WHERE id = ?":
+		var n int64
+		if _, ok := db.sessions[id]; ok {
+			n = 1
+		}
+		rows.columns = []string{"count"}
+		rows.values = [][]driver.Value{{n}}
+	case "SELECT COUNT(*) FROM conversation_entries WHERE session_id = ?":
+		rows.columns = []string{"count"}
+		rows.values = [][]driver.Value{{int64(len(db.entries[id]))}}
+	case "SELECT seq, data FROM conversation_entries WHERE session_id = ? ORDER BY seq":
+		rows.columns = []string{"seq", "data"}
+		var seqs []int64
+		for seq := range db.entries[id] {
+			seqs = append(seqs, seq)
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		for _, seq := range seqs {
+			rows.values = append(rows.values, []driver.Value{seq, db.entries[id][seq]})
+		}
+	default:
+		return nil, fmt.Errorf("fake: unsupported query %q", s.query)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,689 @@
+package convert
+
+import (
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+const anthropicReasoningFormat = "anthropic-claude-v1"
+
+// anthropicThinkingBudgets maps a reasoning effort to a thinking budget when a
+// chat request only carries an effort level.
+var anthropicThinkingBudgets = map[gopenrouter.ReasoningEffort]int{
+	gopenrouter.ReasoningEffortMinimal: 1024,
+	gopenrouter.ReasoningEffortLow:     2048,
+	gopenrouter.ReasoningEffortMedium:  8192,
+	gopenrouter.ReasoningEffortHigh:    16384,
+	gopenrouter.ReasoningEffortXHigh:   32768,
+}
+
+// ChatToAnthropicRequest converts a chat completion request into an Anthropic messages request.
+// System and developer messages are hoisted into System, tool results become
+// tool_result blocks and consecutive messages of the same role are merged.
+func ChatToAnthropicRequest(req gopenrouter.ChatCompletionRequest) (anthropic.Request, error) {
+	system, messages, err := ChatMessagesToAnthropic(req.Messages)
+	if err != nil {
+		return anthropic.Request{}, err
+	}
+	tools, err := chatToolsToAnthropic(req.Tools)
+	if err != nil {
+		return anthropic.Request{}, err
+	}
+	choice, err := parseChatToolChoice(req.ToolChoice)
+	if err != nil {
+		return anthropic.Request{}, err
+	}
+
+	out := anthropic.Request{
+		Model:         req.Model,
+		MaxTokens:     maxTokens(req),
+		Messages:      messages,
+		System:        system,
+		StopSequences: req.Stop,
+		Stream:        req.Stream,
+		Temperature:   floatPtr(req.Temperature),
+		TopP:          floatPtr(req.TopP),
+		TopK:          req.TopK,
+		Tools:         tools,
+		ToolChoice:    anthropicToolChoice(choice, req.ParallelToolCalls),
+		Thinking:      anthropicThinking(req.Reasoning),
+		Provider:      req.Provider,
+	}
+	if req.User != "" {
+		out.Metadata = map[string]any{"user_id": req.User}
+	}
+
+	extra := map[string]any{}
+	for key, value := range req.ExtraBody {
+		extra[key] = value
+	}
+	if len(req.Models) > 0 {
+		extra["models"] = req.Models
+	}
+	if len(req.Plugins) > 0 {
+		extra["plugins"] = req.Plugins
+	}
+	if req.SessionID != "" {
+		extra["session_id"] = req.SessionID
+	}
+	if req.Trace != nil {
+		extra["trace"] = req.Trace
+	}
+	if len(extra) > 0 {
+		out.ExtraBody = extra
+	}
+	return out, nil
+}
+
+// AnthropicToChatRequest converts an Anthropic messages request into a chat completion request.
+func AnthropicToChatRequest(req anthropic.Request) (gopenrouter.ChatCompletionRequest, error) {
+	messages, err := AnthropicMessagesToChat(req.System, req.Messages)
+	if err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+	tools, err := anthropicToolsToChat(req.Tools)
+	if err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+
+	out := gopenrouter.ChatCompletionRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Stop:        req.StopSequences,
+		Stream:      req.Stream,
+		Temperature: floatValue(req.Temperature),
+		TopP:        floatValue(req.TopP),
+		TopK:        req.TopK,
+		Tools:       tools,
+		Provider:    req.Provider,
+	}
+	if req.MaxTokens > 0 {
+		out.MaxCompletionTokens = &req.MaxTokens
+	}
+	if user, ok := req.Metadata["user_id"].(string); ok {
+		out.User = user
+	}
+	if err := applyAnthropicToolChoice(&out, req.ToolChoice); err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+	if err := applyAnthropicThinking(&out, req.Thinking); err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+
+	extra := map[string]any{}
+	for key, value := range req.ExtraBody {
+		var err error
+		switch key {
+		case "models":
+			err = decodeAs(value, &out.Models)
+		case "plugins":
+			err = decodeAs(value, &out.Plugins)
+		case "session_id":
+			out.SessionID, _ = value.(string)
+		case "trace":
+			out.Trace = &shared.TraceMetadata{}
+			err = decodeAs(value, out.Trace)
+		default:
+			extra[key] = value
+		}
+		if err != nil {
+			return gopenrouter.ChatCompletionRequest{}, err
+		}
+	}
+	if len(extra) > 0 {
+		out.ExtraBody = extra
+	}
+	return out, nil
+}
+
+// ChatMessagesToAnthropic converts chat messages into an Anthropic system prompt and messages.
+// Messages without content are dropped, and a tool message starting with
+// "error:" becomes a tool_result with is_error set.
+func ChatMessagesToAnthropic(messages []gopenrouter.ChatCompletionMessage) (any, []anthropic.Message, error) {
+	var (
+		system []anthropic.SystemBlock
+		out    []anthropic.Message
+		blocks [][]anthropic.ContentBlock
+	)
+	appendBlocks := func(role string, content []anthropic.ContentBlock) {
+		// Anthropic rejects empty turns, so messages without content are
+		// dropped and the turns around them merge.
+		if len(content) == 0 {
+			return
+		}
+		if len(out) > 0 && out[len(out)-1].Role == role {
+			blocks[len(blocks)-1] = append(blocks[len(blocks)-1], content...)
+			return
+		}
+		out = append(out, anthropic.Message{Role: role})
+		blocks = append(blocks, content)
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case gopenrouter.RoleSystem, gopenrouter.RoleDeveloper:
+			content, err := chatContentToAnthropic(msg)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, block := range content {
+				if block.Type != anthropic.BlockTypeText {
+					return nil, nil, unsupported("%s content block %q in Anthropic system prompt", msg.Role, block.Type)
+				}
+				system = append(system, anthropic.NewSystemBlock(block.Text).WithCacheControl(block.CacheControl))
+			}
+		case gopenrouter.RoleTool:
+			msg, isError := unmarkToolError(msg)
+			result := anthropic.NewToolResultBlock(msg.ToolCallID)
+			result.IsError = isError
+			if len(msg.MultiContent) > 0 {
+				content, err := chatContentToAnthropic(msg)
+				if err != nil {
+					return nil, nil, err
+				}
+				result.Content = content
+			} else if msg.Content != "" {
+				// Anthropic rejects empty text blocks; a result without
+				// content is valid.
+				result.Content = anthropic.ToolResultContent{anthropic.NewTextBlock(msg.Content)}
+			}
+			appendBlocks(gopenrouter.RoleUser, []anthropic.ContentBlock{result})
+		case gopenrouter.RoleAssistant:
+			content := reasoningDetailsToAnthropic(msg.ReasoningDetails)
+			if text := messageText(msg); text != "" {
+				content = append(content, anthropic.NewTextBlock(text))
+			}
+			for _, call := range msg.ToolCalls {
+				input, err := toolCallArguments(call.Function.Arguments)
+				if err != nil {
+					return nil, nil, err
+				}
+				block, err := anthropic.NewToolUseBlock(call.ID, call.Function.Name, input)
+				if err != nil {
+					return nil, nil, err
+				}
+				content = append(content, block)
+			}
+			appendBlocks(gopenrouter.RoleAssistant, content)
+		case gopenrouter.RoleUser:
+			content, err := chatContentToAnthropic(msg)
+			if err != nil {
+				return nil, nil, err
+			}
+			appendBlocks(gopenrouter.RoleUser, content)
+		default:
+			return nil, nil, unsupported("chat role %q in Anthropic messages", msg.Role)
+		}
+	}
+
+	for i := range out {
+		out[i].Content = blocks[i]
+		if len(blocks[i]) == 1 && blocks[i][0].Type == anthropic.BlockTypeText && blocks[i][0].CacheControl == nil {
+			out[i].Content = blocks[i][0].Text
+		}
+	}
+	var systemPrompt any
+	switch {
+	case len(system) == 1 && system[0].CacheControl == nil:
+		systemPrompt = system[0].Text
+	case len(system) > 0:
+		systemPrompt = system
+	}
+	return systemPrompt, out, nil
+}
+
+func chatContentToAnthropic(msg gopenrouter.ChatCompletionMessage) ([]anthropic.ContentBlock, error) {
+	if len(msg.MultiContent) == 0 {
+		if msg.Content == "" {
+			return nil, nil
+		}
+		return []anthropic.ContentBlock{anthropic.NewTextBlock(msg.Content)}, nil
+	}
+	blocks := make([]anthropic.ContentBlock, 0, len(msg.MultiContent))
+	for _, part := range msg.MultiContent {
+		var block anthropic.ContentBlock
+		switch {
+		case part.Type == "text":
+			if part.Text == "" {
+				continue
+			}
+			block = anthropic.NewTextBlock(part.Text)
+		case part.Type == "image_url" && part.ImageURL != nil:
+			block = anthropic.NewImageBlock(anthropicSource(part.ImageURL.URL))
+		case part.Type == "file" && part.File != nil:
+			block = anthropic.NewDocumentBlock(anthropicSource(part.File.FileData), part.File.Filename)
+		default:
+			return nil, unsupported("chat content part %q in Anthropic messages", part.Type)
+		}
+		blocks = append(blocks, block.WithCacheControl(part.CacheControl))
+	}
+	return blocks, nil
+}
+
+func anthropicSource(raw string) *anthropic.Source {
+	if mediaType, data, ok := parseDataURL(raw); ok {
+		return anthropic.Base64Source(mediaType, data)
+	}
+	return anthropic.URLSource(raw)
+}
+
+func reasoningDetailsToAnthropic(details []gopenrouter.ReasoningDetail) []anthropic.ContentBlock {
+	var blocks []anthropic.ContentBlock
+	for _, detail := range details {
+		switch detail.Type {
+		case "reasoning.text":
+			blocks = append(blocks, anthropic.NewThinkingBlock(detail.Text, detail.Signature))
+		case "reasoning.encrypted":
+			blocks = append(blocks, anthropic.NewRedactedThinkingBlock(detail.Data))
+		}
+	}
+	return blocks
+}
+
+func anthropicToolChoice(choice toolChoice, parallel *bool) *anthropic.ToolChoice {
+	var out *anthropic.ToolChoice
+	switch choice.mode {
+	case "":
+	case toolChoiceAuto:
+		out = anthropic.AutoToolChoice()
+	case toolChoiceRequired:
+		out = anthropic.AnyToolChoice()
+	case toolChoiceNone:
+		out = anthropic.NoToolChoice()
+	case toolChoiceFunction:
+		out = anthropic.NamedToolChoice(choice.name)
+	}
+	if parallel != nil && !*parallel {
+		if out == nil {
+			out = anthropic.AutoToolChoice()
+		}
+		out.DisableParallelToolUse = true
+	}
+	return out
+}
+
+func applyAnthropicToolChoice(req *gopenrouter.ChatCompletionRequest, v *anthropic.ToolChoice) error {
+	if v == nil {
+		return nil
+	}
+	var choice toolChoice
+	switch v.Type {
+	case anthropic.ToolChoiceAuto:
+		choice.mode = toolChoiceAuto
+	case anthropic.ToolChoiceAny:
+		choice.mode = toolChoiceRequired
+	case anthropic.ToolChoiceNone:
+		choice.mode = toolChoiceNone
+	case anthropic.ToolChoiceTool:
+		choice = toolChoice{mode: toolChoiceFunction, name: v.Name}
+	default:
+		return unsupported("Anthropic tool choice %q", v.Type)
+	}
+	req.ToolChoice = choice.chat()
+	if v.DisableParallelToolUse {
+		parallel := false
+		req.ParallelToolCalls = &parallel
+	}
+	return nil
+}
+
+func anthropicThinking(reasoning *gopenrouter.ReasoningParams) *anthropic.ThinkingConfig {
+	if reasoning == nil {
+		return nil
+	}
+	if reasoning.Effort == gopenrouter.ReasoningEffortNone {
+		return anthropic.DisabledThinking()
+	}
+	budget := reasoning.MaxTokens
+	if budget == 0 {
+		budget = anthropicThinkingBudgets[reasoning.Effort]
+	}
+	if budget == 0 {
+		return nil
+	}
+	return anthropic.EnabledThinking(budget)
+}
+
+func applyAnthropicThinking(req *gopenrouter.ChatCompletionRequest, v *anthropic.ThinkingConfig) error {
+	if v == nil {
+		return nil
+	}
+	switch v.Type {
+	case anthropic.ThinkingEnabled:
+		req.Reasoning = &gopenrouter.ReasoningParams{MaxTokens: v.BudgetTokens}
+	case anthropic.ThinkingDisabled:
+		req.Reasoning = &gopenrouter.ReasoningParams{Effort: gopenrouter.ReasoningEffortNone}
+	default:
+		return unsupported("Anthropic thinking type %q", v.Type)
+	}
+	return nil
+}
+
+// AnthropicMessagesToChat converts an Anthropic system prompt and messages into chat messages.
+// tool_result blocks become tool messages that precede the rest of their user turn.
+func AnthropicMessagesToChat(system any, messages []anthropic.Message) ([]gopenrouter.ChatCompletionMessage, error) {
+	var out []gopenrouter.ChatCompletionMessage
+	systemBlocks, err := anthropic.Request{System: system}.SystemBlocks()
+	if err != nil {
+		return nil, err
+	}
+	if len(systemBlocks) > 0 {
+		blocks := make([]anthropic.ContentBlock, 0, len(systemBlocks))
+		for _, block := range systemBlocks {
+			blocks = append(blocks, anthropic.NewTextBlock(block.Text).WithCacheControl(block.CacheControl))
+		}
+		msg, err := anthropicBlocksToChat(gopenrouter.RoleSystem, blocks)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, msg)
+	}
+
+	for _, message := range messages {
+		blocks, err := message.Blocks()
+		if err != nil {
+			return nil, err
+		}
+		switch message.Role {
+		case gopenrouter.RoleAssistant:
+			out = append(out, anthropicAssistantToChat(blocks))
+		case gopenrouter.RoleUser:
+			var rest []anthropic.ContentBlock
+			for _, block := range blocks {
+				if block.Type != anthropic.BlockTypeToolResult {
+					rest = append(rest, block)
+					continue
+				}
+				msg, err := anthropicBlocksToChat(gopenrouter.RoleTool, block.Content)
+				if err != nil {
+					return nil, err
+				}
+				msg.ToolCallID = block.ToolUseID
+				if block.IsError {
+					markToolError(&msg)
+				}
+				out = append(out, msg)
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			msg, err := anthropicBlocksToChat(gopenrouter.RoleUser, rest)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, msg)
+		default:
+			return nil, unsupported("Anthropic role %q in chat completions", message.Role)
+		}
+	}
+	return out, nil
+}
+
+func anthropicBlocksToChat(role gopenrouter.ChatCompletionMessageRole, blocks []anthropic.ContentBlock) (gopenrouter.ChatCompletionMessage, error) {
+	msg := gopenrouter.ChatCompletionMessage{Role: role}
+	if len(blocks) == 1 && blocks[0].Type == anthropic.BlockTypeText && blocks[0].CacheControl == nil {
+		msg.Content = blocks[0].Text
+		return msg, nil
+	}
+	for _, block := range blocks {
+		var part gopenrouter.ChatCompletionMessagePart
+		switch block.Type {
+		case anthropic.BlockTypeText:
+			part = gopenrouter.ChatCompletionMessagePart{Type: "text", Text: block.Text}
+		case anthropic.BlockTypeImage:
+			if block.Source == nil {
+				return msg, unsupported("Anthropic image block without source")
+			}
+			part = gopenrouter.ChatCompletionMessagePart{Type: "image_url", ImageURL: &shared.ImageURL{URL: anthropicSourceURL(*block.Source)}}
+		case anthropic.BlockTypeDocument:
+			if block.Source == nil {
+				return msg, unsupported("Anthropic document block without source")
+			}
+			if block.Source.Type == anthropic.SourceTypeText {
+				part = gopenrouter.ChatCompletionMessagePart{Type: "text", Text: block.Source.Data}
+				break
+			}
+			part = gopenrouter.ChatCompletionMessagePart{Type: "file", File: &shared.File{Filename: block.Title, FileData: anthropicSourceURL(*block.Source)}}
+		default:
+			return msg, unsupported("Anthropic content block %q in chat completions", block.Type)
+		}
+		part.CacheControl = block.CacheControl
+		msg.MultiContent = append(msg.MultiContent, part)
+	}
+	return msg, nil
+}
+
+// markToolError prefixes a tool message with "error: ", the convention of
+// ToolSet.ToolMessage, since chat has no is_error flag. unmarkToolError
+// reverses it.
+func markToolError(msg *gopenrouter.ChatCompletionMessage) {
+	if len(msg.MultiContent) > 0 {
+		msg.MultiContent = append([]gopenrouter.ChatCompletionMessagePart{{Type: "text", Text: "error:"}}, msg.MultiContent...)
+		return
+	}
+	msg.Content = strings.TrimSuffix("error: "+msg.Content, " ")
+}
+
+// unmarkToolError strips the prefix added by markToolError and reports
+// whether the tool message carried it.
+func unmarkToolError(msg gopenrouter.ChatCompletionMessage) (gopenrouter.ChatCompletionMessage, bool) {
+	if len(msg.MultiContent) > 0 {
+		if first := msg.MultiContent[0]; first.Type == "text" && first.Text == "error:" {
+			msg.MultiContent = msg.MultiContent[1:]
+			return msg, true
+		}
+		return msg, false
+	}
+	content, ok := strings.CutPrefix(msg.Content, "error:")
+	if !ok {
+		return msg, false
+	}
+	msg.Content = strings.TrimPrefix(content, " ")
+	return msg, true
+}
+
+func anthropicSourceURL(source anthropic.Source) string {
+	if source.Type == anthropic.SourceTypeBase64 {
+		return dataURL(source.MediaType, source.Data)
+	}
+	return source.URL
+}
+
+func anthropicAssistantToChat(blocks []anthropic.ContentBlock) gopenrouter.ChatCompletionMessage {
+	msg := gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleAssistant}
+	var reasoning strings.Builder
+	for _, block := range blocks {
+		switch block.Type {
+		case anthropic.BlockTypeText:
+			msg.Content += block.Text
+		case anthropic.BlockTypeThinking:
+			reasoning.WriteString(block.Thinking)
+			msg.ReasoningDetails = append(msg.ReasoningDetails, gopenrouter.ReasoningDetail{
+				Type:      "reasoning.text",
+				Text:      block.Thinking,
+				Signature: block.Signature,
+				Format:    anthropicReasoningFormat,
+			})
+		case anthropic.BlockTypeRedactedThinking:
+			msg.ReasoningDetails = append(msg.ReasoningDetails, gopenrouter.ReasoningDetail{
+				Type:   "reasoning.encrypted",
+				Data:   block.Data,
+				Format: anthropicReasoningFormat,
+			})
+		case anthropic.BlockTypeToolUse:
+			arguments := "{}"
+			if len(block.Input) > 0 {
+				arguments = string(block.Input)
+			}
+			msg.ToolCalls = append(msg.ToolCalls, gopenrouter.ToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: gopenrouter.Function{Name: block.Name, Arguments: arguments},
+			})
+		}
+	}
+	msg.Reasoning = reasoning.String()
+	return msg
+}
+
+func chatToolsToAnthropic(tools []gopenrouter.Tool) ([]anthropic.Tool, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	out := make([]anthropic.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Type != "" && tool.Type != "function" {
+			return nil, unsupported("chat tool type %q", tool.Type)
+		}
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		out = append(out, anthropic.Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return out, nil
+}
+
+func anthropicToolsToChat(tools []anthropic.Tool) ([]gopenrouter.Tool, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	out := make([]gopenrouter.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Type != "" && tool.Type != "custom" {
+			return nil, unsupported("Anthropic tool type %q in chat completions", tool.Type)
+		}
+		out = append(out, gopenrouter.Tool{
+			Type: "function",
+			Function: gopenrouter.Function{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.InputSchema,
+			},
+		})
+	}
+	return out, nil
+}
+
+var anthropicStopReasons = map[string]string{
+	"end_turn":      "stop",
+	"stop_sequence": "stop",
+	"pause_turn":    "stop",
+	"max_tokens":    "length",
+	"tool_use":      "tool_calls",
+	"refusal":       "content_filter",
+}
+
+// AnthropicToChatResponse converts an Anthropic messages response into a chat completion response.
+func AnthropicToChatResponse(res *anthropic.Response) (*gopenrouter.ChatCompletionResponse, error) {
+	if res == nil {
+		return nil, nil
+	}
+	msg := anthropicAssistantToChat(res.Content)
+	finishReason, ok := anthropicStopReasons[res.StopReason]
+	if !ok {
+		finishReason = res.StopReason
+	}
+	return &gopenrouter.ChatCompletionResponse{
+		ID:     res.ID,
+		Object: "chat.completion",
+		Model:  res.Model,
+		Choices: []gopenrouter.Choice{{
+			Message:            msg,
+			FinishReason:       finishReason,
+			NativeFinishReason: res.StopReason,
+		}},
+		Usage: AnthropicUsageToChat(res.Usage),
+	}, nil
+}
+
+// ChatResponseToAnthropic converts the first choice of a chat completion response
+// into an Anthropic messages response.
+func ChatResponseToAnthropic(res *gopenrouter.ChatCompletionResponse) (*anthropic.Response, error) {
+	if res == nil {
+		return nil, nil
+	}
+	out := &anthropic.Response{
+		ID:    res.ID,
+		Type:  "message",
+		Role:  gopenrouter.RoleAssistant,
+		Model: res.Model,
+		Usage: ChatUsageToAnthropic(res.Usage),
+	}
+	if len(res.Choices) == 0 {
+		return out, nil
+	}
+	choice := res.Choices[0]
+	switch choice.FinishReason {
+	case "length":
+		out.StopReason = "max_tokens"
+	case "tool_calls":
+		out.StopReason = "tool_use"
+	case "content_filter":
+		out.StopReason = "refusal"
+	default:
+		out.StopReason = "end_turn"
+	}
+
+	msg := choice.Message
+	out.Content = reasoningDetailsToAnthropic(msg.ReasoningDetails)
+	if text := messageText(msg); text != "" {
+		out.Content = append(out.Content, anthropic.NewTextBlock(text))
+	}
+	for _, call := range msg.ToolCalls {
+		input, err := toolCallArguments(call.Function.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		block, err := anthropic.NewToolUseBlock(call.ID, call.Function.Name, input)
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, block)
+	}
+	return out, nil
+}
+
+// ResponsesToAnthropicRequest converts a Responses API request into an Anthropic messages request.
+func ResponsesToAnthropicRequest(req responses.Request) (anthropic.Request, error) {
+	chat, err := ResponsesToChatRequest(req)
+	if err != nil {
+		return anthropic.Request{}, err
+	}
+	return ChatToAnthropicRequest(chat)
+}
+
+// AnthropicToResponsesRequest converts an Anthropic messages request into a Responses API request.
+func AnthropicToResponsesRequest(req anthropic.Request) (responses.Request, error) {
+	chat, err := AnthropicToChatRequest(req)
+	if err != nil {
+		return responses.Request{}, err
+	}
+	return ChatToResponsesRequest(chat)
+}
+
+// ResponsesToAnthropicResponse converts a Responses API response into an Anthropic messages response.
+func ResponsesToAnthropicResponse(res *responses.Response) (*anthropic.Response, error) {
+	chat, err := ResponsesToChatResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	return ChatResponseToAnthropic(chat)
+}
+
+// AnthropicToResponsesResponse converts an Anthropic messages response into a Responses API response.
+func AnthropicToResponsesResponse(res *anthropic.Response) (*responses.Response, error) {
+	chat, err := AnthropicToChatResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	return ChatResponseToResponses(chat)
+}
@@ -0,0 +1,167 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+// ErrUnsupported is returned when a value has no equivalent in the target dialect.
+var ErrUnsupported = errors.New("convert: unsupported")
+
+func unsupported(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, fmt.Sprintf(format, args...))
+}
+
+const (
+	toolChoiceAuto     = "auto"
+	toolChoiceNone     = "none"
+	toolChoiceRequired = "required"
+	toolChoiceFunction = "function"
+)
+
+// toolChoice is the dialect-neutral form of a tool choice.
+type toolChoice struct {
+	mode string
+	name string
+}
+
+func parseChatToolChoice(v any) (toolChoice, error) {
+	if v == nil {
+		return toolChoice{}, nil
+	}
+	if s, ok := v.(string); ok {
+		return toolChoice{mode: s}, nil
+	}
+	var decoded struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := decodeAs(v, &decoded); err != nil {
+		return toolChoice{}, err
+	}
+	name := decoded.Function.Name
+	if name == "" {
+		name = decoded.Name
+	}
+	if name == "" {
+		return toolChoice{}, unsupported("tool choice %v", v)
+	}
+	return toolChoice{mode: toolChoiceFunction, name: name}, nil
+}
+
+func (c toolChoice) chat() any {
+	switch c.mode {
+	case "":
+		return nil
+	case toolChoiceFunction:
+		return map[string]any{"type": "function", "function": map[string]any{"name": c.name}}
+	default:
+		return c.mode
+	}
+}
+
+func (c toolChoice) responses() any {
+	switch c.mode {
+	case "":
+		return nil
+	case toolChoiceFunction:
+		return map[string]any{"type": "function", "name": c.name}
+	default:
+		return c.mode
+	}
+}
+
+func toolCallArguments(arguments string) (json.RawMessage, error) {
+	arguments = strings.TrimSpace(arguments)
+	if arguments == "" {
+		return json.RawMessage("{}"), nil
+	}
+	if !json.Valid([]byte(arguments)) {
+		return nil, fmt.Errorf("convert: tool call arguments are not valid JSON: %q", arguments)
+	}
+	return json.RawMessage(arguments), nil
+}
+
+// parseDataURL splits a base64 data URL into its media type and payload.
+func parseDataURL(raw string) (mediaType, data string, ok bool) {
+	rest, found := strings.CutPrefix(raw, "data:")
+	if !found {
+		return "", "", false
+	}
+	meta, payload, found := strings.Cut(rest, ",")
+	if !found {
+		return "", "", false
+	}
+	mediaType, encoding, _ := strings.Cut(meta, ";")
+	if encoding != "base64" {
+		return "", "", false
+	}
+	return mediaType, payload, true
+}
+
+func dataURL(mediaType, data string) string {
+	return "data:" + mediaType + ";base64," + data
+}
+
+func decodeAs(v any, out any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+func messageText(m gopenrouter.ChatCompletionMessage) string {
+	if len(m.MultiContent) == 0 {
+		return m.Content
+	}
+	var b strings.Builder
+	for _, part := range m.MultiContent {
+		if part.Type == "text" {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+func floatPtr(v float64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func floatValue(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func maxTokens(req gopenrouter.ChatCompletionRequest) int {
+	if req.MaxCompletionTokens != nil {
+		return *req.MaxCompletionTokens
+	}
+	return req.MaxTokens
+}
+
+func intPtr(v int) *int {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func finishReasonFromToolCalls(msg gopenrouter.ChatCompletionMessage, fallback string) string {
+	if len(msg.ToolCalls) > 0 {
+		return "tool_calls"
+	}
+	return fallback
+}
@@ -0,0 +1,274 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func testConversation() []gopenrouter.ChatCompletionMessage {
+	return []gopenrouter.ChatCompletionMessage{
+		{Role: gopenrouter.RoleSystem, Content: "You are terse."},
+		{Role: gopenrouter.RoleUser, MultiContent: []gopenrouter.ChatCompletionMessagePart{
+			{Type: "text", Text: "What is in this image?"},
+			{Type: "image_url", ImageURL: &shared.ImageURL{URL: "data:image/png;base64,AAAA"}},
+		}},
+		{
+			Role:      gopenrouter.RoleAssistant,
+			Reasoning: "Need the weather.",
+			ReasoningDetails: []gopenrouter.ReasoningDetail{
+				{Type: "reasoning.text", Text: "Need the weather.", Signature: "sig", Format: anthropicReasoningFormat},
+			},
+			ToolCalls: []gopenrouter.ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: gopenrouter.Function{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}},
+		},
+		{Role: gopenrouter.RoleTool, ToolCallID: "call_1", Content: "sunny"},
+		{Role: gopenrouter.RoleAssistant, Content: "A cat in the sun."},
+	}
+}
+
+func TestChatAnthropicRoundTrip(t *testing.T) {
+	parallel := false
+	req := gopenrouter.ChatCompletionRequest{
+		Model:             "anthropic/claude-sonnet-4",
+		Messages:          testConversation(),
+		ToolChoice:        "required",
+		ParallelToolCalls: &parallel,
+		SessionID:         "session-1",
+		Tools: []gopenrouter.Tool{{Type: "function", Function: gopenrouter.Function{
+			Name:       "get_weather",
+			Parameters: map[string]any{"type": "object"},
+		}}},
+	}
+
+	converted, err := ChatToAnthropicRequest(req)
+	if err != nil {
+		t.Fatalf("ChatToAnthropicRequest: %v", err)
+	}
+	if converted.System != "You are terse." {
+		t.Fatalf("unexpected system: %#v", converted.System)
+	}
+	if len(converted.Messages) != 4 {
+		t.Fatalf("expected 4 anthropic messages, got %d", len(converted.Messages))
+	}
+	if choice := converted.ToolChoice; choice == nil || choice.Type != anthropic.ToolChoiceAny || !choice.DisableParallelToolUse {
+		t.Fatalf("unexpected tool choice: %+v", choice)
+	}
+	encoded, err := json.Marshal(converted.Messages[1].Content)
+	if err != nil {
+		t.Fatalf("marshal assistant content: %v", err)
+	}
+	want := `[{"type":"thinking","thinking":"Need the weather.","signature":"sig"},{"type":"tool_use","id":"call_1","name":"get_weather","input":{"city":"Paris"}}]`
+	if string(encoded) != want {
+		t.Fatalf("unexpected assistant blocks:\n got %s\nwant %s", encoded, want)
+	}
+
+	// Round-trip through the wire format to make sure decoding does not rely on Go types.
+	var wire anthropic.Request
+	b, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	if err := json.Unmarshal(b, &wire); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	wire.ExtraBody = map[string]any{"session_id": "session-1"}
+
+	back, err := AnthropicToChatRequest(wire)
+	if err != nil {
+		t.Fatalf("AnthropicToChatRequest: %v", err)
+	}
+	if !reflect.DeepEqual(back.Messages, req.Messages) {
+		t.Fatalf("messages did not round-trip:\n got %+v\nwant %+v", back.Messages, req.Messages)
+	}
+	if back.ToolChoice != "required" || back.ParallelToolCalls == nil || *back.ParallelToolCalls {
+		t.Fatalf("tool choice did not round-trip: %#v %v", back.ToolChoice, back.ParallelToolCalls)
+	}
+	if back.SessionID != "session-1" {
+		t.Fatalf("session id did not round-trip: %q", back.SessionID)
+	}
+}
+
+func TestAnthropicToolResultEdgeCases(t *testing.T) {
+	_, messages, err := ChatMessagesToAnthropic([]gopenrouter.ChatCompletionMessage{
+		{Role: gopenrouter.RoleTool, ToolCallID: "call_1"},
+		{Role: gopenrouter.RoleUser, MultiContent: []gopenrouter.ChatCompletionMessagePart{{Type: "text"}, {Type: "text", Text: "next"}}},
+	})
+	if err != nil {
+		t.Fatalf("ChatMessagesToAnthropic: %v", err)
+	}
+	b, _ := json.Marshal(messages)
+	if want := `[{"role":"user","content":[{"type":"tool_result","tool_use_id":"call_1"},{"type":"text","text":"next"}]}]`; string(b) != want {
+		t.Fatalf("empty text must be omitted:\n got %s\nwant %s", b, want)
+	}
+
+	back, err := AnthropicMessagesToChat(nil, []anthropic.Message{anthropic.NewUserMessage(
+		anthropic.NewToolErrorBlock("call_1", "boom"),
+		anthropic.ContentBlock{Type: anthropic.BlockTypeToolResult, ToolUseID: "call_2", IsError: true},
+	)})
+	if err != nil {
+		t.Fatalf("AnthropicMessagesToChat: %v", err)
+	}
+	if len(back) != 2 || back[0].Content != "error: boom" || back[1].Content != "error:" || back[1].ToolCallID != "call_2" {
+		t.Fatalf("is_error not carried over: %+v", back)
+	}
+}
+
+func TestAnthropicToolErrorRoundTrip(t *testing.T) {
+	messages := []anthropic.Message{anthropic.NewUserMessage(
+		anthropic.NewToolErrorBlock("call_1", "boom"),
+		anthropic.ContentBlock{Type: anthropic.BlockTypeToolResult, ToolUseID: "call_2", IsError: true},
+		anthropic.NewToolResultBlock("call_3", anthropic.NewTextBlock("fine")),
+		anthropic.ContentBlock{Type: anthropic.BlockTypeToolResult, ToolUseID: "call_4", IsError: true, Content: anthropic.ToolResultContent{
+			anthropic.NewImageBlock(anthropic.URLSource("https://example.com/a.png")),
+		}},
+	)}
+	chat, err := AnthropicMessagesToChat(nil, messages)
+	if err != nil {
+		t.Fatalf("AnthropicMessagesToChat: %v", err)
+	}
+	_, back, err := ChatMessagesToAnthropic(chat)
+	if err != nil {
+		t.Fatalf("ChatMessagesToAnthropic: %v", err)
+	}
+	got, _ := json.Marshal(back)
+	want, _ := json.Marshal(messages)
+	if string(got) != string(want) {
+		t.Fatalf("tool errors did not round-trip:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestChatEmptyTurnsToAnthropic(t *testing.T) {
+	_, messages, err := ChatMessagesToAnthropic([]gopenrouter.ChatCompletionMessage{
+		{Role: gopenrouter.RoleUser, Content: "hi"},
+		{Role: gopenrouter.RoleAssistant, Content: ""},
+		{Role: gopenrouter.RoleUser, Content: "again"},
+		{Role: gopenrouter.RoleAssistant, Content: "hello"},
+		{Role: gopenrouter.RoleUser, Content: ""},
+	})
+	if err != nil {
+		t.Fatalf("ChatMessagesToAnthropic: %v", err)
+	}
+	b, _ := json.Marshal(messages)
+	if want := `[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"text","text":"again"}]},{"role":"assistant","content":"hello"}]`; string(b) != want {
+		t.Fatalf("empty turns must be dropped:\n got %s\nwant %s", b, want)
+	}
+}
+
+func TestChatResponsesRoundTrip(t *testing.T) {
+	messages := testConversation()
+	messages[2].Reasoning = ""
+	messages[2].ReasoningDetails = []gopenrouter.ReasoningDetail{
+		{Type: "reasoning.summary", Summary: "Look up weather", ID: "rs_1", Format: responsesReasoningFormat},
+		{Type: "reasoning.encrypted", Data: "opaque", ID: "rs_1", Format: responsesReasoningFormat},
+	}
+	req := gopenrouter.ChatCompletionRequest{
+		Model:      "openai/gpt-4o",
+		Messages:   messages,
+		ToolChoice: map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}},
+	}
+
+	converted, err := ChatToResponsesRequest(req)
+	if err != nil {
+		t.Fatalf("ChatToResponsesRequest: %v", err)
+	}
+	items := converted.Input.([]responses.InputItem)
+	if len(items) != 6 {
+		t.Fatalf("expected 6 input items, got %d: %+v", len(items), items)
+	}
+	if items[2].Type != "reasoning" || items[2].EncryptedContent != "opaque" || items[3].Type != "function_call" || items[4].Type != "function_call_output" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	b, err := json.Marshal(converted)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	var wire struct {
+		Input      any `json:"input"`
+		ToolChoice any `json:"tool_choice"`
+	}
+	if err := json.Unmarshal(b, &wire); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	converted.Input = wire.Input
+	converted.ToolChoice = wire.ToolChoice
+
+	back, err := ResponsesToChatRequest(converted)
+	if err != nil {
+		t.Fatalf("ResponsesToChatRequest: %v", err)
+	}
+	if !reflect.DeepEqual(back.Messages, messages) {
+		t.Fatalf("messages did not round-trip:\n got %+v\nwant %+v", back.Messages, messages)
+	}
+	if !reflect.DeepEqual(back.ToolChoice, req.ToolChoice) {
+		t.Fatalf("tool choice did not round-trip: %#v", back.ToolChoice)
+	}
+}
+
+func TestAnthropicResponseToChat(t *testing.T) {
+	var res anthropic.Response
+	payload := `{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[{"type":"thinking","thinking":"hmm","signature":"s1"},{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_1","name":"lookup","input":{"q":"x"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":90}}`
+	if err := json.Unmarshal([]byte(payload), &res); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	chat, err := AnthropicToChatResponse(&res)
+	if err != nil {
+		t.Fatalf("AnthropicToChatResponse: %v", err)
+	}
+	choice := chat.Choices[0]
+	if choice.FinishReason != "tool_calls" || choice.Message.Content != "Checking." || choice.Message.Reasoning != "hmm" {
+		t.Fatalf("unexpected choice: %+v", choice)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].Function.Arguments != `{"q":"x"}` {
+		t.Fatalf("unexpected tool calls: %+v", choice.Message.ToolCalls)
+	}
+	if chat.Usage.PromptTokens != 100 || chat.Usage.PromptTokensDetails.CachedTokens != 90 || chat.Usage.TotalTokens != 105 {
+		t.Fatalf("unexpected usage: %+v", chat.Usage)
+	}
+
+	back, err := ChatResponseToAnthropic(chat)
+	if err != nil {
+		t.Fatalf("ChatResponseToAnthropic: %v", err)
+	}
+	if back.StopReason != "tool_use" || len(back.Content) != 3 || back.Content[0].Signature != "s1" || string(back.Content[2].Input) != `{"q":"x"}` {
+		t.Fatalf("unexpected anthropic response: %+v", back)
+	}
+	if back.Usage.InputTokens != 10 || back.Usage.CacheReadInputTokens != 90 {
+		t.Fatalf("unexpected anthropic usage: %+v", back.Usage)
+	}
+
+	responsesRes, err := AnthropicToResponsesResponse(&res)
+	if err != nil {
+		t.Fatalf("AnthropicToResponsesResponse: %v", err)
+	}
+	chatAgain, err := ResponsesToChatResponse(responsesRes)
+	if err != nil {
+		t.Fatalf("ResponsesToChatResponse: %v", err)
+	}
+	if chatAgain.Choices[0].FinishReason != "tool_calls" || chatAgain.Choices[0].Message.Content != "Checking." {
+		t.Fatalf("unexpected responses round-trip: %+v", chatAgain.Choices[0])
+	}
+}
+
+func TestUnsupportedContent(t *testing.T) {
+	_, err := ChatToResponsesRequest(gopenrouter.ChatCompletionRequest{
+		Messages: []gopenrouter.ChatCompletionMessage{{
+			Role:         gopenrouter.RoleUser,
+			MultiContent: []gopenrouter.ChatCompletionMessagePart{{Type: "input_audio", InputAudio: &shared.InputAudio{Data: "x", Format: "wav"}}},
+		}},
+	})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,456 @@
+package convert
+
+import (
+	"encoding/json"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+const responsesReasoningFormat = "openai-responses-v1"
+
+// ChatToResponsesRequest converts a chat completion request into a Responses API request.
+func ChatToResponsesRequest(req gopenrouter.ChatCompletionRequest) (responses.Request, error) {
+	input, err := ChatMessagesToResponsesInput(req.Messages)
+	if err != nil {
+		return responses.Request{}, err
+	}
+	tools, err := chatToolsToResponses(req.Tools)
+	if err != nil {
+		return responses.Request{}, err
+	}
+	choice, err := parseChatToolChoice(req.ToolChoice)
+	if err != nil {
+		return responses.Request{}, err
+	}
+
+	out := responses.Request{
+		Input:             input,
+		Model:             req.Model,
+		Models:            req.Models,
+		Tools:             tools,
+		ToolChoice:        choice.responses(),
+		ParallelToolCalls: req.ParallelToolCalls,
+		MaxOutputTokens:   intPtr(maxTokens(req)),
+		Temperature:       floatPtr(req.Temperature),
+		TopP:              floatPtr(req.TopP),
+		TopLogProbs:       req.TopLogProbs,
+		TopK:              req.TopK,
+		PresencePenalty:   floatPtr(req.PresencePenalty),
+		FrequencyPenalty:  floatPtr(req.FrequencyPenalty),
+		Metadata:          req.Metadata,
+		Modalities:        req.Modalities,
+		ImageConfig:       req.ImageConfig,
+		Stream:            req.Stream,
+		Provider:          req.Provider,
+		Plugins:           req.Plugins,
+		Route:             req.Route,
+		User:              req.User,
+		SessionID:         req.SessionID,
+		Trace:             req.Trace,
+		ExtraBody:         req.ExtraBody,
+	}
+	if req.ResponseFormat != nil {
+		out.Text = &responses.TextConfig{Format: req.ResponseFormat}
+	}
+	if req.Reasoning != nil {
+		out.Reasoning = &responses.ReasoningConfig{
+			Effort:    string(req.Reasoning.Effort),
+			Summary:   string(req.Reasoning.Summary),
+			MaxTokens: intPtr(req.Reasoning.MaxTokens),
+		}
+	}
+	return out, nil
+}
+
+// ResponsesToChatRequest converts a Responses API request into a chat completion request.
+// Instructions become a leading system message.
+func ResponsesToChatRequest(req responses.Request) (gopenrouter.ChatCompletionRequest, error) {
+	messages, err := ResponsesInputToChatMessages(req.Input)
+	if err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+	if req.Instructions != "" {
+		messages = append([]gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleSystem, Content: req.Instructions}}, messages...)
+	}
+	tools, err := responsesToolsToChat(req.Tools)
+	if err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+	choice, err := parseChatToolChoice(req.ToolChoice)
+	if err != nil {
+		return gopenrouter.ChatCompletionRequest{}, err
+	}
+
+	out := gopenrouter.ChatCompletionRequest{
+		Model:               req.Model,
+		Models:              req.Models,
+		Messages:            messages,
+		Tools:               tools,
+		ToolChoice:          choice.chat(),
+		ParallelToolCalls:   req.ParallelToolCalls,
+		MaxCompletionTokens: req.MaxOutputTokens,
+		Temperature:         floatValue(req.Temperature),
+		TopP:                floatValue(req.TopP),
+		TopLogProbs:         req.TopLogProbs,
+		TopK:                req.TopK,
+		PresencePenalty:     floatValue(req.PresencePenalty),
+		FrequencyPenalty:    floatValue(req.FrequencyPenalty),
+		Metadata:            req.Metadata,
+		Modalities:          req.Modalities,
+		ImageConfig:         req.ImageConfig,
+		Stream:              req.Stream,
+		Provider:            req.Provider,
+		Plugins:             req.Plugins,
+		Route:               req.Route,
+		User:                req.User,
+		SessionID:           req.SessionID,
+		Trace:               req.Trace,
+		ExtraBody:           req.ExtraBody,
+	}
+	if req.Text != nil {
+		out.ResponseFormat = req.Text.Format
+	}
+	if req.Reasoning != nil {
+		out.Reasoning = &gopenrouter.ReasoningParams{
+			Effort:  gopenrouter.ReasoningEffort(req.Reasoning.Effort),
+			Summary: gopenrouter.ReasoningSummaryVerbosity(req.Reasoning.Summary),
+		}
+		if req.Reasoning.MaxTokens != nil {
+			out.Reasoning.MaxTokens = *req.Reasoning.MaxTokens
+		}
+	}
+	return out, nil
+}
+
+// ChatMessagesToResponsesInput converts chat messages into Responses API input items.
+func ChatMessagesToResponsesInput(messages []gopenrouter.ChatCompletionMessage) ([]responses.InputItem, error) {
+	items := make([]responses.InputItem, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case gopenrouter.RoleTool:
+			items = append(items, responses.NewFunctionCallOutput(msg.ToolCallID, messageText(msg)))
+		case gopenrouter.RoleAssistant:
+			items = append(items, reasoningDetailsToResponses(msg.ReasoningDetails)...)
+			if text := messageText(msg); text != "" || msg.Refusal != "" {
+				var content []responses.ContentPart
+				if text != "" {
+					content = append(content, responses.NewOutputText(text))
+				}
+				if msg.Refusal != "" {
+					content = append(content, responses.ContentPart{Type: responses.PartTypeRefusal, Refusal: msg.Refusal})
+				}
+				items = append(items, responses.NewMessage(gopenrouter.RoleAssistant, content...))
+			}
+			for _, call := range msg.ToolCalls {
+				items = append(items, responses.NewFunctionCall(call.ID, call.Function.Name, call.Function.Arguments))
+			}
+		default:
+			content, err := chatContentToResponses(msg)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, responses.InputItem{Type: responses.ItemTypeMessage, Role: string(msg.Role), Content: content})
+		}
+	}
+	return items, nil
+}
+
+func chatContentToResponses(msg gopenrouter.ChatCompletionMessage) (responses.MessageContent, error) {
+	if len(msg.MultiContent) == 0 {
+		return responses.MessageContent{Text: msg.Content}, nil
+	}
+	parts := make([]responses.ContentPart, 0, len(msg.MultiContent))
+	for _, part := range msg.MultiContent {
+		switch {
+		case part.Type == "text":
+			parts = append(parts, responses.NewInputText(part.Text))
+		case part.Type == "image_url" && part.ImageURL != nil:
+			parts = append(parts, responses.NewInputImage(part.ImageURL.URL, part.ImageURL.Detail))
+		case part.Type == "file" && part.File != nil:
+			parts = append(parts, responses.NewInputFile(part.File.Filename, part.File.FileData))
+		default:
+			return responses.MessageContent{}, unsupported("chat content part %q in the Responses API", part.Type)
+		}
+	}
+	return responses.MessageContent{Parts: parts}, nil
+}
+
+func reasoningDetailsToResponses(details []gopenrouter.ReasoningDetail) []responses.InputItem {
+	var (
+		items []responses.InputItem
+		byID  = map[string]int{}
+	)
+	itemFor := func(id string) *responses.InputItem {
+		if i, ok := byID[id]; ok && id != "" {
+			return &items[i]
+		}
+		items = append(items, responses.NewReasoning(id, ""))
+		if id != "" {
+			byID[id] = len(items) - 1
+		}
+		return &items[len(items)-1]
+	}
+	for _, detail := range details {
+		switch detail.Type {
+		case "reasoning.summary":
+			item := itemFor(detail.ID)
+			item.Summary = append(item.Summary, responses.NewSummaryText(detail.Summary))
+		case "reasoning.encrypted":
+			itemFor(detail.ID).EncryptedContent = detail.Data
+		case "reasoning.text":
+			item := itemFor(detail.ID)
+			item.Content.Parts = append(item.Content.Parts, responses.ContentPart{Type: responses.PartTypeReasoningText, Text: detail.Text})
+		}
+	}
+	return items
+}
+
+// ResponsesInputToChatMessages converts Responses API input (a string or a
+// list of input items) into chat messages. Consecutive assistant items such
+// as reasoning, output messages and function calls are merged into one
+// assistant message.
+func ResponsesInputToChatMessages(input any) ([]gopenrouter.ChatCompletionMessage, error) {
+	items, err := responses.Request{Input: input}.InputItems()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		messages  []gopenrouter.ChatCompletionMessage
+		assistant *gopenrouter.ChatCompletionMessage
+	)
+	flush := func() {
+		if assistant != nil {
+			messages = append(messages, *assistant)
+			assistant = nil
+		}
+	}
+	currentAssistant := func() *gopenrouter.ChatCompletionMessage {
+		if assistant == nil {
+			assistant = &gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleAssistant}
+		}
+		return assistant
+	}
+
+	for _, item := range items {
+		switch item.Type {
+		case responses.ItemTypeReasoning:
+			msg := currentAssistant()
+			msg.ReasoningDetails = append(msg.ReasoningDetails, responsesReasoningToDetails(item)...)
+		case responses.ItemTypeFunctionCall:
+			msg := currentAssistant()
+			msg.ToolCalls = append(msg.ToolCalls, gopenrouter.ToolCall{
+				ID:       item.CallID,
+				Type:     "function",
+				Function: gopenrouter.Function{Name: item.Name, Arguments: item.Arguments},
+			})
+		case responses.ItemTypeFunctionCallOutput:
+			flush()
+			messages = append(messages, gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleTool, ToolCallID: item.CallID, Content: item.Output})
+		case responses.ItemTypeMessage:
+			if item.Role == gopenrouter.RoleAssistant {
+				msg := currentAssistant()
+				msg.Content += item.Content.Text
+				for _, part := range item.Content.Parts {
+					msg.Content += part.Text
+					msg.Refusal += part.Refusal
+				}
+				continue
+			}
+			flush()
+			msg, err := responsesMessageToChat(item)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, msg)
+		default:
+			return nil, unsupported("Responses input item %q in chat completions", item.Type)
+		}
+	}
+	flush()
+	return messages, nil
+}
+
+func responsesReasoningToDetails(item responses.InputItem) []gopenrouter.ReasoningDetail {
+	var details []gopenrouter.ReasoningDetail
+	for _, part := range item.Summary {
+		details = append(details, gopenrouter.ReasoningDetail{Type: "reasoning.summary", Summary: part.Text, ID: item.ID, Format: responsesReasoningFormat})
+	}
+	for _, part := range item.Content.Parts {
+		details = append(details, gopenrouter.ReasoningDetail{Type: "reasoning.text", Text: part.Text, ID: item.ID, Format: responsesReasoningFormat})
+	}
+	if item.EncryptedContent != "" {
+		details = append(details, gopenrouter.ReasoningDetail{Type: "reasoning.encrypted", Data: item.EncryptedContent, ID: item.ID, Format: responsesReasoningFormat})
+	}
+	return details
+}
+
+func responsesMessageToChat(item responses.InputItem) (gopenrouter.ChatCompletionMessage, error) {
+	msg := gopenrouter.ChatCompletionMessage{Role: gopenrouter.ChatCompletionMessageRole(item.Role)}
+	if item.Content.Parts == nil {
+		msg.Content = item.Content.Text
+		return msg, nil
+	}
+	for _, part := range item.Content.Parts {
+		switch part.Type {
+		case responses.PartTypeInputText, responses.PartTypeOutputText, "text":
+			msg.MultiContent = append(msg.MultiContent, gopenrouter.ChatCompletionMessagePart{Type: "text", Text: part.Text})
+		case responses.PartTypeInputImage:
+			msg.MultiContent = append(msg.MultiContent, gopenrouter.ChatCompletionMessagePart{
+				Type:     "image_url",
+				ImageURL: &shared.ImageURL{URL: part.ImageURL, Detail: part.Detail},
+			})
+		case responses.PartTypeInputFile:
+			data := part.FileData
+			if data == "" {
+				data = part.FileURL
+			}
+			msg.MultiContent = append(msg.MultiContent, gopenrouter.ChatCompletionMessagePart{
+				Type: "file",
+				File: &shared.File{Filename: part.Filename, FileData: data},
+			})
+		default:
+			return msg, unsupported("Responses content part %q in chat completions", part.Type)
+		}
+	}
+	return msg, nil
+}
+
+func chatToolsToResponses(tools []gopenrouter.Tool) ([]responses.Tool, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	out := make([]responses.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Type != "" && tool.Type != "function" {
+			return nil, unsupported("chat tool type %q", tool.Type)
+		}
+		out = append(out, responses.Tool{
+			Type:        "function",
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	return out, nil
+}
+
+func responsesToolsToChat(tools []responses.Tool) ([]gopenrouter.Tool, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	out := make([]gopenrouter.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Type != "function" {
+			return nil, unsupported("Responses tool type %q in chat completions", tool.Type)
+		}
+		out = append(out, gopenrouter.Tool{
+			Type: "function",
+			Function: gopenrouter.Function{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	return out, nil
+}
+
+// ResponsesToChatResponse converts a Responses API response into a chat completion response
+// with a single choice.
+func ResponsesToChatResponse(res *responses.Response) (*gopenrouter.ChatCompletionResponse, error) {
+	if res == nil {
+		return nil, nil
+	}
+	items, err := res.FollowUpInput()
+	if err != nil {
+		return nil, err
+	}
+	messages, err := ResponsesInputToChatMessages(items)
+	if err != nil {
+		return nil, err
+	}
+	msg := gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleAssistant}
+	for _, m := range messages {
+		if m.Role == gopenrouter.RoleAssistant {
+			msg = m
+			break
+		}
+	}
+	for _, detail := range msg.ReasoningDetails {
+		if detail.Type == "reasoning.summary" {
+			msg.Reasoning += detail.Summary
+		}
+	}
+
+	finishReason := "stop"
+	if res.Status == "incomplete" {
+		finishReason = "length"
+		if reason, _ := res.IncompleteDetails["reason"].(string); reason == "content_filter" {
+			finishReason = "content_filter"
+		}
+	}
+	out := &gopenrouter.ChatCompletionResponse{
+		ID:      res.ID,
+		Object:  "chat.completion",
+		Created: res.CreatedAt,
+		Model:   res.Model,
+		Choices: []gopenrouter.Choice{{
+			Message:      msg,
+			FinishReason: finishReasonFromToolCalls(msg, finishReason),
+		}},
+	}
+	if res.Usage != nil {
+		out.Usage = ResponsesUsageToChat(*res.Usage)
+	}
+	return out, nil
+}
+
+// ChatResponseToResponses converts the first choice of a chat completion response
+// into a Responses API response.
+func ChatResponseToResponses(res *gopenrouter.ChatCompletionResponse) (*responses.Response, error) {
+	if res == nil {
+		return nil, nil
+	}
+	out := &responses.Response{
+		ID:        res.ID,
+		Object:    "response",
+		CreatedAt: res.Created,
+		Model:     res.Model,
+		Status:    "completed",
+	}
+	usage := ChatUsageToResponses(res.Usage)
+	out.Usage = &usage
+	if len(res.Choices) == 0 {
+		return out, nil
+	}
+	choice := res.Choices[0]
+	switch choice.FinishReason {
+	case "length":
+		out.Status = "incomplete"
+		out.IncompleteDetails = map[string]any{"reason": "max_output_tokens"}
+	case "content_filter":
+		out.Status = "incomplete"
+		out.IncompleteDetails = map[string]any{"reason": "content_filter"}
+	}
+
+	items, err := ChatMessagesToResponsesInput([]gopenrouter.ChatCompletionMessage{choice.Message})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		var output responses.OutputItem
+		b, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &output); err != nil {
+			return nil, err
+		}
+		output.Status = "completed"
+		out.Output = append(out.Output, output)
+	}
+	return out, nil
+}
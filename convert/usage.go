@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/responses"
+)
+
+// ChatUsageToResponses converts chat completion usage into Responses API usage.
+func ChatUsageToResponses(u gopenrouter.Usage) responses.Usage {
+	out := responses.Usage{
+		InputTokens:   u.PromptTokens,
+		OutputTokens:  u.CompletionTokens,
+		TotalTokens:   u.TotalTokens,
+		Cost:          u.Cost,
+		IsBYOK:        u.IsBYOK,
+		CostDetails:   u.CostDetails,
+		ServerToolUse: u.ServerToolUse,
+	}
+	if d := u.PromptTokensDetails; d != nil {
+		out.InputTokensDetails = &responses.InputTokensDetails{
+			CachedTokens:     d.CachedTokens,
+			CacheWriteTokens: d.CacheWriteTokens,
+			AudioTokens:      d.AudioTokens,
+			VideoTokens:      d.VideoTokens,
+		}
+	}
+	if d := u.CompletionTokensDetails; d != nil || u.ImageTokens > 0 {
+		out.OutputTokensDetails = &responses.OutputTokensDetails{ImageTokens: u.ImageTokens}
+		if d != nil {
+			out.OutputTokensDetails.ReasoningTokens = d.ReasoningTokens
+			out.OutputTokensDetails.AudioTokens = d.AudioTokens
+		}
+	}
+	return out
+}
+
+// ResponsesUsageToChat converts Responses API usage into chat completion usage.
+func ResponsesUsageToChat(u responses.Usage) gopenrouter.Usage {
+	out := gopenrouter.Usage{
+		PromptTokens:     u.InputTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      u.TotalTokens,
+		Cost:             u.Cost,
+		IsBYOK:           u.IsBYOK,
+		CostDetails:      u.CostDetails,
+		ServerToolUse:    u.ServerToolUse,
+	}
+	if d := u.InputTokensDetails; d != nil {
+		out.PromptTokensDetails = &gopenrouter.TokensDetails{
+			CachedTokens:     d.CachedTokens,
+			CacheWriteTokens: d.CacheWriteTokens,
+			AudioTokens:      d.AudioTokens,
+			VideoTokens:      d.VideoTokens,
+		}
+	}
+	if d := u.OutputTokensDetails; d != nil {
+		out.CompletionTokensDetails = &gopenrouter.TokensDetails{
+			ReasoningTokens: d.ReasoningTokens,
+			AudioTokens:     d.AudioTokens,
+		}
+		out.ImageTokens = d.ImageTokens
+	}
+	return out
+}
+
+// ChatUsageToAnthropic converts chat completion usage into Anthropic usage.
+// Anthropic reports input tokens excluding cache reads and writes.
+func ChatUsageToAnthropic(u gopenrouter.Usage) *anthropic.Usage {
+	out := &anthropic.Usage{
+		InputTokens:   u.PromptTokens,
+		OutputTokens:  u.CompletionTokens,
+		ServerToolUse: u.ServerToolUse,
+		Cost:          u.Cost,
+		IsBYOK:        u.IsBYOK,
+		CostDetails:   u.CostDetails,
+	}
+	if d := u.PromptTokensDetails; d != nil {
+		out.InputTokens -= d.CachedTokens + d.CacheWriteTokens
+		out.CacheReadInputTokens = d.CachedTokens
+		out.CacheCreationInputTokens = d.CacheWriteTokens
+	}
+	return out
+}
+
+// AnthropicUsageToChat converts Anthropic usage into chat completion usage.
+func AnthropicUsageToChat(u *anthropic.Usage) gopenrouter.Usage {
+	if u == nil {
+		return gopenrouter.Usage{}
+	}
+	out := gopenrouter.Usage{
+		PromptTokens:     u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens,
+		CompletionTokens: u.OutputTokens,
+		Cost:             u.Cost,
+		IsBYOK:           u.IsBYOK,
+		CostDetails:      u.CostDetails,
+		ServerToolUse:    u.ServerToolUse,
+	}
+	out.TotalTokens = out.PromptTokens + out.CompletionTokens
+	if u.CacheReadInputTokens > 0 || u.CacheCreationInputTokens > 0 {
+		out.PromptTokensDetails = &gopenrouter.TokensDetails{
+			CachedTokens:     u.CacheReadInputTokens,
+			CacheWriteTokens: u.CacheCreationInputTokens,
+		}
+	}
+	return out
+}
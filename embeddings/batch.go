@@ -0,0 +1,218 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// BatchConfig configures CreateBatch and CreateMultimodalBatch.
+type BatchConfig struct {
+	// MaxInputs bounds the inputs per request. Zero means 128.
+	MaxInputs int
+	// MaxTokens bounds the approximate tokens per request. Zero means
+	// 32000. An input larger than MaxTokens is sent on its own.
+	MaxTokens int
+	// Concurrency bounds the requests in flight. Zero means 4.
+	Concurrency int
+	// MaxAttempts bounds the attempts per request. Zero means 3.
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles after each
+	// attempt. Zero means 500ms.
+	RetryDelay time.Duration
+	// CountTokens estimates the tokens of a text. Nil assumes three
+	// characters per token, which overestimates for most tokenizers.
+	CountTokens func(string) int
+	// ImageTokens is the estimate for an image part. Zero means 1000.
+	ImageTokens int
+	// Retryable reports whether a failed request should be retried. Nil
+	// retries rate limits, timeouts, server errors and transport failures.
+	Retryable func(error) bool
+}
+
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.MaxInputs <= 0 {
+		c.MaxInputs = 128
+	}
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = 32000
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 4
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = 500 * time.Millisecond
+	}
+	if c.CountTokens == nil {
+		c.CountTokens = func(s string) int { return (utf8.RuneCountInString(s) + 2) / 3 }
+	}
+	if c.ImageTokens <= 0 {
+		c.ImageTokens = 1000
+	}
+	if c.Retryable == nil {
+		c.Retryable = shared.Retryable
+	}
+	return c
+}
+
+// CreateBatch embeds any number of texts with req as the template for every
+// request; req.Input is ignored. Texts are split into batches by count and
+// approximate tokens, the batches run concurrently, and the result holds one
+// Datum per text in the original order, with Index set to the text's
+// position. Usage is summed over the batches. The first batch that fails
+// after its retries cancels the others.
+func (c *Client) CreateBatch(ctx context.Context, req Request, texts []string, config BatchConfig) (*Response, error) {
+	config = config.withDefaults()
+	return createBatch(ctx, c.backend, req, texts, config, func(items []string) any { return items }, config.CountTokens)
+}
+
+// CreateMultimodalBatch is CreateBatch for multimodal inputs.
+func (c *Client) CreateMultimodalBatch(ctx context.Context, req Request, inputs []MultimodalInput, config BatchConfig) (*Response, error) {
+	config = config.withDefaults()
+	return createBatch(ctx, c.backend, req, inputs, config, func(items []MultimodalInput) any { return items }, func(input MultimodalInput) int {
+		n := 0
+		for _, part := range input.Content {
+			if part.ImageURL != nil {
+				n += config.ImageTokens
+			} else {
+				n += config.CountTokens(part.Text)
+			}
+		}
+		return n
+	})
+}
+
+// chunk is a half-open range of inputs sent in one request.
+type chunk struct{ start, end int }
+
+// split groups consecutive items into chunks within the count and token
+// limits.
+func split[T any](items []T, maxInputs, maxTokens int, count func(T) int) []chunk {
+	var chunks []chunk
+	start, tokens := 0, 0
+	for i, item := range items {
+		n := count(item)
+		if i > start && (i-start >= maxInputs || tokens+n > maxTokens) {
+			chunks = append(chunks, chunk{start, i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	if start < len(items) {
+		chunks = append(chunks, chunk{start, len(items)})
+	}
+	return chunks
+}
+
+func createBatch[T any](ctx context.Context, b backend, req Request, items []T, config BatchConfig, input func([]T) any, count func(T) int) (*Response, error) {
+	out := &Response{Object: "list", Data: make([]Datum, len(items)), Model: req.Model}
+	chunks := split(items, config.MaxInputs, config.MaxTokens, count)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		usage    *Usage
+		slots    = make(chan struct{}, config.Concurrency)
+	)
+	for _, ch := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r := req
+			r.Input = input(items[ch.start:ch.end])
+			res, err := createWithRetry(ctx, b, r, config)
+			if err == nil {
+				err = place(out.Data[ch.start:ch.end], res.Data, ch.start)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("embeddings: inputs %d-%d: %w", ch.start, ch.end-1, err)
+				}
+				cancel()
+				return
+			}
+			if res.Model != "" {
+				out.Model = res.Model
+			}
+			if res.Usage != nil {
+				if usage == nil {
+					usage = &Usage{}
+				}
+				usage.PromptTokens += res.Usage.PromptTokens
+				usage.TotalTokens += res.Usage.TotalTokens
+				usage.Cost += res.Usage.Cost
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out.Usage = usage
+	return out, nil
+}
+
+// place copies a batch's data into dst by Index. Providers that omit Index
+// report every datum as 0; their data is taken in order.
+func place(dst, data []Datum, offset int) error {
+	if len(data) != len(dst) {
+		return fmt.Errorf("got %d embeddings for %d inputs", len(data), len(dst))
+	}
+	seen := make([]bool, len(dst))
+	byIndex := true
+	for _, d := range data {
+		if d.Index < 0 || d.Index >= len(dst) || seen[d.Index] {
+			byIndex = false
+			break
+		}
+		seen[d.Index] = true
+	}
+	for i, d := range data {
+		if byIndex {
+			i = d.Index
+		}
+		d.Index = offset + i
+		dst[i] = d
+	}
+	return nil
+}
+
+func createWithRetry(ctx context.Context, b backend, req Request, config BatchConfig) (*Response, error) {
+	delay := config.RetryDelay
+	for attempt := 1; ; attempt++ {
+		res, err := b.CreateEmbeddings(ctx, req)
+		if err == nil || attempt >= config.MaxAttempts || ctx.Err() != nil || !config.Retryable(err) {
+			return res, err
+		}
+		wait := shared.Jitter(delay)
+		delay *= 2
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
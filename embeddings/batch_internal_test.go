@@ -0,0 +1,53 @@
+package embeddings
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	size := func(s string) int { return len(s) }
+	for _, tc := range []struct {
+		items     []string
+		maxInputs int
+		maxTokens int
+		want      string
+	}{
+		{nil, 2, 10, "[]"},
+		{[]string{"a", "b", "c", "d", "e"}, 2, 100, "[{0 2} {2 4} {4 5}]"},
+		{[]string{"aaaa", "bbbb", "cc", "d"}, 10, 6, "[{0 1} {1 3} {3 4}]"},
+		// An item over the token limit still gets a chunk of its own.
+		{[]string{"a", "bbbbbbbbbb", "c"}, 10, 5, "[{0 1} {1 2} {2 3}]"},
+	} {
+		if got := fmt.Sprint(split(tc.items, tc.maxInputs, tc.maxTokens, size)); got != tc.want {
+			t.Errorf("split(%q, %d, %d) = %s, want %s", tc.items, tc.maxInputs, tc.maxTokens, got, tc.want)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	datum := func(index int, v float64) Datum {
+		return Datum{Index: index, Embedding: Value{Vector: []float64{v}}}
+	}
+	dst := make([]Datum, 3)
+	if err := place(dst, []Datum{datum(2, 2), datum(0, 0), datum(1, 1)}, 10); err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range dst {
+		if d.Index != 10+i || d.Embedding.Vector[0] != float64(i) {
+			t.Fatalf("datum %d misplaced: %+v", i, d)
+		}
+	}
+
+	// Without usable indexes the data is taken in order.
+	if err := place(dst, []Datum{datum(0, 5), datum(0, 6), datum(0, 7)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if dst[0].Index != 0 || dst[2].Index != 2 || dst[2].Embedding.Vector[0] != 7 {
+		t.Fatalf("data not taken in order: %+v", dst)
+	}
+
+	if err := place(dst, []Datum{datum(0, 0)}, 0); err == nil {
+		t.Fatal("expected an error for a short batch")
+	}
+}
@@ -0,0 +1,143 @@
+package embeddings_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// embeddingServer embeds each text as [len(text)] and returns the data in
+// reverse order with their indexes. Texts containing "flaky" fail once with
+// 503; texts containing "fatal" fail with 400.
+func embeddingServer(t *testing.T, sizes *[]int, peak *atomic.Int32) *httptest.Server {
+	var (
+		mu       sync.Mutex
+		failed   = map[string]bool{}
+		inFlight atomic.Int32
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(2 * time.Millisecond)
+		var req struct {
+			Model string `json:"model"`
+			Input []any  `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		*sizes = append(*sizes, len(req.Input))
+		mu.Unlock()
+		var data []string
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			text := fmt.Sprint(req.Input[i])
+			mu.Lock()
+			flaky := strings.Contains(text, "flaky") && !failed[text]
+			failed[text] = true
+			mu.Unlock()
+			switch {
+			case flaky:
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"error":{"message":"overloaded","code":503}}`)
+				return
+			case strings.Contains(text, "fatal"):
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"message":"input too long","code":400}}`)
+				return
+			}
+			data = append(data, fmt.Sprintf(`{"object":"embedding","embedding":[%d],"index":%d}`, len(text), i))
+		}
+		fmt.Fprintf(w, `{"object":"list","model":"%s-v1","data":[%s],"usage":{"prompt_tokens":%d,"total_tokens":%d,"cost":0.25}}`, req.Model, strings.Join(data, ","), len(req.Input), len(req.Input))
+	}))
+}
+
+func TestEmbeddingsCreateBatch(t *testing.T) {
+	var (
+		sizes []int
+		peak  atomic.Int32
+	)
+	server := embeddingServer(t, &sizes, &peak)
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := embeddings.New(gopenrouter.NewClientWithConfig(cfg))
+
+	var texts []string
+	for i := range 23 {
+		texts = append(texts, strings.Repeat("x", i+1))
+	}
+	texts[7] = "flaky " + texts[7]
+	texts[20] = strings.Repeat("y", 60) // over MaxTokens alone
+	res, err := client.CreateBatch(context.Background(), embeddings.Request{Model: "test/embed", Input: "ignored"}, texts, embeddings.BatchConfig{
+		MaxInputs:   5,
+		MaxTokens:   40,
+		Concurrency: 2,
+		RetryDelay:  time.Millisecond,
+		CountTokens: func(s string) int { return len(s) },
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(res.Data) != len(texts) || res.Model != "test/embed-v1" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+	for i, d := range res.Data {
+		if d.Index != i || len(d.Embedding.Vector) != 1 || int(d.Embedding.Vector[0]) != len(texts[i]) {
+			t.Fatalf("datum %d out of order: %+v", i, d)
+		}
+	}
+	for _, n := range sizes {
+		if n > 5 {
+			t.Fatalf("batch exceeds MaxInputs: %v", sizes)
+		}
+	}
+	batches := len(sizes) - 1 // one retry
+	if res.Usage == nil || res.Usage.PromptTokens != 23 || res.Usage.Cost != 0.25*float64(batches) {
+		t.Fatalf("unexpected usage: %+v over %d batches", res.Usage, batches)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("concurrency exceeded: %d", peak.Load())
+	}
+
+	_, err = client.CreateBatch(context.Background(), embeddings.Request{Model: "test/embed"}, []string{"a", "b", "fatal", "c"}, embeddings.BatchConfig{MaxInputs: 2, RetryDelay: time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "inputs 2-3") || !strings.Contains(err.Error(), "input too long") {
+		t.Fatalf("expected the failing batch, got %v", err)
+	}
+}
+
+func TestEmbeddingsCreateMultimodalBatch(t *testing.T) {
+	var (
+		sizes []int
+		peak  atomic.Int32
+	)
+	server := embeddingServer(t, &sizes, &peak)
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := embeddings.New(gopenrouter.NewClientWithConfig(cfg))
+
+	image := embeddings.MultimodalInput{Content: []embeddings.InputPart{{Type: "image_url", ImageURL: &shared.ImageURL{URL: "https://example.com/a.png"}}}}
+	text := embeddings.MultimodalInput{Content: []embeddings.InputPart{{Type: "text", Text: "caption"}}}
+	res, err := client.CreateMultimodalBatch(context.Background(), embeddings.Request{Model: "test/clip"}, []embeddings.MultimodalInput{image, text, image, text}, embeddings.BatchConfig{MaxTokens: 1500})
+	if err != nil {
+		t.Fatalf("CreateMultimodalBatch: %v", err)
+	}
+	if len(res.Data) != 4 || res.Data[3].Index != 3 || len(sizes) != 2 {
+		t.Fatalf("expected two batches of an image and a text, got %v: %+v", sizes, res.Data)
+	}
+}
@@ -0,0 +1,337 @@
+package embeddings
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// CacheEntry is a cached embedding with its share of the usage of the
+// request that produced it, which a hit counts as saved.
+type CacheEntry struct {
+	Embedding Value   `json:"embedding"`
+	Tokens    int     `json:"tokens,omitempty"`
+	Cost      float64 `json:"cost,omitempty"`
+}
+
+// Cache stores embeddings by key. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool, error)
+	Set(key string, entry CacheEntry) error
+}
+
+// CacheStats counts the inputs served by a CachedClient.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	// SavedTokens and SavedCost sum the usage recorded with each hit.
+	SavedTokens int64
+	SavedCost   float64
+	// Errors counts cache reads and writes that failed; a failed read is
+	// treated as a miss.
+	Errors int64
+}
+
+// HitRate returns the fraction of inputs served from the cache.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// CachedClient embeds through a Cache: each input is looked up by model,
+// dimensions, encoding format, input type and content hash, and only the
+// misses are sent upstream.
+type CachedClient struct {
+	client *Client
+	cache  Cache
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// NewCachedClient returns a client that serves client's embeddings from
+// cache.
+func NewCachedClient(client *Client, cache Cache) *CachedClient {
+	return &CachedClient{client: client, cache: cache}
+}
+
+// Stats returns the counters since the client was created.
+func (c *CachedClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Create is Client.Create through the cache. The response holds one Datum
+// per input in order, with Index set to its position; Usage covers only
+// the inputs sent upstream and is nil when every input was a hit.
+// Repeated inputs in one request are sent once.
+func (c *CachedClient) Create(ctx context.Context, req Request) (*Response, error) {
+	items, single, err := splitInput(req.Input)
+	if err != nil {
+		return nil, err
+	}
+	out := &Response{Object: "list", Data: make([]Datum, len(items)), Model: req.Model}
+	keys := make([]string, len(items))
+	var (
+		stats   CacheStats
+		misses  []json.RawMessage
+		missKey []string
+		pending = make(map[string][]int)
+	)
+	for i, item := range items {
+		keys[i] = cacheKey(req, item)
+		if _, ok := pending[keys[i]]; ok {
+			stats.Hits++
+			pending[keys[i]] = append(pending[keys[i]], i)
+			continue
+		}
+		entry, ok, err := c.cache.Get(keys[i])
+		if err != nil {
+			stats.Errors++
+		}
+		if ok {
+			stats.Hits++
+			stats.SavedTokens += int64(entry.Tokens)
+			stats.SavedCost += entry.Cost
+			out.Data[i] = Datum{Object: "embedding", Embedding: entry.Embedding, Index: i}
+			continue
+		}
+		stats.Misses++
+		pending[keys[i]] = []int{i}
+		misses = append(misses, item)
+		missKey = append(missKey, keys[i])
+	}
+	defer c.record(&stats)
+	if len(misses) == 0 {
+		return out, nil
+	}
+
+	upstream := req
+	if single {
+		upstream.Input = misses[0]
+	} else {
+		upstream.Input = misses
+	}
+	res, err := c.client.Create(ctx, upstream)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]Datum, len(misses))
+	if err := place(data, res.Data, 0); err != nil {
+		return nil, fmt.Errorf("embeddings: %w", err)
+	}
+	if res.Model != "" {
+		out.Model = res.Model
+	}
+	out.Usage = res.Usage
+	shares := usageShares(res.Usage, misses)
+	for j, d := range data {
+		for _, i := range pending[missKey[j]] {
+			out.Data[i] = Datum{Object: d.Object, Embedding: d.Embedding, Index: i}
+		}
+		if err := c.cache.Set(missKey[j], CacheEntry{Embedding: d.Embedding, Tokens: shares[j].Tokens, Cost: shares[j].Cost}); err != nil {
+			stats.Errors++
+		}
+	}
+	return out, nil
+}
+
+func (c *CachedClient) record(s *CacheStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Hits += s.Hits
+	c.stats.Misses += s.Misses
+	c.stats.SavedTokens += s.SavedTokens
+	c.stats.SavedCost += s.SavedCost
+	c.stats.Errors += s.Errors
+}
+
+// splitInput returns the JSON of each input item. A string, multimodal
+// input or token array is a single item.
+func splitInput(input any) (items []json.RawMessage, single bool, err error) {
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil, false, fmt.Errorf("embeddings: encode input: %w", err)
+	}
+	if len(raw) == 0 || raw[0] != '[' {
+		return []json.RawMessage{raw}, true, nil
+	}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, false, fmt.Errorf("embeddings: decode input: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, false, errors.New("embeddings: empty input")
+	}
+	if c := items[0][0]; c == '-' || c >= '0' && c <= '9' {
+		return []json.RawMessage{raw}, true, nil
+	}
+	return items, false, nil
+}
+
+// cacheKey hashes the request fields that change an embedding together
+// with the hash of item.
+func cacheKey(req Request, item json.RawMessage) string {
+	content := sha256.Sum256(item)
+	h := sha256.New()
+	// Extra fields can change the embedding too; json sorts the map keys.
+	extra, _ := json.Marshal(req.ExtraBody)
+	for _, field := range []string{req.Model, strconv.Itoa(req.Dimensions), req.EncodingFormat, req.InputType, string(extra)} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	h.Write(content[:])
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// usageShares splits usage over items in proportion to their size.
+func usageShares(usage *Usage, items []json.RawMessage) []CacheEntry {
+	shares := make([]CacheEntry, len(items))
+	if usage == nil {
+		return shares
+	}
+	total := 0
+	for _, item := range items {
+		total += len(item)
+	}
+	tokens := usage.PromptTokens
+	for i, item := range items {
+		if i == len(items)-1 {
+			shares[i].Tokens = tokens
+		} else {
+			shares[i].Tokens = usage.PromptTokens * len(item) / total
+			tokens -= shares[i].Tokens
+		}
+		shares[i].Cost = usage.Cost * float64(len(item)) / float64(total)
+	}
+	return shares
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entry beyond its capacity.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache returns a cache of up to capacity entries. Zero or less
+// means unbounded.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (m *MemoryCache) Get(key string) (CacheEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return CacheEntry{}, false, nil
+	}
+	m.order.MoveToFront(e)
+	return e.Value.(*memoryItem).entry, true, nil
+}
+
+func (m *MemoryCache) Set(key string, entry CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryItem).entry = entry
+		m.order.MoveToFront(e)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(&memoryItem{key: key, entry: entry})
+	if m.capacity > 0 && m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DirCache is a Cache that keeps one JSON file per entry in a directory,
+// so it survives restarts and can be shared by processes. Writes are
+// atomic; entries are never evicted.
+type DirCache struct {
+	dir string
+}
+
+// NewDirCache returns a cache in dir, creating it if needed.
+func NewDirCache(dir string) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirCache{dir: dir}, nil
+}
+
+// path spreads entries over subdirectories named by the first byte of the
+// key.
+func (d *DirCache) path(key string) string {
+	if len(key) < 3 {
+		return filepath.Join(d.dir, key+".json")
+	}
+	return filepath.Join(d.dir, key[:2], key[2:]+".json")
+}
+
+func (d *DirCache) Get(key string) (CacheEntry, bool, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false, fmt.Errorf("embeddings: cache entry %s: %w", key, err)
+	}
+	return entry, true, nil
+}
+
+func (d *DirCache) Set(key string, entry CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,47 @@
+package embeddings
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitInput(t *testing.T) {
+	for _, tc := range []struct {
+		input  any
+		items  []string
+		single bool
+	}{
+		{"hello", []string{`"hello"`}, true},
+		{[]string{"a", "b"}, []string{`"a"`, `"b"`}, false},
+		{[]int{1, 2, 3}, []string{`[1,2,3]`}, true},
+		{[][]int{{1}, {2}}, []string{`[1]`, `[2]`}, false},
+	} {
+		items, single, err := splitInput(tc.input)
+		if err != nil {
+			t.Fatalf("splitInput(%v): %v", tc.input, err)
+		}
+		got := make([]string, len(items))
+		for i, item := range items {
+			got[i] = string(item)
+		}
+		if !slices.Equal(got, tc.items) || single != tc.single {
+			t.Errorf("splitInput(%v) = %q, %v; want %q, %v", tc.input, got, single, tc.items, tc.single)
+		}
+	}
+	if _, _, err := splitInput([]string{}); err == nil {
+		t.Fatal("expected an error for an empty input")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	item := []byte(`"hello"`)
+	key := cacheKey(Request{Model: "m"}, item)
+	if key != cacheKey(Request{Model: "m", Input: "ignored"}, item) {
+		t.Fatal("the input field must not change the key")
+	}
+	for _, req := range []Request{{Model: "other"}, {Model: "m", Dimensions: 8}, {Model: "m", EncodingFormat: EncodingBase64}, {Model: "m", InputType: "search_query"}, {Model: "m", ExtraBody: map[string]any{"truncate": "END"}}} {
+		if cacheKey(req, item) == key {
+			t.Errorf("%+v shares a key with the base request", req)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package embeddings_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+)
+
+// countingCache records the keys it is asked for.
+type countingCache struct {
+	*embeddings.MemoryCache
+	gets []string
+}
+
+func (c *countingCache) Get(key string) (embeddings.CacheEntry, bool, error) {
+	c.gets = append(c.gets, key)
+	return c.MemoryCache.Get(key)
+}
+
+// letterServer embeds each text as its letter counts, base64 encoded.
+func letterServer(t *testing.T, requests *[]embeddings.Request) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embeddings.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		*requests = append(*requests, req)
+		mu.Unlock()
+		var texts []string
+		switch input := req.Input.(type) {
+		case string:
+			texts = []string{input}
+		case []any:
+			for _, s := range input {
+				texts = append(texts, s.(string))
+			}
+		}
+		resp := embeddings.Response{Object: "list", Model: req.Model}
+		for i, text := range texts {
+			v := make([]float32, 26)
+			for _, c := range strings.ToLower(text) {
+				if c >= 'a' && c <= 'z' {
+					v[c-'a']++
+				}
+			}
+			resp.Data = append(resp.Data, embeddings.Datum{Object: "embedding", Index: i, Embedding: embeddings.Value{Base64: embeddings.EncodeBase64Float32(v)}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestEmbeddingsCachedClient(t *testing.T) {
+	var requests []embeddings.Request
+	server := letterServer(t, &requests)
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	cache := &countingCache{MemoryCache: embeddings.NewMemoryCache(0)}
+	client := embeddings.NewCachedClient(embeddings.New(gopenrouter.NewClientWithConfig(cfg)), cache)
+	ctx := context.Background()
+	req := embeddings.Request{Model: "test/embed", EncodingFormat: embeddings.EncodingBase64}
+
+	check := func(res *embeddings.Response, texts ...string) {
+		t.Helper()
+		if len(res.Data) != len(texts) {
+			t.Fatalf("got %d embeddings for %d texts", len(res.Data), len(texts))
+		}
+		for i, d := range res.Data {
+			v, err := d.Embedding.Float32()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Index != i || int(v[texts[i][0]-'a']) != len(texts[i]) {
+				t.Fatalf("datum %d does not embed %q: %+v %v", i, texts[i], d, v)
+			}
+		}
+	}
+
+	req.Input = []string{"aa", "bbb", "aa"}
+	res, err := client.Create(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, "aa", "bbb", "aa")
+	if len(requests) != 1 || len(requests[0].Input.([]any)) != 2 {
+		t.Fatalf("expected one request with the unique texts, got %+v", requests)
+	}
+
+	req.Input = []string{"cccc", "bbb", "aa"}
+	if res, err = client.Create(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	check(res, "cccc", "bbb", "aa")
+	if len(requests) != 2 || len(requests[1].Input.([]any)) != 1 || requests[1].Input.([]any)[0] != "cccc" {
+		t.Fatalf("expected only the miss upstream, got %+v", requests[1])
+	}
+
+	req.Input = "bbb"
+	if res, err = client.Create(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	check(res, "bbb")
+	if len(requests) != 2 || res.Usage != nil {
+		t.Fatalf("expected a local hit, got %d requests and usage %+v", len(requests), res.Usage)
+	}
+
+	// Another input type is another key.
+	req.InputType = "search_query"
+	if _, err = client.Create(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 || requests[2].Input != "bbb" {
+		t.Fatalf("expected a miss for a new input type, got %+v", requests)
+	}
+
+	stats := client.Stats()
+	if stats.Hits != 4 || stats.Misses != 4 || stats.Errors != 0 || stats.HitRate() != 0.5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(cache.gets) != 7 {
+		t.Fatalf("expected 7 lookups, got %d", len(cache.gets))
+	}
+}
+
+func TestEmbeddingsCacheSavedUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"object":"list","model":"test/embed","data":[{"embedding":[1]},{"embedding":[2],"index":1}],"usage":{"prompt_tokens":10,"total_tokens":10,"cost":0.01}}`)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	backend := embeddings.New(gopenrouter.NewClientWithConfig(cfg))
+	cache := embeddings.NewMemoryCache(0)
+	ctx := context.Background()
+
+	// Usage is split by input size: "aaaa" is 6 bytes of JSON, "bb" 4.
+	res, err := embeddings.NewCachedClient(backend, cache).Create(ctx, embeddings.Request{Model: "test/embed", Input: []string{"aaaa", "bb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Usage == nil || res.Usage.PromptTokens != 10 {
+		t.Fatalf("expected upstream usage, got %+v", res.Usage)
+	}
+
+	// A second client shares the cache and saves the recorded usage.
+	client := embeddings.NewCachedClient(backend, cache)
+	if res, err = client.Create(ctx, embeddings.Request{Model: "test/embed", Input: []string{"bb"}}); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data[0].Embedding.Vector[0] != 2 {
+		t.Fatalf("unexpected embedding: %+v", res.Data)
+	}
+	if s := client.Stats(); s.Hits != 1 || s.Misses != 0 || s.SavedTokens != 4 || math.Abs(s.SavedCost-0.004) > 1e-12 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestEmbeddingsMemoryCacheLRU(t *testing.T) {
+	cache := embeddings.NewMemoryCache(2)
+	entry := func(f float64) embeddings.CacheEntry {
+		return embeddings.CacheEntry{Embedding: embeddings.Value{Vector: []float64{f}}}
+	}
+	cache.Set("a", entry(1))
+	cache.Set("b", entry(2))
+	cache.Get("a")
+	cache.Set("c", entry(3))
+	if _, ok, _ := cache.Get("b"); ok {
+		t.Fatal("least recently used entry was not evicted")
+	}
+	if e, ok, _ := cache.Get("a"); !ok || e.Embedding.Vector[0] != 1 || cache.Len() != 2 {
+		t.Fatalf("unexpected cache state: %+v %v %d", e, ok, cache.Len())
+	}
+}
+
+func TestEmbeddingsDirCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := embeddings.NewDirCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "0123456789abcdef"
+	if _, ok, err := cache.Get(key); ok || err != nil {
+		t.Fatalf("expected a clean miss, got %v %v", ok, err)
+	}
+	want := embeddings.CacheEntry{Embedding: embeddings.Value{Base64: embeddings.EncodeBase64Float32([]float32{1, 2})}, Tokens: 3, Cost: 0.5}
+	if err := cache.Set(key, want); err != nil {
+		t.Fatal(err)
+	}
+	reopened, _ := embeddings.NewDirCache(dir)
+	got, ok, err := reopened.Get(key)
+	if !ok || err != nil {
+		t.Fatalf("entry not persisted: %v %v", ok, err)
+	}
+	a, _ := json.Marshal(want)
+	b, _ := json.Marshal(got)
+	if string(a) != string(b) {
+		t.Fatalf("got %s, want %s", b, a)
+	}
+}
@@ -1,9 +1,11 @@
 package embeddings
 
 import (
+	"bytes"
 	"encoding/json"
 
 	"github.com/iamwavecut/gopenrouter/catalog"
+	"github.com/iamwavecut/gopenrouter/internal/jsonx"
 	"github.com/iamwavecut/gopenrouter/shared"
 )
 
@@ -12,6 +14,7 @@ type (
 	ProviderPreferences = shared.ProviderPreferences
 	ImageURL            = shared.ImageURL
 	ModelsList          = catalog.ModelsList
+	NormalizedUsage     = shared.NormalizedUsage
 )
 
 type Request struct {
@@ -22,6 +25,22 @@ type Request struct {
 	User           string               `json:"user,omitempty"`
 	Provider       *ProviderPreferences `json:"provider,omitempty"`
 	InputType      string               `json:"input_type,omitempty"`
+	ExtraBody      map[string]any       `json:"-"`
+}
+
+type requestAlias Request
+
+func (r Request) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(requestAlias(r))
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	jsonx.MergeMaps(m, r.ExtraBody)
+	return json.Marshal(m)
 }
 
 type InputPart struct {
@@ -65,6 +84,11 @@ func (e *Value) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 	if data[0] == '"' {
+		// Base64 needs no unescaping unless an encoder escaped '/'.
+		if len(data) >= 2 && data[len(data)-1] == '"' && bytes.IndexByte(data, '\\') < 0 {
+			e.Base64 = string(data[1 : len(data)-1])
+			return nil
+		}
 		return json.Unmarshal(data, &e.Base64)
 	}
 	return json.Unmarshal(data, &e.Vector)
@@ -75,3 +99,16 @@ type Usage struct {
 	TotalTokens  int     `json:"total_tokens"`
 	Cost         float64 `json:"cost,omitempty"`
 }
+
+// Normalized returns the dialect-independent view of u.
+func (u Usage) Normalized() NormalizedUsage {
+	out := NormalizedUsage{
+		InputTokens: u.PromptTokens,
+		TotalTokens: u.TotalTokens,
+		Cost:        u.Cost,
+	}
+	if out.TotalTokens == 0 {
+		out.TotalTokens = out.InputTokens
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package embeddings
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Values of Request.EncodingFormat. Base64 responses carry little-endian
+// float32 vectors, which decode much faster than JSON number arrays.
+const (
+	EncodingFloat  = "float"
+	EncodingBase64 = "base64"
+)
+
+// decodeChunk is the number of base64 characters decoded at once into a
+// stack buffer; a multiple of 16 so every chunk ends on a float boundary.
+const decodeChunk = 1024
+
+var errNoVector = errors.New("embeddings: value has no vector")
+
+// Dims returns the number of dimensions of e without decoding it.
+func (e Value) Dims() int {
+	if e.Base64 != "" {
+		return base64.StdEncoding.DecodedLen(len(e.Base64)) / 4
+	}
+	return len(e.Vector)
+}
+
+// Float32 returns the vector as float32s, decoding Base64 directly.
+func (e Value) Float32() ([]float32, error) {
+	return e.AppendFloat32(nil)
+}
+
+// Float64 returns the vector as float64s, decoding Base64 directly.
+func (e Value) Float64() ([]float64, error) {
+	return e.AppendFloat64(nil)
+}
+
+// AppendFloat32 appends the vector to dst, so a buffer can be reused across
+// values.
+func (e Value) AppendFloat32(dst []float32) ([]float32, error) {
+	if e.Base64 != "" {
+		return AppendBase64Float32(dst, e.Base64)
+	}
+	if e.Vector == nil {
+		return dst, errNoVector
+	}
+	for _, f := range e.Vector {
+		dst = append(dst, float32(f))
+	}
+	return dst, nil
+}
+
+// AppendFloat64 appends the vector to dst.
+func (e Value) AppendFloat64(dst []float64) ([]float64, error) {
+	if e.Base64 == "" {
+		if e.Vector == nil {
+			return dst, errNoVector
+		}
+		return append(dst, e.Vector...), nil
+	}
+	start := len(dst)
+	dst = grow(dst, e.Dims())
+	n := 0
+	err := decodeBase64(e.Base64, func(f float32) {
+		dst[start+n] = float64(f)
+		n++
+	})
+	return dst[:start+n], err
+}
+
+// AppendBase64Float32 decodes a base64 payload of little-endian float32s and
+// appends them to dst. It allocates only when dst lacks capacity.
+func AppendBase64Float32(dst []float32, s string) ([]float32, error) {
+	start := len(dst)
+	dst = grow(dst, base64.StdEncoding.DecodedLen(len(s))/4)
+	n := 0
+	err := decodeBase64(s, func(f float32) {
+		dst[start+n] = f
+		n++
+	})
+	return dst[:start+n], err
+}
+
+// EncodeBase64Float32 encodes v as base64 little-endian float32s, the
+// format of base64 embedding responses.
+func EncodeBase64Float32(v []float32) string {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// grow extends dst by n elements, reallocating only when needed.
+func grow[T any](dst []T, n int) []T {
+	if cap(dst)-len(dst) < n {
+		next := make([]T, len(dst), len(dst)+n)
+		copy(next, dst)
+		dst = next
+	}
+	return dst[:len(dst)+n]
+}
+
+// decodeBase64 calls emit with each float in s, decoding through a stack
+// buffer.
+func decodeBase64(s string, emit func(float32)) error {
+	var buf [decodeChunk / 4 * 3]byte
+	var src [decodeChunk]byte
+	for len(s) > 0 {
+		k := copy(src[:], s)
+		s = s[k:]
+		n, err := base64.StdEncoding.Decode(buf[:], src[:k])
+		if err != nil {
+			return fmt.Errorf("embeddings: invalid base64 vector: %w", err)
+		}
+		if n%4 != 0 {
+			return fmt.Errorf("embeddings: base64 vector is not a whole number of float32s")
+		}
+		for i := 0; i < n; i += 4 {
+			emit(math.Float32frombits(binary.LittleEndian.Uint32(buf[i:])))
+		}
+	}
+	return nil
+}
+
+// Float32s decodes every embedding of r into one contiguous allocation and
+// returns a vector per datum, in Data order.
+func (r *Response) Float32s() ([][]float32, error) {
+	total := 0
+	for _, d := range r.Data {
+		total += d.Embedding.Dims()
+	}
+	backing := make([]float32, 0, total)
+	out := make([][]float32, len(r.Data))
+	for i, d := range r.Data {
+		start := len(backing)
+		var err error
+		if backing, err = d.Embedding.AppendFloat32(backing); err != nil {
+			return nil, fmt.Errorf("embeddings: datum %d: %w", i, err)
+		}
+		out[i] = backing[start:len(backing):len(backing)]
+	}
+	return out, nil
+}
+
+// Float64s is Float32s with float64 vectors.
+func (r *Response) Float64s() ([][]float64, error) {
+	total := 0
+	for _, d := range r.Data {
+		total += d.Embedding.Dims()
+	}
+	backing := make([]float64, 0, total)
+	out := make([][]float64, len(r.Data))
+	for i, d := range r.Data {
+		start := len(backing)
+		var err error
+		if backing, err = d.Embedding.AppendFloat64(backing); err != nil {
+			return nil, fmt.Errorf("embeddings: datum %d: %w", i, err)
+		}
+		out[i] = backing[start:len(backing):len(backing)]
+	}
+	return out, nil
+}
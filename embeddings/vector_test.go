@@ -0,0 +1,111 @@
+package embeddings_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+)
+
+func testVector(n int) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = float32(math.Sin(float64(i))) * 0.1
+	}
+	return v
+}
+
+func TestEmbeddingBase64Decoding(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 191, 192, 193, 1536, 3072} {
+		want := testVector(n)
+		value := embeddings.Value{Base64: embeddings.EncodeBase64Float32(want)}
+		if value.Dims() != n {
+			t.Fatalf("Dims = %d, want %d", value.Dims(), n)
+		}
+		got, err := value.Float32()
+		if err != nil || len(got) != n {
+			t.Fatalf("n=%d: %d floats, %v", n, len(got), err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("n=%d: float %d = %v, want %v", n, i, got[i], want[i])
+			}
+		}
+		wide, err := value.Float64()
+		if err != nil || len(wide) != n || (n > 0 && wide[n-1] != float64(want[n-1])) {
+			t.Fatalf("n=%d: Float64 mismatch (%v)", n, err)
+		}
+	}
+
+	if _, err := (embeddings.Value{Base64: "not base64!"}).Float32(); err == nil {
+		t.Fatal("expected an error for invalid base64")
+	}
+	if _, err := (embeddings.Value{Base64: "AAAAAAA="}).Float32(); err == nil {
+		t.Fatal("expected an error for a partial float")
+	}
+	if _, err := (embeddings.Value{}).Float32(); err == nil {
+		t.Fatal("expected an error for an empty value")
+	}
+	if got, err := (embeddings.Value{Vector: []float64{0.5, -1}}).Float32(); err != nil || got[1] != -1 {
+		t.Fatalf("float vector: %v, %v", got, err)
+	}
+}
+
+func TestEmbeddingBase64Allocations(t *testing.T) {
+	value := embeddings.Value{Base64: embeddings.EncodeBase64Float32(testVector(1536))}
+	buf := make([]float32, 0, 1536)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := value.AppendFloat32(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("AppendFloat32 allocated %v times", allocs)
+	}
+}
+
+func TestEmbeddingBase64Response(t *testing.T) {
+	vectors := [][]float32{testVector(4), testVector(4)}
+	vectors[1][0] = 42
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embeddings.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.EncodingFormat != embeddings.EncodingBase64 {
+			t.Errorf("unexpected encoding format %q", req.EncodingFormat)
+		}
+		// Some encoders escape '/' in strings.
+		second := strings.ReplaceAll(embeddings.EncodeBase64Float32(vectors[1]), "/", `\/`)
+		fmt.Fprintf(w, `{"object":"list","model":"m","data":[{"object":"embedding","embedding":%q,"index":0},{"object":"embedding","embedding":"%s","index":1}]}`,
+			embeddings.EncodeBase64Float32(vectors[0]), second)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+
+	res, err := embeddings.New(gopenrouter.NewClientWithConfig(cfg)).Create(context.Background(), embeddings.Request{Model: "m", Input: []string{"a", "b"}, EncodingFormat: embeddings.EncodingBase64})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	got, err := res.Float32s()
+	if err != nil || len(got) != 2 || got[1][0] != 42 || got[0][3] != vectors[0][3] {
+		t.Fatalf("unexpected vectors: %v, %v", got, err)
+	}
+	if cap(got[0]) != len(got[0]) {
+		t.Fatal("vectors must not share capacity")
+	}
+	wide, err := res.Float64s()
+	if err != nil || len(wide[1]) != 4 || wide[1][0] != 42 {
+		t.Fatalf("unexpected float64 vectors: %v, %v", wide, err)
+	}
+	out, _ := json.Marshal(res.Data[0].Embedding)
+	if string(out) != fmt.Sprintf("%q", embeddings.EncodeBase64Float32(vectors[0])) {
+		t.Fatalf("base64 should round-trip: %s", out)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+)
+
+func main() {
+	ctx := context.Background()
+	// Activity needs a provisioning (management) key.
+	client := management.New(gopenrouter.NewClient(os.Getenv("OPENROUTER_PROVISIONING_KEY")))
+
+	// Activity is available for the last 30 completed UTC days.
+	to := time.Now().UTC().AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, -29)
+	items, err := client.GetActivityRange(ctx, management.ActivityRangeParams{
+		From: from.Format(management.DateLayout),
+		To:   to.Format(management.DateLayout),
+	})
+	if err != nil {
+		fmt.Printf("fetch activity: %v\n", err)
+		return
+	}
+	fmt.Printf("fetched %d activity rows\n\n", len(items))
+
+	fmt.Println("Spend by model:")
+	if err := management.WriteActivityCSV(os.Stdout, management.AggregateActivity(items, management.GroupByModel)); err != nil {
+		fmt.Printf("write csv: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nDaily totals with day-over-day changes:")
+	if err := management.WriteActivityJSON(os.Stdout, management.AggregateActivity(items, management.GroupByDay)); err != nil {
+		fmt.Printf("write json: %v\n", err)
+	}
+}
@@ -16,11 +16,9 @@ func main() {
 	resp, err := api.Create(context.Background(), anthropicapi.Request{
 		Model:     "anthropic/claude-3.5-sonnet",
 		MaxTokens: 128,
+		System:    []anthropicapi.SystemBlock{anthropicapi.NewSystemBlock("Answer in a single sentence.")},
 		Messages: []anthropicapi.Message{
-			{
-				Role:    "user",
-				Content: "Explain in one sentence why provider routing matters.",
-			},
+			anthropicapi.NewUserMessage(anthropicapi.NewTextBlock("Explain why provider routing matters.")),
 		},
 	})
 	if err != nil {
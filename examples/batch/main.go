@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/batch"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	// Write a small input file; real runs usually have thousands of lines.
+	dir, err := os.MkdirTemp("", "batch")
+	if err != nil {
+		fmt.Printf("temp dir: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	in, out := filepath.Join(dir, "in.jsonl"), filepath.Join(dir, "out.jsonl")
+	var lines []string
+	for i, city := range []string{"Lisbon", "Oslo", "Kyoto", "Lima"} {
+		lines = append(lines, fmt.Sprintf(`{"custom_id":"city-%d","body":{"model":"openai/gpt-4o-mini","messages":[{"role":"user","content":"One sentence about %s."}]}}`, i, city))
+	}
+	lines = append(lines, `{"custom_id":"embed-1","url":"/embeddings","body":{"model":"openai/text-embedding-3-small","input":["Lisbon","Oslo"]}}`)
+	if err := os.WriteFile(in, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		fmt.Printf("write input: %v\n", err)
+		return
+	}
+
+	runner := batch.New(client, batch.Config{
+		Concurrency:      4,
+		ModelConcurrency: map[string]int{"openai/gpt-4o-mini": 2},
+		Budget:           0.10,
+		OnResult: func(res batch.Result) {
+			fmt.Printf("%s: attempts=%d latency=%dms error=%q\n", res.CustomID, res.Attempts, res.LatencyMS, res.Error)
+		},
+	})
+	summary, err := runner.RunFile(ctx, in, out)
+	if err != nil {
+		fmt.Printf("run: %v\n", err)
+	}
+	if summary != nil {
+		fmt.Println(summary)
+	}
+
+	// Running again resumes: every ID already succeeded, so nothing is sent.
+	summary, err = runner.RunFile(ctx, in, out)
+	if err == nil {
+		fmt.Printf("second run skipped %d records\n", summary.Skipped)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/conversation"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	model := "openai/gpt-4o-mini"
+
+	limits, err := conversation.LookupLimits(ctx, client, model)
+	if err != nil {
+		fmt.Printf("lookup limits: %v\n", err)
+		return
+	}
+	fmt.Printf("context: %d tokens, max completion: %d\n", limits.ContextSize, limits.MaxCompletionTokens)
+
+	// Older turns are summarized instead of dropped once the history no
+	// longer fits the context window.
+	summarize := conversation.Summarize{Summarizer: func(ctx context.Context, dropped []gopenrouter.ChatCompletionMessage) (gopenrouter.ChatCompletionMessage, error) {
+		req := gopenrouter.ChatCompletionRequest{
+			Model: model,
+			Messages: append(dropped, gopenrouter.ChatCompletionMessage{
+				Role:    gopenrouter.RoleUser,
+				Content: "Summarize the conversation so far in a few sentences.",
+			}),
+		}
+		res, err := client.CreateChatCompletion(ctx, req)
+		if err != nil {
+			return gopenrouter.ChatCompletionMessage{}, err
+		}
+		return gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleUser, Content: "Earlier conversation: " + res.Choices[0].Message.Content}, nil
+	}}
+
+	conv := conversation.New(client, conversation.Config{Model: model, Limits: limits, Policy: summarize})
+	conv.Append(gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleSystem, Content: "You are a concise assistant."})
+
+	for _, question := range []string{
+		"Name three prime numbers.",
+		"Which of them is the largest?",
+		"Square it.",
+	} {
+		res, err := conv.Send(ctx, gopenrouter.ChatCompletionRequest{
+			Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: question}},
+		})
+		if err != nil {
+			fmt.Printf("send: %v\n", err)
+			return
+		}
+		fmt.Printf("> %s\n%s\n", question, res.Choices[0].Message.Content)
+	}
+	fmt.Printf("history: %d messages, ~%d tokens\n", len(conv.Messages()), conv.Tokens())
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/conversation"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	// Each session is a JSONL file; conversation.NewSQLStore and
+	// conversation.NewMemoryStore are drop-in alternatives.
+	store, err := conversation.NewFileStore(filepath.Join(os.TempDir(), "gopenrouter-sessions"))
+	if err != nil {
+		fmt.Printf("open store: %v\n", err)
+		return
+	}
+	config := conversation.Config{
+		Model:     "openai/gpt-4o-mini",
+		Limits:    conversation.Limits{ContextSize: 128000},
+		Store:     store,
+		SessionID: "example-session",
+	}
+
+	// Running the example again continues the same session.
+	conv, err := conversation.Resume(ctx, client, config)
+	if err != nil {
+		fmt.Printf("resume: %v\n", err)
+		return
+	}
+	fmt.Printf("resumed with %d messages\n", len(conv.Messages()))
+
+	res, err := conv.Send(ctx, gopenrouter.ChatCompletionRequest{
+		Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "Tell me a one-line fact about the Moon."}},
+		Metadata: map[string]string{"source": "example"},
+	})
+	if err != nil {
+		fmt.Printf("send: %v\n", err)
+		return
+	}
+	fmt.Println(res.Choices[0].Message.Content)
+
+	transcript, err := store.Load(ctx, config.SessionID)
+	if err != nil {
+		fmt.Printf("load: %v\n", err)
+		return
+	}
+	for _, entry := range transcript.Entries {
+		if entry.GenerationID != "" {
+			fmt.Printf("%s via %s: %d prompt + %d completion tokens\n", entry.GenerationID, entry.Model, entry.Usage.PromptTokens, entry.Usage.CompletionTokens)
+		}
+	}
+
+	// Branch off before the latest question to explore a different reply.
+	branchID := fmt.Sprintf("%s-branch-%d", config.SessionID, transcript.Version())
+	branch, err := conv.Fork(ctx, branchID, transcript.Version()-2)
+	if err != nil {
+		fmt.Printf("fork: %v\n", err)
+		return
+	}
+	res, err = branch.Send(ctx, gopenrouter.ChatCompletionRequest{
+		Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "Tell me a one-line fact about Mars instead."}},
+	})
+	if err != nil {
+		fmt.Printf("send on branch: %v\n", err)
+		return
+	}
+	fmt.Printf("branch %s: %s\n", branchID, res.Choices[0].Message.Content)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+	embeddingsapi "github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/tokens"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	api := embeddingsapi.New(client)
+
+	// A corpus of any size: here, the paragraphs of a few documents.
+	var chunks []string
+	for doc := range 20 {
+		for para := range 25 {
+			chunks = append(chunks, fmt.Sprintf("Document %d, paragraph %d. %s", doc, para, strings.Repeat("Lorem ipsum dolor sit amet. ", 10)))
+		}
+	}
+
+	// Base64 vectors decode much faster than JSON number arrays.
+	resp, err := api.CreateBatch(context.Background(), embeddingsapi.Request{
+		Model:          "openai/text-embedding-3-small",
+		EncodingFormat: embeddingsapi.EncodingBase64,
+	}, chunks, embeddingsapi.BatchConfig{
+		MaxInputs:   100,
+		MaxTokens:   50000,
+		Concurrency: 4,
+		CountTokens: tokens.GPT.CountText,
+	})
+	if err != nil {
+		fmt.Printf("embeddings.CreateBatch error: %v\n", err)
+		return
+	}
+
+	vectors, err := resp.Float32s()
+	if err != nil {
+		fmt.Printf("decode error: %v\n", err)
+		return
+	}
+	fmt.Printf("Embeddings: %d for %d chunks\n", len(vectors), len(chunks))
+	fmt.Printf("First vector: %d dimensions, index %d\n", len(vectors[0]), resp.Data[0].Index)
+	if resp.Usage != nil {
+		fmt.Printf("Prompt tokens: %d, cost: $%.6f\n", resp.Usage.PromptTokens, resp.Usage.Cost)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iamwavecut/gopenrouter"
+	embeddingsapi "github.com/iamwavecut/gopenrouter/embeddings"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	// A directory cache survives restarts; NewMemoryCache(n) keeps the n
+	// most recently used embeddings in memory instead.
+	cache, err := embeddingsapi.NewDirCache(filepath.Join(os.TempDir(), "embeddings-cache"))
+	if err != nil {
+		fmt.Printf("embeddings.NewDirCache error: %v\n", err)
+		return
+	}
+	api := embeddingsapi.NewCachedClient(embeddingsapi.New(client), cache)
+
+	req := embeddingsapi.Request{
+		Model:          "openai/text-embedding-3-small",
+		EncodingFormat: embeddingsapi.EncodingBase64,
+	}
+	for _, input := range [][]string{
+		{"The quick brown fox.", "Jumps over the lazy dog."},
+		{"Jumps over the lazy dog.", "A new sentence."},
+	} {
+		req.Input = input
+		resp, err := api.Create(context.Background(), req)
+		if err != nil {
+			fmt.Printf("embeddings.Create error: %v\n", err)
+			return
+		}
+		sent := 0
+		if resp.Usage != nil {
+			sent = resp.Usage.PromptTokens
+		}
+		fmt.Printf("Embedded %d inputs, %d prompt tokens sent upstream\n", len(resp.Data), sent)
+	}
+
+	stats := api.Stats()
+	fmt.Printf("Hits: %d, misses: %d (%.0f%% hit rate)\n", stats.Hits, stats.Misses, 100*stats.HitRate())
+	fmt.Printf("Saved: %d tokens, $%.6f\n", stats.SavedTokens, stats.SavedCost)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/gateway"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	handler := gateway.NewHandler(client, gateway.Config{
+		Keys: map[string]gateway.Caller{
+			os.Getenv("GATEWAY_KEY"): {
+				Name:          "internal-service",
+				AllowedModels: []string{"openai/*", "anthropic/claude-3.5-sonnet"},
+			},
+		},
+		Defaults: gateway.Defaults{
+			Provider: &shared.ProviderPreferences{DataCollection: shared.DataCollectionDeny},
+		},
+	})
+
+	fmt.Println("OpenAI-compatible gateway listening on :8080")
+	if err := http.ListenAndServe(":8080", handler); err != nil {
+		fmt.Printf("gateway error: %v\n", err)
+	}
+}
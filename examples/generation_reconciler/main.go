@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/reconcile"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	// Stats arrive in batches, e.g. for one billing write per batch.
+	reconciler := reconcile.New(client, reconcile.Config{
+		Concurrency: 2,
+		BatchSize:   10,
+		OnBatch: func(batch []reconcile.Result) {
+			for _, res := range batch {
+				if res.Err != nil {
+					fmt.Printf("%s: %v\n", res.ID, res.Err)
+					continue
+				}
+				gen := res.Generation
+				fmt.Printf("%s: %s via %s, %d native prompt tokens, cost %.6f (cache discount %.6f), after %d polls in %s\n",
+					res.ID, gen.Model, gen.ProviderName, gen.NativePromptTokens, gen.TotalCost, gen.CacheDiscount, res.Attempts, res.Elapsed.Round(time.Millisecond))
+			}
+		},
+	})
+
+	for _, question := range []string{"Name a prime number.", "Name a noble gas."} {
+		res, err := client.CreateChatCompletion(ctx, gopenrouter.ChatCompletionRequest{
+			Model:    "google/gemini-2.0-flash-lite-001",
+			Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: question}},
+		})
+		if err != nil {
+			fmt.Printf("chat completion: %v\n", err)
+			continue
+		}
+		fmt.Printf("%s %s\n", question, res.Choices[0].Message.Content)
+		// Submitting never blocks the request path.
+		if _, err := reconciler.Submit(res.ID); err != nil {
+			fmt.Printf("submit: %v\n", err)
+		}
+	}
+
+	shutdown, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	if err := reconciler.Close(shutdown); err != nil {
+		fmt.Printf("close: %v\n", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/generator"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	model := os.Getenv("MODEL")
+	if model == "" {
+		model = "openai/gpt-4o"
+	}
+
+	// The same request can be served by any endpoint.
+	var gen generator.Generator = generator.NewChat(client)
+	if strings.HasPrefix(model, "anthropic/") {
+		gen = generator.NewAnthropic(client)
+	}
+
+	stream, err := gen.Stream(context.Background(), gopenrouter.ChatCompletionRequest{
+		Model: model,
+		Messages: []gopenrouter.ChatCompletionMessage{
+			{Role: gopenrouter.RoleUser, Content: "Write a haiku about routers."},
+		},
+	})
+	if err != nil {
+		fmt.Printf("Stream error: %v\n", err)
+		return
+	}
+	defer stream.Close()
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Printf("\nRecv error: %v\n", err)
+			return
+		}
+		switch event.Type {
+		case generator.EventText:
+			fmt.Print(event.Text)
+		case generator.EventUsage:
+			fmt.Printf("\n\nTokens: %d in, %d out\n", event.Usage.InputTokens, event.Usage.OutputTokens)
+		case generator.EventFinish:
+			fmt.Printf("Finish reason: %s\n", event.FinishReason)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// Credits and managed keys need a provisioning (management) key.
+	client := management.New(gopenrouter.NewClient(os.Getenv("OPENROUTER_PROVISIONING_KEY")))
+
+	monitor := client.NewMonitor(management.MonitorConfig{
+		Interval:    time.Minute,
+		Credits:     true,
+		ManagedKeys: true,
+		Thresholds: []management.Threshold{
+			management.BalanceBelow(25),
+			management.LimitRemainingBelow(5),
+			management.DailyUsageAbove(50),
+			management.ExpiresWithin(7 * 24 * time.Hour),
+		},
+		OnAlert: func(a management.Alert) {
+			state := "FIRING"
+			if a.Resolved {
+				state = "resolved"
+			}
+			fmt.Printf("[%s] %s %s %s: %.2f\n", state, a.Threshold.Name, a.Subject, a.Label, a.Value)
+		},
+		OnError: func(err error) { fmt.Printf("poll: %v\n", err) },
+	})
+
+	// A dashboard can read the latest state at any time.
+	http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		snap := monitor.Snapshot()
+		json.NewEncoder(w).Encode(map[string]any{
+			"at":      snap.At,
+			"balance": snap.Balance,
+			"keys":    len(snap.ManagedKeys),
+			"firing":  snap.Firing,
+		})
+	})
+	go http.ListenAndServe("127.0.0.1:8090", nil)
+
+	fmt.Println("monitoring; status at http://127.0.0.1:8090/status")
+	monitor.Run(ctx)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/mcp"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	// Any stdio MCP server works here; mcp.NewHTTPTransport connects to
+	// streamable-HTTP servers instead.
+	transport, err := mcp.NewStdioTransport(exec.Command("npx", "-y", "@modelcontextprotocol/server-everything"))
+	if err != nil {
+		fmt.Printf("start MCP server: %v\n", err)
+		return
+	}
+	server, err := mcp.Connect(ctx, transport, mcp.Implementation{})
+	if err != nil {
+		fmt.Printf("connect MCP server: %v\n", err)
+		return
+	}
+	defer server.Close()
+
+	bridge, err := mcp.NewBridge(ctx, server)
+	if err != nil {
+		fmt.Printf("list MCP tools: %v\n", err)
+		return
+	}
+
+	messages := []gopenrouter.ChatCompletionMessage{
+		{Role: gopenrouter.RoleUser, Content: "Use the add tool to add 17 and 25."},
+	}
+	for range 5 {
+		resp, err := client.CreateChatCompletion(ctx, gopenrouter.ChatCompletionRequest{
+			Model:    "openai/gpt-4o-mini",
+			Messages: messages,
+			Tools:    bridge.ChatTools(),
+		})
+		if err != nil {
+			fmt.Printf("chat completion error: %v\n", err)
+			return
+		}
+		msg := resp.Choices[0].Message
+		if len(msg.ToolCalls) == 0 {
+			fmt.Println(msg.Content)
+			return
+		}
+		messages = append(messages, msg)
+		results, unmatched := bridge.ToolMessages(ctx, msg.ToolCalls)
+		for _, call := range unmatched {
+			results = append(results, gopenrouter.ChatCompletionMessage{Role: gopenrouter.RoleTool, ToolCallID: call.ID, Content: "unknown tool"})
+		}
+		messages = append(messages, results...)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iamwavecut/gopenrouter"
+	oauthapi "github.com/iamwavecut/gopenrouter/oauth"
+)
+
+func main() {
+	client := gopenrouter.NewClient("")
+	api := oauthapi.New(client)
+
+	key, err := api.RunPKCEFlow(context.Background(), oauthapi.FlowConfig{
+		OpenURL: func(authorizationURL string) error {
+			fmt.Printf("Open this URL in your browser to authorize:\n%s\n", authorizationURL)
+			return nil
+		},
+	})
+	if err != nil {
+		fmt.Printf("oauth.RunPKCEFlow error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Received API key: %s...\n", key[:min(len(key), 12)])
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+)
+
+func main() {
+	ctx := context.Background()
+	// Key and guardrail management needs a provisioning (management) key.
+	client := management.New(gopenrouter.NewClient(os.Getenv("OPENROUTER_PROVISIONING_KEY")))
+
+	var total float64
+	for key, err := range client.AllAPIKeys(ctx, management.APIKeysListParams{IncludeDisabled: true}) {
+		if err != nil {
+			fmt.Printf("list keys: %v\n", err)
+			return
+		}
+		total += key.UsageMonthly
+		fmt.Printf("%-32s disabled=%-5v month $%.2f\n", key.Name, key.Disabled, key.UsageMonthly)
+	}
+	fmt.Printf("total this month: $%.2f\n\n", total)
+
+	for guardrail, err := range client.AllGuardrails(ctx) {
+		if err != nil {
+			fmt.Printf("list guardrails: %v\n", err)
+			return
+		}
+		keys := 0
+		for _, err := range client.AllGuardrailKeyAssignments(ctx, guardrail.ID) {
+			if err != nil {
+				fmt.Printf("list assignments: %v\n", err)
+				return
+			}
+			keys++
+		}
+		fmt.Printf("guardrail %s: %d keys\n", guardrail.Name, keys)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/catalog"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		fmt.Printf("list models: %v\n", err)
+		return
+	}
+	// Cheapest paid models by prompt price, compared exactly.
+	var paid []catalog.Model
+	for _, m := range models.Data {
+		if price, err := m.Pricing.Prompt.Decimal(); err == nil && price.Sign() > 0 {
+			paid = append(paid, m)
+		}
+	}
+	slices.SortFunc(paid, func(a, b catalog.Model) int {
+		return a.Pricing.Prompt.Cmp(b.Pricing.Prompt)
+	})
+
+	for _, m := range paid[:min(10, len(paid))] {
+		prompt, _ := m.Pricing.Prompt.Decimal()
+		completion, _ := m.Pricing.Completion.Decimal()
+		// A month of 40M prompt and 8M completion tokens.
+		cost, err := m.Pricing.Cost(40_000_000, 8_000_000)
+		if err != nil {
+			fmt.Printf("%s: %v\n", m.ID, err)
+			continue
+		}
+		fmt.Printf("%-48s $%s/M in  $%s/M out  month $%s\n", m.ID, prompt.PerMillion(), completion.PerMillion(), cost.StringFixed(2))
+	}
+}
@@ -15,17 +15,8 @@ func main() {
 
 	resp, err := api.Create(context.Background(), responsesapi.Request{
 		Model: "openai/gpt-4o-mini",
-		Input: []map[string]any{
-			{
-				"type": "message",
-				"role": "user",
-				"content": []map[string]any{
-					{
-						"type": "input_text",
-						"text": "Give me a one-line summary of OpenRouter.",
-					},
-				},
-			},
+		Input: []responsesapi.InputItem{
+			responsesapi.NewUserMessage(responsesapi.NewInputText("Give me a one-line summary of OpenRouter.")),
 		},
 	})
 	if err != nil {
@@ -35,7 +26,5 @@ func main() {
 
 	fmt.Printf("Response ID: %s\n", resp.ID)
 	fmt.Printf("Status: %s\n", resp.Status)
-	if len(resp.Output) > 0 && len(resp.Output[0].Content) > 0 {
-		fmt.Println(resp.Output[0].Content[0].Text)
-	}
+	fmt.Println(resp.OutputText())
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	responsesapi "github.com/iamwavecut/gopenrouter/responses"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	api := responsesapi.New(client)
+
+	stream, err := api.CreateStream(context.Background(), responsesapi.Request{
+		Model: "openai/gpt-4o-mini",
+		Input: "Give me a one-line summary of OpenRouter.",
+	})
+	if err != nil {
+		fmt.Printf("responses.CreateStream error: %v\n", err)
+		return
+	}
+	defer stream.Close()
+
+	acc := responsesapi.NewAccumulator()
+	for {
+		event, err := stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Printf("\nstream error: %v\n", err)
+			return
+		}
+		if err := acc.Add(event); err != nil {
+			fmt.Printf("\nwarning: %v\n", err)
+		}
+		if delta, ok := event.(responsesapi.OutputTextDeltaEvent); ok {
+			fmt.Print(delta.Delta)
+		}
+	}
+	fmt.Println()
+
+	if err := acc.Err(); err != nil {
+		fmt.Printf("response error: %v\n", err)
+	}
+	resp := acc.Response()
+	fmt.Printf("Response ID: %s, output items: %d\n", resp.ID, len(resp.Output))
+	if usage := acc.Usage(); usage != nil {
+		fmt.Printf("Tokens: %d in, %d out\n", usage.InputTokens, usage.OutputTokens)
+	}
+}
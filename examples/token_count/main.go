@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/catalog"
+	"github.com/iamwavecut/gopenrouter/tokens"
+)
+
+func main() {
+	ctx := context.Background()
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	model := "anthropic/claude-sonnet-4"
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		fmt.Printf("list models: %v\n", err)
+		return
+	}
+	var entry catalog.Model
+	for _, m := range models.Data {
+		if m.ID == model {
+			entry = m
+		}
+	}
+	counter := tokens.ForModel(entry)
+
+	req := gopenrouter.ChatCompletionRequest{
+		Model: model,
+		Messages: []gopenrouter.ChatCompletionMessage{
+			{Role: gopenrouter.RoleSystem, Content: "You are a concise assistant."},
+			{Role: gopenrouter.RoleUser, MultiContent: []gopenrouter.ChatCompletionMessagePart{
+				{Type: "text", Text: "Describe this picture in one sentence."},
+				{Type: "image_url", ImageURL: &gopenrouter.ImageURL{URL: "https://upload.wikimedia.org/wikipedia/commons/4/47/PNG_transparency_demonstration_1.png"}},
+			}},
+		},
+	}
+	estimate := counter.CountRequest(req)
+	fmt.Printf("%s tokenizer estimate: %d prompt tokens\n", counter.Family, estimate)
+
+	res, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		fmt.Printf("chat completion: %v\n", err)
+		return
+	}
+	fmt.Println(res.Choices[0].Message.Content)
+	fmt.Printf("actual: %d prompt tokens\n", res.Usage.PromptTokens)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/responses"
+)
+
+type weatherArgs struct {
+	City string `json:"city" jsonschema:"City name"`
+}
+
+type weatherReport struct {
+	City    string  `json:"city"`
+	TempC   float64 `json:"temp_c"`
+	Summary string  `json:"summary"`
+}
+
+func weather(ctx context.Context, args weatherArgs) (weatherReport, error) {
+	return weatherReport{City: args.City, TempC: 21, Summary: "sunny"}, nil
+}
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	ctx := context.Background()
+
+	weatherTool, err := gopenrouter.NewFunctionTool("get_weather", "Current weather for a city", weather)
+	if err != nil {
+		fmt.Printf("tool definition error: %v\n", err)
+		return
+	}
+	tools := gopenrouter.NewToolSet(weatherTool)
+
+	runner := responses.NewRunner(client, responses.RunnerConfig{
+		Tools:   tools.ResponsesHandlers(),
+		MaxCost: 0.05,
+	})
+	res, err := runner.Run(ctx, responses.Request{
+		Model: "openai/gpt-4o-mini",
+		Input: "What is the weather in Paris and in Rome?",
+		Tools: tools.ResponsesTools(),
+	})
+	if err != nil {
+		fmt.Printf("responses runner error: %v\n", err)
+		return
+	}
+	fmt.Printf("Responses (%d iterations, %d tool calls, $%.6f): %s\n", res.Iterations, res.ToolCalls, res.Usage.Cost, res.Response.OutputText())
+
+	claude := anthropic.NewRunner(client, anthropic.RunnerConfig{
+		Tools:         tools.AnthropicHandlers(),
+		MaxIterations: 5,
+		OnEvent: func(event anthropic.Event) {
+			if delta, ok := event.(anthropic.ContentBlockDeltaEvent); ok {
+				if text, ok := delta.Delta.(anthropic.TextDelta); ok {
+					fmt.Print(text.Text)
+				}
+			}
+		},
+	})
+	_, err = claude.Run(ctx, anthropic.Request{
+		Model:     "anthropic/claude-sonnet-4",
+		MaxTokens: 1024,
+		Stream:    true,
+		Tools:     tools.AnthropicTools(),
+		Messages:  []anthropic.Message{anthropic.NewUserMessage(anthropic.NewTextBlock("What is the weather in Oslo?"))},
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("anthropic runner error: %v\n", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iamwavecut/gopenrouter"
+	embeddingsapi "github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/vector"
+)
+
+func main() {
+	client := gopenrouter.NewClient(os.Getenv("OPENROUTER_API_KEY"))
+	api := embeddingsapi.New(client)
+	ctx := context.Background()
+
+	// Documents and queries are embedded with the same model.
+	collection, err := vector.NewCollection(api, embeddingsapi.Request{
+		Model: "openai/text-embedding-3-small",
+	}, vector.NewHNSW(0, vector.HNSWConfig{}), vector.CollectionConfig{StoreText: true})
+	if err != nil {
+		fmt.Printf("vector.NewCollection error: %v\n", err)
+		return
+	}
+
+	err = collection.Add(ctx,
+		vector.Document{ID: "go", Text: "Go is a statically typed, compiled language designed at Google.", Metadata: map[string]string{"topic": "languages"}},
+		vector.Document{ID: "rust", Text: "Rust guarantees memory safety without a garbage collector.", Metadata: map[string]string{"topic": "languages"}},
+		vector.Document{ID: "espresso", Text: "Espresso is brewed by forcing hot water through finely ground coffee.", Metadata: map[string]string{"topic": "coffee"}},
+		vector.Document{ID: "latte", Text: "A latte is espresso with steamed milk and a thin layer of foam.", Metadata: map[string]string{"topic": "coffee"}},
+	)
+	if err != nil {
+		fmt.Printf("collection.Add error: %v\n", err)
+		return
+	}
+
+	hits, err := collection.Query(ctx, "How is coffee made?", 2)
+	if err != nil {
+		fmt.Printf("collection.Query error: %v\n", err)
+		return
+	}
+	for _, hit := range hits {
+		fmt.Printf("%.3f %-8s [%s] %s\n", hit.Score, hit.ID, hit.Metadata["topic"], hit.Metadata[vector.TextKey])
+	}
+
+	// Save the index with its model; LoadCollection reuses that model.
+	path := filepath.Join(os.TempDir(), "vector_search.idx")
+	if err := collection.Save(path); err != nil {
+		fmt.Printf("collection.Save error: %v\n", err)
+		return
+	}
+	loaded, err := vector.LoadCollection(api, path, embeddingsapi.Request{}, vector.CollectionConfig{})
+	if err != nil {
+		fmt.Printf("vector.LoadCollection error: %v\n", err)
+		return
+	}
+	fmt.Printf("Reloaded %d documents embedded with %s\n", loaded.Index().Len(), loaded.Model())
+}
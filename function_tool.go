@@ -0,0 +1,305 @@
+package gopenrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/responses"
+)
+
+// FunctionTool is a tool backed by a typed Go function. One definition
+// produces the tool declaration for chat completions, /responses and
+// /messages, and executes the calls the model makes.
+type FunctionTool struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+	call        func(ctx context.Context, arguments json.RawMessage) (string, error)
+}
+
+// NewFunctionTool declares a tool whose parameters schema is generated from
+// Args, which must be a struct; pointer and omitempty fields are optional.
+// Arguments are validated against the schema and decoded into Args before fn
+// runs; a string Result is returned as is and any other Result is marshaled
+// as JSON.
+func NewFunctionTool[Args, Result any](name, description string, fn func(context.Context, Args) (Result, error)) (*FunctionTool, error) {
+	schema, err := parametersSchema(reflect.TypeFor[Args]())
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", name, err)
+	}
+	tool := &FunctionTool{Name: name, Description: description, Parameters: schema}
+	tool.call = func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		var args Args
+		if err := tool.decodeArguments(arguments, &args); err != nil {
+			return "", err
+		}
+		result, err := fn(ctx, args)
+		if err != nil {
+			return "", err
+		}
+		if s, ok := any(result).(string); ok {
+			return s, nil
+		}
+		b, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("tool %s: encode result: %w", name, err)
+		}
+		return string(b), nil
+	}
+	return tool, nil
+}
+
+// parametersSchema describes the tool arguments t. Unlike GenerateSchema,
+// whose output has to satisfy strict structured outputs, it describes
+// composite fields and leaves pointer and omitempty fields optional. A field
+// type that cannot be described, such as a channel or a recursive struct, is
+// an error.
+func parametersSchema(t reflect.Type) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, but got %s", t.Kind())
+	}
+	return structSchema(t, map[reflect.Type]bool{})
+}
+
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) (map[string]any, error) {
+	if seen[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "" || jsonTag == "-" {
+			continue
+		}
+		jsonTagParts := strings.Split(jsonTag, ",")
+		fieldName := jsonTagParts[0]
+
+		prop, err := typeSchema(field.Type, seen)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if desc := field.Tag.Get("jsonschema"); desc != "" {
+			prop["description"] = desc
+		}
+		properties[fieldName] = prop
+
+		// Fields are required unless they may be left out.
+		if field.Type.Kind() != reflect.Pointer && !hasOption(jsonTagParts[1:], "omitempty") {
+			required = append(required, fieldName)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+func hasOption(options []string, name string) bool {
+	for _, o := range options {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8:
+		// encoding/json encodes byte slices as base64 strings.
+		return map[string]any{"type": "string"}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := typeSchema(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return structSchema(t, seen)
+	case reflect.Interface:
+		// Any JSON value.
+		return map[string]any{}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// NewSchemaTool declares a tool from an existing parameters schema. call
+// receives the raw arguments without validation; this suits tools whose
+// implementation validates its own input, such as tools proxied to MCP servers.
+func NewSchemaTool(name, description string, parameters map[string]any, call func(context.Context, json.RawMessage) (string, error)) *FunctionTool {
+	return &FunctionTool{Name: name, Description: description, Parameters: parameters, call: call}
+}
+
+// decodeArguments checks the required and allowed properties of the
+// schema, then decodes arguments into args.
+func (t *FunctionTool) decodeArguments(arguments json.RawMessage, args any) error {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(arguments, &fields); err != nil {
+		return fmt.Errorf("tool %s: arguments must be a JSON object: %w", t.Name, err)
+	}
+	properties, _ := t.Parameters["properties"].(map[string]any)
+	for name := range fields {
+		if _, ok := properties[name]; !ok {
+			return fmt.Errorf("tool %s: unknown argument %q", t.Name, name)
+		}
+	}
+	required, _ := t.Parameters["required"].([]string)
+	for _, name := range required {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("tool %s: missing required argument %q", t.Name, name)
+		}
+	}
+	if err := json.Unmarshal(arguments, args); err != nil {
+		return fmt.Errorf("tool %s: invalid arguments: %w", t.Name, err)
+	}
+	return nil
+}
+
+// Call validates and decodes arguments, runs the function and returns its
+// encoded result. Its signature matches responses.ToolFunc and
+// anthropic.ToolFunc.
+func (t *FunctionTool) Call(ctx context.Context, arguments json.RawMessage) (string, error) {
+	return t.call(ctx, arguments)
+}
+
+func (t *FunctionTool) ChatTool() Tool {
+	return Tool{Type: "function", Function: Function{Name: t.Name, Description: t.Description, Parameters: t.Parameters}}
+}
+
+func (t *FunctionTool) ResponsesTool() responses.Tool {
+	return responses.Tool{Type: "function", Name: t.Name, Description: t.Description, Parameters: t.Parameters}
+}
+
+func (t *FunctionTool) AnthropicTool() anthropic.Tool {
+	return anthropic.Tool{Name: t.Name, Description: t.Description, InputSchema: t.Parameters}
+}
+
+// ToolSet is a library of function tools shared across endpoints.
+type ToolSet struct {
+	tools []*FunctionTool
+}
+
+func NewToolSet(tools ...*FunctionTool) *ToolSet {
+	return &ToolSet{tools: tools}
+}
+
+func (s *ToolSet) Add(tools ...*FunctionTool) {
+	s.tools = append(s.tools, tools...)
+}
+
+// Get returns the tool with the given name, or nil.
+func (s *ToolSet) Get(name string) *FunctionTool {
+	i := slices.IndexFunc(s.tools, func(t *FunctionTool) bool { return t.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return s.tools[i]
+}
+
+func (s *ToolSet) ChatTools() []Tool {
+	out := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		out = append(out, t.ChatTool())
+	}
+	return out
+}
+
+func (s *ToolSet) ResponsesTools() []responses.Tool {
+	out := make([]responses.Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		out = append(out, t.ResponsesTool())
+	}
+	return out
+}
+
+func (s *ToolSet) AnthropicTools() []anthropic.Tool {
+	out := make([]anthropic.Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		out = append(out, t.AnthropicTool())
+	}
+	return out
+}
+
+// ResponsesHandlers returns the handlers for responses.RunnerConfig.Tools.
+func (s *ToolSet) ResponsesHandlers() map[string]responses.ToolFunc {
+	out := make(map[string]responses.ToolFunc, len(s.tools))
+	for _, t := range s.tools {
+		out[t.Name] = t.Call
+	}
+	return out
+}
+
+// AnthropicHandlers returns the handlers for anthropic.RunnerConfig.Tools.
+func (s *ToolSet) AnthropicHandlers() map[string]anthropic.ToolFunc {
+	out := make(map[string]anthropic.ToolFunc, len(s.tools))
+	for _, t := range s.tools {
+		out[t.Name] = t.Call
+	}
+	return out
+}
+
+// Call runs the named tool.
+func (s *ToolSet) Call(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	tool := s.Get(name)
+	if tool == nil {
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	return tool.Call(ctx, arguments)
+}
+
+// ToolMessage runs a chat tool call and returns the tool message answering
+// it. Failures are reported to the model in the message content.
+func (s *ToolSet) ToolMessage(ctx context.Context, call ToolCall) ChatCompletionMessage {
+	content, err := s.Call(ctx, call.Function.Name, json.RawMessage(call.Function.Arguments))
+	if err != nil {
+		content = "error: " + err.Error()
+	}
+	return ChatCompletionMessage{Role: RoleTool, ToolCallID: call.ID, Content: content}
+}
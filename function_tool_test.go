@@ -0,0 +1,155 @@
+package gopenrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type weatherArgs struct {
+	City  string `json:"city" jsonschema:"City name"`
+	Units string `json:"units"`
+}
+
+type weatherResult struct {
+	TempC float64 `json:"temp_c"`
+}
+
+func newWeatherTool(t *testing.T) *FunctionTool {
+	t.Helper()
+	tool, err := NewFunctionTool("weather", "Current weather", func(ctx context.Context, args weatherArgs) (weatherResult, error) {
+		if args.City == "Nowhere" {
+			return weatherResult{}, context.DeadlineExceeded
+		}
+		return weatherResult{TempC: 21.5}, nil
+	})
+	if err != nil {
+		t.Fatalf("NewFunctionTool: %v", err)
+	}
+	return tool
+}
+
+func TestFunctionTool_Definitions(t *testing.T) {
+	tools := NewToolSet(newWeatherTool(t))
+
+	chat := tools.ChatTools()
+	if len(chat) != 1 || chat[0].Type != "function" || chat[0].Function.Name != "weather" {
+		t.Fatalf("unexpected chat tools: %+v", chat)
+	}
+	properties := chat[0].Function.Parameters.(map[string]any)["properties"].(map[string]any)
+	if properties["city"].(map[string]any)["description"] != "City name" {
+		t.Fatalf("unexpected schema: %v", chat[0].Function.Parameters)
+	}
+	if rt := tools.ResponsesTools(); rt[0].Type != "function" || rt[0].Name != "weather" || rt[0].Parameters == nil {
+		t.Fatalf("unexpected responses tools: %+v", rt)
+	}
+	at := tools.AnthropicTools()
+	b, err := json.Marshal(at[0])
+	if err != nil {
+		t.Fatalf("marshal anthropic tool: %v", err)
+	}
+	if !strings.Contains(string(b), `"input_schema":{`) || at[0].Type != "" {
+		t.Fatalf("unexpected anthropic tool: %s", b)
+	}
+
+	if _, err := NewFunctionTool("bad", "", func(ctx context.Context, args string) (string, error) { return args, nil }); err == nil {
+		t.Fatal("expected error for non-struct arguments")
+	}
+}
+
+func TestFunctionTool_Call(t *testing.T) {
+	tool := newWeatherTool(t)
+	ctx := context.Background()
+
+	out, err := tool.Call(ctx, json.RawMessage(`{"city":"Paris","units":"c"}`))
+	if err != nil || out != `{"temp_c":21.5}` {
+		t.Fatalf("unexpected result %q, %v", out, err)
+	}
+	for args, want := range map[string]string{
+		`{"city":"Paris"}`:                       `missing required argument "units"`,
+		`{"city":"Paris","units":"c","extra":1}`: `unknown argument "extra"`,
+		`{"city":1,"units":"c"}`:                 `invalid arguments`,
+		`[]`:                                     `must be a JSON object`,
+	} {
+		if _, err := tool.Call(ctx, json.RawMessage(args)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("args %s: expected %q, got %v", args, want, err)
+		}
+	}
+
+	tools := NewToolSet(tool)
+	msg := tools.ToolMessage(ctx, ToolCall{ID: "call_1", Function: Function{Name: "weather", Arguments: `{"city":"Nowhere","units":"c"}`}})
+	if msg.Role != RoleTool || msg.ToolCallID != "call_1" || !strings.HasPrefix(msg.Content, "error: ") {
+		t.Fatalf("unexpected tool message: %+v", msg)
+	}
+	msg = tools.ToolMessage(ctx, ToolCall{ID: "call_2", Function: Function{Name: "missing"}})
+	if !strings.Contains(msg.Content, `unknown tool "missing"`) {
+		t.Fatalf("unexpected tool message: %+v", msg)
+	}
+	if handlers := tools.ResponsesHandlers(); handlers["weather"] == nil || tools.AnthropicHandlers()["weather"] == nil {
+		t.Fatal("expected handlers for the weather tool")
+	}
+
+	text, err := NewFunctionTool("echo", "", func(ctx context.Context, args weatherArgs) (string, error) { return args.City, nil })
+	if err != nil {
+		t.Fatalf("NewFunctionTool: %v", err)
+	}
+	if out, err := text.Call(ctx, json.RawMessage(`{"city":"Rome","units":"c"}`)); err != nil || out != "Rome" {
+		t.Fatalf("string results must not be re-encoded, got %q, %v", out, err)
+	}
+}
+
+type searchArgs struct {
+	Query  string   `json:"query"`
+	Tags   []string `json:"tags"`
+	Limit  *int     `json:"limit"`
+	Sort   string   `json:"sort,omitempty"`
+	Filter struct {
+		Lang string `json:"lang"`
+	} `json:"filter,omitempty"`
+	Boost map[string]float64 `json:"boost,omitempty"`
+}
+
+func TestFunctionTool_CompositeArguments(t *testing.T) {
+	tool, err := NewFunctionTool("search", "", func(ctx context.Context, args searchArgs) (string, error) {
+		limit := -1
+		if args.Limit != nil {
+			limit = *args.Limit
+		}
+		return fmt.Sprintf("%s %v %d %s %v", args.Query, args.Tags, limit, args.Filter.Lang, args.Boost), nil
+	})
+	if err != nil {
+		t.Fatalf("NewFunctionTool: %v", err)
+	}
+	b, _ := json.Marshal(tool.Parameters)
+	for _, want := range []string{
+		`"tags":{"items":{"type":"string"},"type":"array"}`,
+		`"limit":{"type":"integer"}`,
+		`"filter":{"additionalProperties":false,"properties":{"lang":{"type":"string"}},"required":["lang"],"type":"object"}`,
+		`"boost":{"additionalProperties":{"type":"number"},"type":"object"}`,
+		`"required":["query","tags"]`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("schema %s lacks %s", b, want)
+		}
+	}
+
+	ctx := context.Background()
+	out, err := tool.Call(ctx, json.RawMessage(`{"query":"go","tags":["a","b"],"limit":3,"filter":{"lang":"en"},"boost":{"x":2}}`))
+	if err != nil || out != "go [a b] 3 en map[x:2]" {
+		t.Fatalf("unexpected result %q, %v", out, err)
+	}
+	if out, err = tool.Call(ctx, json.RawMessage(`{"query":"go","tags":[]}`)); err != nil || out != "go [] -1  map[]" {
+		t.Fatalf("optional fields must be optional, got %q, %v", out, err)
+	}
+
+	_, err = NewFunctionTool("bad", "", func(ctx context.Context, args struct {
+		C chan int `json:"c"`
+	}) (string, error) {
+		return "", nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Fatalf("expected an error for a channel field, got %v", err)
+	}
+}
@@ -0,0 +1,425 @@
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/catalog"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/internal/jsonx"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+type backend interface {
+	CreateChatCompletion(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionStream, error)
+	CreateEmbeddings(ctx context.Context, req embeddings.Request) (*embeddings.Response, error)
+	CreateResponse(ctx context.Context, req responses.Request) (*responses.Response, error)
+	CreateResponseStream(ctx context.Context, req responses.Request) (*responses.Stream, error)
+	ListModels(ctx context.Context) (*catalog.ModelsList, error)
+}
+
+// Handler is an OpenAI-compatible HTTP gateway that forwards requests through
+// an OpenRouter client. Callers authenticate with gateway-issued keys; the
+// upstream OpenRouter credentials stay inside the client.
+type Handler struct {
+	backend backend
+	config  Config
+	mux     *http.ServeMux
+}
+
+func NewHandler(backend backend, config Config) *Handler {
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	h := &Handler{backend: backend, config: config, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /v1/chat/completions", h.authenticated(h.handleChatCompletions))
+	h.mux.HandleFunc("POST /v1/embeddings", h.authenticated(h.handleEmbeddings))
+	h.mux.HandleFunc("POST /v1/responses", h.authenticated(h.handleResponses))
+	h.mux.HandleFunc("GET /v1/models", h.authenticated(h.handleModels))
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) authenticated(next func(http.ResponseWriter, *http.Request, Caller)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeErrorBody(w, http.StatusUnauthorized, "missing bearer token", "authentication_error", "invalid_api_key")
+			return
+		}
+		caller, ok := h.lookupCaller(token)
+		if !ok {
+			writeErrorBody(w, http.StatusUnauthorized, "invalid api key", "authentication_error", "invalid_api_key")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxBodyBytes)
+		next(w, r, caller)
+	}
+}
+
+func (h *Handler) lookupCaller(token string) (Caller, bool) {
+	var (
+		found  Caller
+		exists bool
+	)
+	for key, caller := range h.config.Keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			found, exists = caller, true
+		}
+	}
+	return found, exists
+}
+
+func (h *Handler) defaultsFor(caller Caller) Defaults {
+	if caller.Defaults != nil {
+		return *caller.Defaults
+	}
+	return h.config.Defaults
+}
+
+func (h *Handler) handleChatCompletions(w http.ResponseWriter, r *http.Request, caller Caller) {
+	var req gopenrouter.ChatCompletionRequest
+	extra, ok := decodeRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	req.ExtraBody = extra
+	if !checkModels(w, caller, req.Model, req.Models) {
+		return
+	}
+	h.defaultsFor(caller).apply(&req.Provider, &req.Plugins, &req.Trace, &req.SessionID)
+
+	if !req.Stream {
+		res, err := h.backend.CreateChatCompletion(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	stream, err := h.backend.CreateChatCompletionStream(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer stream.Close()
+
+	events := newEventWriter(w)
+	for {
+		chunk, err := stream.StreamReader.Recv()
+		if errors.Is(err, io.EOF) {
+			events.write("", []byte("[DONE]"))
+			return
+		}
+		if err != nil {
+			events.writeError(err)
+			return
+		}
+		events.write("", chunk)
+	}
+}
+
+func (h *Handler) handleResponses(w http.ResponseWriter, r *http.Request, caller Caller) {
+	var req responses.Request
+	extra, ok := decodeRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	req.ExtraBody = extra
+	if !checkModels(w, caller, req.Model, req.Models) {
+		return
+	}
+	h.defaultsFor(caller).apply(&req.Provider, &req.Plugins, &req.Trace, &req.SessionID)
+
+	if !req.Stream {
+		res, err := h.backend.CreateResponse(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	stream, err := h.backend.CreateResponseStream(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer stream.Close()
+
+	events := newEventWriter(w)
+	sequence := 0
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			events.writeResponsesError(err, sequence)
+			return
+		}
+		payload, err := json.Marshal(event.Raw)
+		if err != nil {
+			events.writeResponsesError(err, sequence)
+			return
+		}
+		events.write(event.Type, payload)
+		sequence = event.SequenceNumber + 1
+	}
+}
+
+func (h *Handler) handleEmbeddings(w http.ResponseWriter, r *http.Request, caller Caller) {
+	var req embeddings.Request
+	extra, ok := decodeRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	req.ExtraBody = extra
+	if !checkModels(w, caller, req.Model, nil) {
+		return
+	}
+	if req.Provider == nil {
+		req.Provider = h.defaultsFor(caller).Provider
+	}
+
+	res, err := h.backend.CreateEmbeddings(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) handleModels(w http.ResponseWriter, r *http.Request, caller Caller) {
+	models, err := h.backend.ListModels(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	list := ModelList{Object: "list", Data: []ModelObject{}}
+	for _, model := range models.Data {
+		if !caller.allows(model.ID) {
+			continue
+		}
+		owner, _, _ := strings.Cut(model.ID, "/")
+		list.Data = append(list.Data, ModelObject{
+			ID:      model.ID,
+			Object:  "model",
+			Created: model.Created,
+			OwnedBy: owner,
+		})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (d Defaults) apply(provider **ProviderPreferences, plugins *[]Plugin, trace **TraceMetadata, sessionID *string) {
+	if *provider == nil && d.Provider != nil {
+		cloned := *d.Provider
+		*provider = &cloned
+	}
+	for _, plugin := range d.Plugins {
+		if !slices.ContainsFunc(*plugins, func(p Plugin) bool { return p.ID == plugin.ID }) {
+			*plugins = append(*plugins, plugin)
+		}
+	}
+	if *trace == nil && d.Trace != nil {
+		cloned := *d.Trace
+		*trace = &cloned
+	}
+	if *sessionID == "" {
+		*sessionID = d.SessionID
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, out any) (map[string]any, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeErrorBody(w, http.StatusRequestEntityTooLarge, "request body too large", "invalid_request_error", nil)
+			return nil, false
+		}
+		writeErrorBody(w, http.StatusBadRequest, "failed to read request body", "invalid_request_error", nil)
+		return nil, false
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		writeErrorBody(w, http.StatusBadRequest, "invalid request body: "+err.Error(), "invalid_request_error", nil)
+		return nil, false
+	}
+	extra, err := jsonx.UnknownFields(body, out)
+	if err != nil {
+		writeErrorBody(w, http.StatusBadRequest, "invalid request body: "+err.Error(), "invalid_request_error", nil)
+		return nil, false
+	}
+	return extra, true
+}
+
+func checkModels(w http.ResponseWriter, caller Caller, model string, fallbacks []string) bool {
+	if len(caller.AllowedModels) > 0 && model == "" && len(fallbacks) == 0 {
+		writeErrorBody(w, http.StatusBadRequest, "model is required", "invalid_request_error", "model_required")
+		return false
+	}
+	for _, m := range append([]string{model}, fallbacks...) {
+		if m != "" && !caller.allows(m) {
+			writeErrorBody(w, http.StatusForbidden, fmt.Sprintf("model %q is not allowed for this key", m), "permission_error", "model_not_allowed")
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeErrorBody(w http.ResponseWriter, status int, message, errType string, code any) {
+	writeJSON(w, status, ErrorBody{Error: ErrorDetail{Message: message, Type: errType, Code: code}})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, body := translateError(err)
+	writeJSON(w, status, body)
+}
+
+// translateError maps client errors to an HTTP status and an OpenAI-shaped body.
+func translateError(err error) (int, ErrorBody) {
+	var (
+		apiErr *shared.APIError
+		reqErr *shared.RequestError
+		urlErr *url.Error
+	)
+	status := http.StatusBadGateway
+	message := err.Error()
+	var code any
+	switch {
+	case errors.As(err, &apiErr):
+		message = apiErr.Message
+		code = apiErr.Code
+		if s, ok := statusFromCode(apiErr.Code); ok {
+			status = s
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	case errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0:
+		status = reqErr.HTTPStatusCode
+	case errors.As(err, &urlErr), errors.Is(err, context.Canceled):
+		status = http.StatusBadGateway
+	case errors.As(err, &reqErr):
+		status = http.StatusBadRequest
+	}
+	return status, ErrorBody{Error: ErrorDetail{Message: message, Type: errorType(status), Code: code}}
+}
+
+func statusFromCode(code any) (int, bool) {
+	var status int
+	switch v := code.(type) {
+	case float64:
+		status = int(v)
+	case int:
+		status = v
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		status = int(n)
+	default:
+		return 0, false
+	}
+	return status, status >= 400 && status <= 599
+}
+
+func errorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return "authentication_error"
+	case status == http.StatusPaymentRequired:
+		return "insufficient_quota"
+	case status == http.StatusForbidden:
+		return "permission_error"
+	case status == http.StatusNotFound:
+		return "not_found_error"
+	case status == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case status >= 500:
+		return "server_error"
+	default:
+		return "invalid_request_error"
+	}
+}
+
+type eventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newEventWriter(w http.ResponseWriter) *eventWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	return &eventWriter{w: w, flusher: flusher}
+}
+
+func (e *eventWriter) write(event string, data []byte) {
+	if event != "" {
+		fmt.Fprintf(e.w, "event: %s\n", event)
+	}
+	fmt.Fprintf(e.w, "data: %s\n\n", data)
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+}
+
+// writeError ends a chat completion stream with an OpenAI-shaped error body.
+func (e *eventWriter) writeError(err error) {
+	_, body := translateError(err)
+	payload, _ := json.Marshal(body)
+	e.write("", payload)
+}
+
+// writeResponsesError ends a Responses stream with an error event, numbered
+// after the last event sent.
+func (e *eventWriter) writeResponsesError(err error, sequence int) {
+	_, body := translateError(err)
+	code := body.Error.Code
+	if code == nil {
+		code = body.Error.Type
+	}
+	payload, _ := json.Marshal(responsesErrorEvent{
+		Type:           "error",
+		Code:           code,
+		Message:        body.Error.Message,
+		SequenceNumber: sequence,
+	})
+	e.write("error", payload)
+}
+
+// responsesErrorEvent is the payload of an error event on a Responses stream.
+type responsesErrorEvent struct {
+	Type           string `json:"type"`
+	Code           any    `json:"code"`
+	Message        string `json:"message"`
+	Param          any    `json:"param"`
+	SequenceNumber int    `json:"sequence_number"`
+}
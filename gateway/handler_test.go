@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func newTestGateway(t *testing.T, upstream http.HandlerFunc, cfg Config) *httptest.Server {
+	t.Helper()
+	upstreamServer := httptest.NewServer(upstream)
+	t.Cleanup(upstreamServer.Close)
+
+	clientCfg := gopenrouter.DefaultConfig("sk-or-upstream")
+	clientCfg.BaseURL = upstreamServer.URL
+	gw := httptest.NewServer(NewHandler(gopenrouter.NewClientWithConfig(clientCfg), cfg))
+	t.Cleanup(gw.Close)
+	return gw
+}
+
+func doGateway(t *testing.T, gw *httptest.Server, method, path, key, body string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, gw.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, data
+}
+
+func TestHandler_Authentication(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("upstream must not be called, got %s", r.URL.Path)
+	}, Config{Keys: map[string]Caller{"gw-key": {Name: "svc"}}})
+
+	resp, body := doGateway(t, gw, http.MethodGet, "/v1/models", "wrong", "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	var errBody ErrorBody
+	if err := json.Unmarshal(body, &errBody); err != nil || errBody.Error.Type != "authentication_error" {
+		t.Fatalf("unexpected error body: %s err=%v", body, err)
+	}
+}
+
+func TestHandler_ChatCompletionInjectsDefaults(t *testing.T) {
+	allowFallbacks := false
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected upstream path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-or-upstream" {
+			t.Errorf("unexpected upstream authorization %q", got)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode upstream body: %v", err)
+		}
+		provider, _ := body["provider"].(map[string]any)
+		if provider["allow_fallbacks"] != false {
+			t.Errorf("expected provider defaults, got %v", body["provider"])
+		}
+		if body["session_id"] != "gateway-session" {
+			t.Errorf("expected session id default, got %v", body["session_id"])
+		}
+		if plugins, _ := body["plugins"].([]any); len(plugins) != 1 {
+			t.Errorf("expected one default plugin, got %v", body["plugins"])
+		}
+		if body["custom_param"] != "kept" {
+			t.Errorf("expected unknown fields to be forwarded, got %v", body["custom_param"])
+		}
+		if _, ok := body["Temperature"]; ok || body["temperature"] != 0.5 {
+			t.Errorf("expected a known field in another case to be decoded, not forwarded, got %v", body)
+		}
+		fmt.Fprint(w, `{"id":"gen-1","object":"chat.completion","model":"openai/gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`)
+	}, Config{
+		Keys: map[string]Caller{"gw-key": {Name: "svc", AllowedModels: []string{"openai/*"}}},
+		Defaults: Defaults{
+			Provider:  &shared.ProviderPreferences{AllowFallbacks: &allowFallbacks},
+			Plugins:   []shared.Plugin{{ID: shared.PluginIDResponseHealing}},
+			SessionID: "gateway-session",
+		},
+	})
+
+	resp, body := doGateway(t, gw, http.MethodPost, "/v1/chat/completions", "gw-key", `{"model":"openai/gpt-4o","messages":[{"role":"user","content":"hi"}],"Temperature":0.5,"custom_param":"kept"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+	var res gopenrouter.ChatCompletionResponse
+	if err := json.Unmarshal(body, &res); err != nil || res.Choices[0].Message.Content != "hi" {
+		t.Fatalf("unexpected response: %s err=%v", body, err)
+	}
+
+	resp, body = doGateway(t, gw, http.MethodPost, "/v1/chat/completions", "gw-key", `{"model":"anthropic/claude-3.5-sonnet","messages":[{"role":"user","content":"hi"}]}`)
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "permission_error") {
+		t.Fatalf("expected 403 permission_error, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestHandler_ChatCompletionStream(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"id\":\"gen-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		io.WriteString(w, ": OPENROUTER PROCESSING\n\n")
+		io.WriteString(w, "data: {\"id\":\"gen-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}, Config{Keys: map[string]Caller{"gw-key": {}}})
+
+	resp, body := doGateway(t, gw, http.MethodPost, "/v1/chat/completions", "gw-key", `{"model":"openai/gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected stream response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	want := "data: {\"id\":\"gen-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+		"data: {\"id\":\"gen-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n" +
+		"data: [DONE]\n\n"
+	if string(body) != want {
+		t.Fatalf("unexpected stream body:\n%s", body)
+	}
+}
+
+func TestHandler_ResponsesStreamError(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"type\":\"response.created\",\"sequence_number\":0,\"response\":{\"id\":\"resp_1\",\"status\":\"in_progress\"}}\n\n")
+		io.WriteString(w, "data: {\"type\":\"error\",\"sequence_number\":1,\"error\":{\"code\":\"server_error\",\"message\":\"boom\"}}\n\n")
+	}, Config{Keys: map[string]Caller{"gw-key": {}}})
+
+	resp, body := doGateway(t, gw, http.MethodPost, "/v1/responses", "gw-key", `{"model":"openai/gpt-4o","stream":true,"input":"hi"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	if len(events) != 2 || !strings.HasPrefix(events[0], "event: response.created\n") {
+		t.Fatalf("unexpected stream body:\n%s", body)
+	}
+	want := `event: error` + "\n" + `data: {"type":"error","code":"server_error","message":"boom","param":null,"sequence_number":1}`
+	if events[1] != want {
+		t.Fatalf("unexpected error event:\n got %s\nwant %s", events[1], want)
+	}
+}
+
+func TestHandler_EmbeddingsForwardsUnknownFields(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode upstream body: %v", err)
+		}
+		if body["truncate"] != "END" || body["input"] != "hi" {
+			t.Errorf("expected unknown fields to be forwarded, got %v", body)
+		}
+		fmt.Fprint(w, `{"object":"list","data":[{"object":"embedding","embedding":[0.5],"index":0}],"model":"openai/text-embedding-3-small"}`)
+	}, Config{Keys: map[string]Caller{"gw-key": {}}})
+
+	resp, body := doGateway(t, gw, http.MethodPost, "/v1/embeddings", "gw-key", `{"model":"openai/text-embedding-3-small","input":"hi","truncate":"END"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestHandler_UpstreamErrorMapping(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error":{"message":"rate limited","code":429}}`)
+	}, Config{Keys: map[string]Caller{"gw-key": {}}})
+
+	resp, body := doGateway(t, gw, http.MethodPost, "/v1/embeddings", "gw-key", `{"model":"openai/text-embedding-3-small","input":"hi"}`)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", resp.StatusCode)
+	}
+	var errBody ErrorBody
+	if err := json.Unmarshal(body, &errBody); err != nil {
+		t.Fatalf("decode error body: %v", err)
+	}
+	if errBody.Error.Type != "rate_limit_error" || errBody.Error.Message != "rate limited" {
+		t.Fatalf("unexpected error body: %+v", errBody)
+	}
+}
+
+func TestHandler_ModelsFiltered(t *testing.T) {
+	gw := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"id":"openai/gpt-4o","name":"GPT-4o","created":1700000000,"pricing":{}},{"id":"anthropic/claude-3.5-sonnet","name":"Claude","pricing":{}}]}`)
+	}, Config{Keys: map[string]Caller{"gw-key": {AllowedModels: []string{"openai/gpt-4o"}}}})
+
+	resp, body := doGateway(t, gw, http.MethodGet, "/v1/models", "gw-key", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var list ModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		t.Fatalf("decode models: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != "openai/gpt-4o" || list.Data[0].OwnedBy != "openai" {
+		t.Fatalf("unexpected models: %+v", list)
+	}
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+type (
+	Plugin              = shared.Plugin
+	ProviderPreferences = shared.ProviderPreferences
+	TraceMetadata       = shared.TraceMetadata
+)
+
+const defaultMaxBodyBytes = 32 << 20
+
+// Config configures a gateway Handler.
+type Config struct {
+	// Keys maps gateway-issued bearer keys to the callers that own them.
+	Keys map[string]Caller
+	// Defaults are injected into every upstream request that leaves them unset.
+	Defaults     Defaults
+	MaxBodyBytes int64
+}
+
+// Caller describes a client of the gateway.
+type Caller struct {
+	Name string
+	// AllowedModels restricts the models a caller may request. An empty list
+	// allows every model, "*" matches anything and a trailing "*" matches by prefix.
+	AllowedModels []string
+	// Defaults override the gateway-wide defaults for this caller.
+	Defaults *Defaults
+}
+
+// Defaults are server-side request fields applied when the caller left them empty.
+type Defaults struct {
+	Provider  *ProviderPreferences
+	Plugins   []Plugin
+	Trace     *TraceMetadata
+	SessionID string
+}
+
+func (c Caller) allows(model string) bool {
+	if len(c.AllowedModels) == 0 {
+		return true
+	}
+	for _, pattern := range c.AllowedModels {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(model, prefix) {
+				return true
+			}
+			continue
+		}
+		if pattern == model {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorBody is an OpenAI-shaped error response body.
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   any    `json:"param"`
+	Code    any    `json:"code"`
+}
+
+// ModelList is an OpenAI-shaped model listing.
+type ModelList struct {
+	Object string        `json:"object"`
+	Data   []ModelObject `json:"data"`
+}
+
+type ModelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
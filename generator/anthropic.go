@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/convert"
+)
+
+type anthropicBackend interface {
+	CreateAnthropicMessage(ctx context.Context, req anthropic.Request) (*anthropic.Response, error)
+	CreateAnthropicMessageStream(ctx context.Context, req anthropic.Request) (*anthropic.Stream, error)
+}
+
+// Anthropic is a Generator backed by /messages.
+type Anthropic struct {
+	backend anthropicBackend
+}
+
+func NewAnthropic(backend anthropicBackend) *Anthropic {
+	return &Anthropic{backend: backend}
+}
+
+func (g *Anthropic) Generate(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*Result, error) {
+	req.Stream = false
+	converted, err := convert.ChatToAnthropicRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.backend.CreateAnthropicMessage(ctx, converted)
+	if err != nil {
+		return nil, err
+	}
+	chat, err := convert.AnthropicToChatResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	return resultFromChat(chat), nil
+}
+
+func (g *Anthropic) Stream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (Stream, error) {
+	converted, err := convert.ChatToAnthropicRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := g.backend.CreateAnthropicMessageStream(ctx, converted)
+	if err != nil {
+		return nil, err
+	}
+	return &anthropicStream{stream: stream, acc: anthropic.NewAccumulator()}, nil
+}
+
+type anthropicStream struct {
+	stream *anthropic.Stream
+	acc    *anthropic.Accumulator
+	queue  eventQueue
+	done   bool
+}
+
+func (s *anthropicStream) Recv() (Event, error) {
+	for {
+		if event, ok := s.queue.pop(); ok {
+			return event, nil
+		}
+		if s.done {
+			return Event{}, io.EOF
+		}
+		event, err := s.stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			s.done = true
+			continue
+		}
+		if err != nil {
+			return Event{}, err
+		}
+		if err := s.acc.Add(event); err != nil {
+			return Event{}, err
+		}
+		s.handle(event)
+	}
+}
+
+func (s *anthropicStream) handle(event anthropic.Event) {
+	switch e := event.(type) {
+	case anthropic.ContentBlockDeltaEvent:
+		switch d := e.Delta.(type) {
+		case anthropic.TextDelta:
+			s.queue.push(Event{Type: EventText, Text: d.Text})
+		case anthropic.ThinkingDelta:
+			s.queue.push(Event{Type: EventReasoning, Text: d.Thinking})
+		}
+	case anthropic.ContentBlockStopEvent:
+		message := s.acc.Message()
+		if e.Index >= len(message.Content) {
+			return
+		}
+		if block := message.Content[e.Index]; block.Type == "tool_use" {
+			s.queue.push(Event{Type: EventToolCall, ToolCall: &gopenrouter.ToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: gopenrouter.Function{Name: block.Name, Arguments: string(block.Input)},
+			}})
+		}
+	case anthropic.MessageDeltaEvent:
+		if usage := s.acc.Message().Usage; usage != nil {
+			normalized := usage.Normalized()
+			s.queue.push(Event{Type: EventUsage, Usage: &normalized})
+		}
+		if e.StopReason != "" {
+			s.queue.push(Event{Type: EventFinish, FinishReason: NormalizeFinishReason(e.StopReason), NativeFinishReason: e.StopReason})
+		}
+	case anthropic.MessageStopEvent:
+		s.done = true
+	}
+}
+
+func (s *anthropicStream) Close() {
+	s.stream.Close()
+}
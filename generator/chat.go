@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+type chatBackend interface {
+	CreateChatCompletion(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionResponse, error)
+	CreateChatCompletionStream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*gopenrouter.ChatCompletionStream, error)
+}
+
+// Chat is a Generator backed by /chat/completions.
+type Chat struct {
+	backend chatBackend
+}
+
+func NewChat(backend chatBackend) *Chat {
+	return &Chat{backend: backend}
+}
+
+func (g *Chat) Generate(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*Result, error) {
+	req.Stream = false
+	res, err := g.backend.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resultFromChat(res), nil
+}
+
+func (g *Chat) Stream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (Stream, error) {
+	stream, err := g.backend.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &chatStream{stream: stream, toolCalls: map[int]*gopenrouter.ToolCall{}}, nil
+}
+
+type chatStream struct {
+	stream    *gopenrouter.ChatCompletionStream
+	queue     eventQueue
+	toolCalls map[int]*gopenrouter.ToolCall
+	done      bool
+}
+
+func (s *chatStream) Recv() (Event, error) {
+	for {
+		if event, ok := s.queue.pop(); ok {
+			return event, nil
+		}
+		if s.done {
+			return Event{}, io.EOF
+		}
+		chunk, err := s.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.done = true
+			s.flushToolCalls()
+			continue
+		}
+		if err != nil {
+			return Event{}, err
+		}
+		s.handle(chunk)
+	}
+}
+
+func (s *chatStream) handle(chunk gopenrouter.ChatCompletionStreamResponse) {
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		delta := choice.Delta
+		if delta.Reasoning != "" {
+			s.queue.push(Event{Type: EventReasoning, Text: delta.Reasoning})
+		}
+		if delta.Content != "" {
+			s.queue.push(Event{Type: EventText, Text: delta.Content})
+		}
+		for _, call := range delta.ToolCalls {
+			acc, ok := s.toolCalls[call.Index]
+			if !ok {
+				acc = &gopenrouter.ToolCall{Index: call.Index, Type: "function"}
+				s.toolCalls[call.Index] = acc
+			}
+			if call.ID != "" {
+				acc.ID = call.ID
+			}
+			if call.Type != "" {
+				acc.Type = call.Type
+			}
+			acc.Function.Name += call.Function.Name
+			acc.Function.Arguments += call.Function.Arguments
+		}
+		if choice.FinishReason != "" {
+			s.flushToolCalls()
+			native := choice.NativeFinishReason
+			if native == "" {
+				native = choice.FinishReason
+			}
+			s.queue.push(Event{Type: EventFinish, FinishReason: NormalizeFinishReason(choice.FinishReason), NativeFinishReason: native})
+		}
+	}
+	if chunk.Usage != nil {
+		usage := chunk.Usage.Normalized()
+		s.queue.push(Event{Type: EventUsage, Usage: &usage})
+	}
+}
+
+func (s *chatStream) flushToolCalls() {
+	indexes := make([]int, 0, len(s.toolCalls))
+	for index := range s.toolCalls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		s.queue.push(Event{Type: EventToolCall, ToolCall: s.toolCalls[index]})
+	}
+	clear(s.toolCalls)
+}
+
+func (s *chatStream) Close() {
+	s.stream.Close()
+}
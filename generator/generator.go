@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"context"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// Generator produces model output independently of the endpoint that serves it.
+// Requests and results use the chat completion message model; implementations
+// translate to and from their native dialect.
+type Generator interface {
+	Generate(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*Result, error)
+	Stream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (Stream, error)
+}
+
+// Stream yields normalized events until it returns io.EOF.
+type Stream interface {
+	Recv() (Event, error)
+	Close()
+}
+
+type Result struct {
+	ID                 string
+	Model              string
+	Message            gopenrouter.ChatCompletionMessage
+	FinishReason       FinishReason
+	NativeFinishReason string
+	Usage              shared.NormalizedUsage
+}
+
+type EventType string
+
+const (
+	EventText      EventType = "text"
+	EventReasoning EventType = "reasoning"
+	EventToolCall  EventType = "tool_call"
+	EventUsage     EventType = "usage"
+	EventFinish    EventType = "finish"
+)
+
+// Event is a normalized stream event. Text and reasoning events carry deltas,
+// tool call events carry a complete call once its arguments are known.
+type Event struct {
+	Type               EventType
+	Text               string
+	ToolCall           *gopenrouter.ToolCall
+	Usage              *shared.NormalizedUsage
+	FinishReason       FinishReason
+	NativeFinishReason string
+}
+
+type FinishReason string
+
+const (
+	FinishStop          FinishReason = "stop"
+	FinishLength        FinishReason = "length"
+	FinishToolCalls     FinishReason = "tool_calls"
+	FinishContentFilter FinishReason = "content_filter"
+	FinishError         FinishReason = "error"
+	FinishUnknown       FinishReason = "unknown"
+)
+
+// NormalizeFinishReason maps chat, Responses and Anthropic stop reasons onto FinishReason.
+func NormalizeFinishReason(reason string) FinishReason {
+	switch reason {
+	case "stop", "end_turn", "stop_sequence", "pause_turn", "completed":
+		return FinishStop
+	case "length", "max_tokens", "max_output_tokens", "model_context_window_exceeded":
+		return FinishLength
+	case "tool_calls", "tool_use", "function_call":
+		return FinishToolCalls
+	case "content_filter", "refusal":
+		return FinishContentFilter
+	case "error", "failed":
+		return FinishError
+	default:
+		return FinishUnknown
+	}
+}
+
+func resultFromChat(res *gopenrouter.ChatCompletionResponse) *Result {
+	out := &Result{
+		ID:    res.ID,
+		Model: res.Model,
+		Usage: res.Usage.Normalized(),
+	}
+	if len(res.Choices) == 0 {
+		out.Message.Role = gopenrouter.RoleAssistant
+		return out
+	}
+	choice := res.Choices[0]
+	out.Message = choice.Message
+	out.FinishReason = NormalizeFinishReason(choice.FinishReason)
+	out.NativeFinishReason = choice.NativeFinishReason
+	if out.NativeFinishReason == "" {
+		out.NativeFinishReason = choice.FinishReason
+	}
+	return out
+}
+
+// eventQueue buffers normalized events produced from one upstream event.
+type eventQueue struct {
+	pending []Event
+}
+
+func (q *eventQueue) push(events ...Event) {
+	q.pending = append(q.pending, events...)
+}
+
+func (q *eventQueue) pop() (Event, bool) {
+	if len(q.pending) == 0 {
+		return Event{}, false
+	}
+	event := q.pending[0]
+	q.pending = q.pending[1:]
+	return event, true
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *gopenrouter.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	return gopenrouter.NewClientWithConfig(cfg)
+}
+
+func writeSSE(t *testing.T, w http.ResponseWriter, lines ...string) {
+	t.Helper()
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n\n"); err != nil {
+			t.Fatalf("write stream event: %v", err)
+		}
+	}
+}
+
+func testRequest() gopenrouter.ChatCompletionRequest {
+	return gopenrouter.ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "Weather in Paris?"}},
+		Tools: []gopenrouter.Tool{{Type: "function", Function: gopenrouter.Function{
+			Name:       "get_weather",
+			Parameters: map[string]any{"type": "object"},
+		}}},
+	}
+}
+
+func collect(t *testing.T, stream Stream) []Event {
+	t.Helper()
+	defer stream.Close()
+	var events []Event
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		events = append(events, event)
+	}
+}
+
+func checkStreamEvents(t *testing.T, events []Event) {
+	t.Helper()
+	var text, reasoning strings.Builder
+	var calls []*gopenrouter.ToolCall
+	var usage *shared.NormalizedUsage
+	var finish FinishReason
+	for _, event := range events {
+		switch event.Type {
+		case EventText:
+			text.WriteString(event.Text)
+		case EventReasoning:
+			reasoning.WriteString(event.Text)
+		case EventToolCall:
+			calls = append(calls, event.ToolCall)
+		case EventUsage:
+			usage = event.Usage
+		case EventFinish:
+			finish = event.FinishReason
+		}
+	}
+	if text.String() != "Checking." || reasoning.String() != "Need weather." {
+		t.Fatalf("unexpected text %q reasoning %q", text.String(), reasoning.String())
+	}
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("unexpected tool calls: %+v", calls)
+	}
+	if usage == nil || usage.InputTokens != 10 || usage.OutputTokens != 5 || usage.TotalTokens != 15 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if finish != FinishToolCalls {
+		t.Fatalf("unexpected finish reason: %q", finish)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSSE(t, w,
+			`data: {"id":"gen-1","choices":[{"index":0,"delta":{"role":"assistant","reasoning":"Need weather."}}]}`,
+			`data: {"id":"gen-1","choices":[{"index":0,"delta":{"content":"Checking."}}]}`,
+			`data: {"id":"gen-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":"}}]}}]}`,
+			`data: {"id":"gen-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			`data: {"id":"gen-1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+			`data: {"id":"gen-1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+			`data: [DONE]`,
+		)
+	})
+
+	stream, err := NewChat(client).Stream(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	checkStreamEvents(t, collect(t, stream))
+}
+
+func TestResponsesStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/responses" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		writeSSE(t, w,
+			`data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress"}}`,
+			`data: {"type":"response.reasoning_summary_text.delta","sequence_number":1,"delta":"Need weather."}`,
+			`data: {"type":"response.output_text.delta","sequence_number":2,"delta":"Checking."}`,
+			`data: {"type":"response.output_item.done","sequence_number":3,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}`,
+			`data: {"type":"response.completed","sequence_number":4,"response":{"id":"resp_1","status":"completed","usage":{"input_tokens":10,"output_tokens":5,"total_tokens":15}}}`,
+		)
+	})
+
+	stream, err := NewResponses(client).Stream(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	checkStreamEvents(t, collect(t, stream))
+}
+
+func TestAnthropicStream(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		writeSSE(t, w,
+			"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"Need weather.\"}}",
+			"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"Checking.\"}}",
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":2,\"content_block\":{\"type\":\"tool_use\",\"id\":\"call_1\",\"name\":\"get_weather\",\"input\":{}}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":2,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+			"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":2,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"Paris\\\"}\"}}",
+			"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":2}",
+			"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":5}}",
+			"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+		)
+	})
+
+	stream, err := NewAnthropic(client).Stream(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	checkStreamEvents(t, collect(t, stream))
+}
+
+func TestGenerate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/chat/completions":
+			io.WriteString(w, `{"id":"gen-1","model":"openai/gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Sunny."},"finish_reason":"stop"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+		case "/responses":
+			io.WriteString(w, `{"id":"resp_1","model":"openai/gpt-4o","status":"completed","output":[{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Sunny."}]}],"usage":{"input_tokens":10,"output_tokens":5,"total_tokens":15}}`)
+		case "/messages":
+			io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","model":"openai/gpt-4o","content":[{"type":"text","text":"Sunny."}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":5}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	generators := map[string]Generator{
+		"chat":      NewChat(client),
+		"responses": NewResponses(client),
+		"anthropic": NewAnthropic(client),
+	}
+	for name, gen := range generators {
+		t.Run(name, func(t *testing.T) {
+			result, err := gen.Generate(context.Background(), testRequest())
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			if result.Message.Content != "Sunny." || result.FinishReason != FinishStop {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+			if result.Usage.InputTokens != 10 || result.Usage.OutputTokens != 5 || result.Usage.TotalTokens != 15 {
+				t.Fatalf("unexpected usage: %+v", result.Usage)
+			}
+		})
+	}
+}
+
+func TestNormalizeFinishReason(t *testing.T) {
+	cases := map[string]FinishReason{
+		"stop":              FinishStop,
+		"end_turn":          FinishStop,
+		"max_tokens":        FinishLength,
+		"max_output_tokens": FinishLength,
+		"tool_use":          FinishToolCalls,
+		"content_filter":    FinishContentFilter,
+		"refusal":           FinishContentFilter,
+		"something_new":     FinishUnknown,
+	}
+	for in, want := range cases {
+		if got := NormalizeFinishReason(in); got != want {
+			t.Fatalf("NormalizeFinishReason(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
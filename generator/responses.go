@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/convert"
+	"github.com/iamwavecut/gopenrouter/responses"
+)
+
+type responsesBackend interface {
+	CreateResponse(ctx context.Context, req responses.Request) (*responses.Response, error)
+	CreateResponseStream(ctx context.Context, req responses.Request) (*responses.Stream, error)
+}
+
+// Responses is a Generator backed by /responses.
+type Responses struct {
+	backend responsesBackend
+}
+
+func NewResponses(backend responsesBackend) *Responses {
+	return &Responses{backend: backend}
+}
+
+func (g *Responses) Generate(ctx context.Context, req gopenrouter.ChatCompletionRequest) (*Result, error) {
+	req.Stream = false
+	converted, err := convert.ChatToResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := g.backend.CreateResponse(ctx, converted)
+	if err != nil {
+		return nil, err
+	}
+	chat, err := convert.ResponsesToChatResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	result := resultFromChat(chat)
+	result.NativeFinishReason = res.Status
+	return result, nil
+}
+
+func (g *Responses) Stream(ctx context.Context, req gopenrouter.ChatCompletionRequest) (Stream, error) {
+	converted, err := convert.ChatToResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := g.backend.CreateResponseStream(ctx, converted)
+	if err != nil {
+		return nil, err
+	}
+	return &responsesStream{stream: stream}, nil
+}
+
+type responsesStream struct {
+	stream       *responses.Stream
+	queue        eventQueue
+	sawToolCalls bool
+	done         bool
+}
+
+func (s *responsesStream) Recv() (Event, error) {
+	for {
+		if event, ok := s.queue.pop(); ok {
+			return event, nil
+		}
+		if s.done {
+			return Event{}, io.EOF
+		}
+		event, err := s.stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			s.done = true
+			continue
+		}
+		if err != nil {
+			return Event{}, err
+		}
+		s.handle(event)
+	}
+}
+
+func (s *responsesStream) handle(event responses.Event) {
+	switch e := event.(type) {
+	case responses.OutputTextDeltaEvent:
+		s.queue.push(Event{Type: EventText, Text: e.Delta})
+	case responses.ReasoningSummaryTextDeltaEvent:
+		s.queue.push(Event{Type: EventReasoning, Text: e.Delta})
+	case responses.ReasoningTextDeltaEvent:
+		s.queue.push(Event{Type: EventReasoning, Text: e.Delta})
+	case responses.OutputItemDoneEvent:
+		if e.Item != nil && e.Item.Type == "function_call" {
+			s.sawToolCalls = true
+			s.queue.push(Event{Type: EventToolCall, ToolCall: &gopenrouter.ToolCall{
+				ID:       e.Item.CallID,
+				Type:     "function",
+				Function: gopenrouter.Function{Name: e.Item.Name, Arguments: e.Item.Arguments},
+			}})
+		}
+	case responses.ResponseCompletedEvent:
+		s.finish(e.Response)
+	case responses.ResponseIncompleteEvent:
+		s.finish(e.Response)
+	case responses.ResponseFailedEvent:
+		s.finish(e.Response)
+	}
+}
+
+func (s *responsesStream) finish(res *responses.Response) {
+	s.done = true
+	if res == nil {
+		return
+	}
+	if res.Usage != nil {
+		usage := res.Usage.Normalized()
+		s.queue.push(Event{Type: EventUsage, Usage: &usage})
+	}
+	s.queue.push(Event{Type: EventFinish, FinishReason: s.finishReason(res), NativeFinishReason: res.Status})
+}
+
+func (s *responsesStream) finishReason(res *responses.Response) FinishReason {
+	switch res.Status {
+	case "failed":
+		return FinishError
+	case "incomplete":
+		if reason, _ := res.IncompleteDetails["reason"].(string); reason != "" {
+			return NormalizeFinishReason(reason)
+		}
+		return FinishLength
+	}
+	if s.sawToolCalls {
+		return FinishToolCalls
+	}
+	return FinishStop
+}
+
+func (s *responsesStream) Close() {
+	s.stream.Close()
+}
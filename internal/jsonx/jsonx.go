@@ -3,6 +3,8 @@ package jsonx
 import (
 	"encoding/json"
 	"maps"
+	"reflect"
+	"strings"
 )
 
 func MarshalMap(v any) (map[string]any, error) {
@@ -23,3 +25,34 @@ func MarshalMap(v any) (map[string]any, error) {
 func MergeMaps(dst map[string]any, src map[string]any) {
 	maps.Copy(dst, src)
 }
+
+// UnknownFields returns the top-level members of the JSON object data that do
+// not correspond to a json-tagged field of the struct pointed to by v. Keys
+// match tags case-insensitively, as they do in json.Unmarshal.
+func UnknownFields(data []byte, v any) (map[string]any, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			for key := range raw {
+				if strings.EqualFold(key, name) {
+					delete(raw, key)
+				}
+			}
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return raw, nil
+}
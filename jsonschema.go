@@ -10,6 +10,9 @@ import (
 // It uses reflection to generate a JSON schema from the struct's fields and tags.
 func GenerateSchema(v any) (map[string]any, error) {
 	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("expected a struct, but got nil")
+	}
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
@@ -77,3 +77,17 @@ func TestGenerateSchema_Error(t *testing.T) {
 		t.Errorf("expected error message %q, got %q", expectedError, err.Error())
 	}
 }
+
+// Strict structured outputs need every property required and no extra
+// properties, so optional fields are still listed as required.
+func TestGenerateSchema_Strict(t *testing.T) {
+	schema, err := GenerateSchema(searchArgs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := json.Marshal(schema)
+	want := `{"additionalProperties":false,"properties":{"query":{"type":"string"},"sort":{"type":"string"}},"required":["query","sort"],"type":"object"}`
+	if string(b) != want {
+		t.Fatalf("schema mismatch:\n- got: %s\n- want: %s", b, want)
+	}
+}
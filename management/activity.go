@@ -0,0 +1,252 @@
+package management
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DateLayout is the format of activity dates.
+const DateLayout = "2006-01-02"
+
+type activityGetter interface {
+	GetUserActivity(ctx context.Context, params ActivityParams) ([]ActivityItem, error)
+}
+
+// ActivityRangeParams selects the days to fetch. From and To are inclusive
+// dates in DateLayout.
+type ActivityRangeParams struct {
+	From string
+	To   string
+	// Concurrency bounds the days fetched at once. Zero means 4.
+	Concurrency int
+}
+
+// GetActivityRange fetches every day in the range and returns the rows in
+// date order.
+func (c *Client) GetActivityRange(ctx context.Context, params ActivityRangeParams) ([]ActivityItem, error) {
+	return FetchActivityRange(ctx, c.backend, params)
+}
+
+// FetchActivityRange fetches every day in the range through getter, one
+// request per day, and returns the rows in date order. The first error
+// cancels the remaining requests.
+func FetchActivityRange(ctx context.Context, getter activityGetter, params ActivityRangeParams) ([]ActivityItem, error) {
+	from, err := time.Parse(DateLayout, params.From)
+	if err != nil {
+		return nil, fmt.Errorf("management: invalid From date: %w", err)
+	}
+	to, err := time.Parse(DateLayout, params.To)
+	if err != nil {
+		return nil, fmt.Errorf("management: invalid To date: %w", err)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("management: To %s is before From %s", params.To, params.From)
+	}
+	var days []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(DateLayout))
+	}
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		perDay   = make([][]ActivityItem, len(days))
+		slots    = make(chan struct{}, concurrency)
+	)
+	for i, day := range days {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			items, err := getter.GetUserActivity(ctx, ActivityParams{Date: day})
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("management: activity for %s: %w", day, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			perDay[i] = items
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var out []ActivityItem
+	for _, items := range perDay {
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+// ActivityGroupBy is a dimension to aggregate activity by.
+type ActivityGroupBy string
+
+const (
+	GroupByDay      ActivityGroupBy = "day"
+	GroupByModel    ActivityGroupBy = "model"
+	GroupByProvider ActivityGroupBy = "provider"
+	GroupByEndpoint ActivityGroupBy = "endpoint"
+)
+
+// ActivityGroup is the activity summed over one group. Only the fields of
+// the grouped dimensions are set.
+type ActivityGroup struct {
+	Date             string  `json:"date,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	Provider         string  `json:"provider,omitempty"`
+	EndpointID       string  `json:"endpoint_id,omitempty"`
+	Usage            float64 `json:"usage"`
+	BYOKUsage        float64 `json:"byok_usage"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	ReasoningTokens  int     `json:"reasoning_tokens"`
+	// CostPerMillion is Usage per million prompt and completion tokens.
+	CostPerMillion float64 `json:"cost_per_million"`
+	// The deltas compare with the same group on the previous day, or with
+	// zero when it had no activity. They are only set when grouping by day.
+	UsageDelta    float64 `json:"usage_delta,omitempty"`
+	RequestsDelta int     `json:"requests_delta,omitempty"`
+	TokensDelta   int     `json:"tokens_delta,omitempty"`
+}
+
+// Tokens is the sum of prompt and completion tokens.
+func (g ActivityGroup) Tokens() int {
+	return g.PromptTokens + g.CompletionTokens
+}
+
+// AggregateActivity sums items by the given dimensions; no dimensions sums
+// everything into one group. Groups are ordered by date, then by usage,
+// highest first.
+func AggregateActivity(items []ActivityItem, by ...ActivityGroupBy) []ActivityGroup {
+	type key struct{ date, model, provider, endpoint string }
+	keyOf := func(item ActivityItem) key {
+		var k key
+		for _, dim := range by {
+			switch dim {
+			case GroupByDay:
+				k.date = item.Date
+			case GroupByModel:
+				k.model = item.Model
+			case GroupByProvider:
+				k.provider = item.ProviderName
+			case GroupByEndpoint:
+				k.endpoint = item.EndpointID
+			}
+		}
+		return k
+	}
+
+	groups := make(map[key]*ActivityGroup)
+	for _, item := range items {
+		k := keyOf(item)
+		g, ok := groups[k]
+		if !ok {
+			g = &ActivityGroup{Date: k.date, Model: k.model, Provider: k.provider, EndpointID: k.endpoint}
+			groups[k] = g
+		}
+		g.Usage += item.Usage
+		g.BYOKUsage += item.BYOKUsageInference
+		g.Requests += item.Requests
+		g.PromptTokens += item.PromptTokens
+		g.CompletionTokens += item.CompletionTokens
+		g.ReasoningTokens += item.ReasoningTokens
+	}
+
+	out := make([]ActivityGroup, 0, len(groups))
+	for k, g := range groups {
+		if tokens := g.Tokens(); tokens > 0 {
+			g.CostPerMillion = g.Usage * 1e6 / float64(tokens)
+		}
+		if slices.Contains(by, GroupByDay) {
+			var prev ActivityGroup
+			if day, err := time.Parse(DateLayout, k.date); err == nil {
+				k.date = day.AddDate(0, 0, -1).Format(DateLayout)
+				if p, ok := groups[k]; ok {
+					prev = *p
+				}
+			}
+			g.UsageDelta = g.Usage - prev.Usage
+			g.RequestsDelta = g.Requests - prev.Requests
+			g.TokensDelta = g.Tokens() - prev.Tokens()
+		}
+		out = append(out, *g)
+	}
+	slices.SortFunc(out, func(a, b ActivityGroup) int {
+		return cmp.Or(
+			cmp.Compare(a.Date, b.Date),
+			cmp.Compare(b.Usage, a.Usage),
+			cmp.Compare(a.Model, b.Model),
+			cmp.Compare(a.Provider, b.Provider),
+			cmp.Compare(a.EndpointID, b.EndpointID),
+		)
+	})
+	return out
+}
+
+var activityCSVHeader = []string{
+	"date", "model", "provider", "endpoint_id",
+	"usage", "byok_usage", "requests", "prompt_tokens", "completion_tokens", "reasoning_tokens",
+	"cost_per_million", "usage_delta", "requests_delta", "tokens_delta",
+}
+
+// WriteActivityCSV writes groups as CSV with a header row.
+func WriteActivityCSV(w io.Writer, groups []ActivityGroup) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(activityCSVHeader); err != nil {
+		return err
+	}
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, g := range groups {
+		record := []string{
+			g.Date, g.Model, g.Provider, g.EndpointID,
+			float(g.Usage), float(g.BYOKUsage), strconv.Itoa(g.Requests),
+			strconv.Itoa(g.PromptTokens), strconv.Itoa(g.CompletionTokens), strconv.Itoa(g.ReasoningTokens),
+			float(g.CostPerMillion), float(g.UsageDelta), strconv.Itoa(g.RequestsDelta), strconv.Itoa(g.TokensDelta),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteActivityJSON writes groups as an indented JSON array.
+func WriteActivityJSON(w io.Writer, groups []ActivityGroup) error {
+	if groups == nil {
+		groups = []ActivityGroup{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(groups)
+}
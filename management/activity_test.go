@@ -0,0 +1,155 @@
+package management_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+)
+
+func TestFetchActivityRange(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		dates    []string
+		inFlight atomic.Int32
+		peak     atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/activity" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		time.Sleep(5 * time.Millisecond)
+		date := r.URL.Query().Get("date")
+		mu.Lock()
+		dates = append(dates, date)
+		mu.Unlock()
+		if date == "2026-02-03" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"message":"boom","code":500}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[{"date":%q,"model":"openai/gpt-4o","provider_name":"OpenAI","usage":1,"requests":2}]}`, date)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := management.New(gopenrouter.NewClientWithConfig(cfg))
+	ctx := context.Background()
+
+	items, err := client.GetActivityRange(ctx, management.ActivityRangeParams{From: "2026-01-30", To: "2026-02-02", Concurrency: 2})
+	if err != nil {
+		t.Fatalf("GetActivityRange: %v", err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Date)
+	}
+	if strings.Join(got, ",") != "2026-01-30,2026-01-31,2026-02-01,2026-02-02" {
+		t.Fatalf("unexpected days: %v", got)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("concurrency exceeded: %d", peak.Load())
+	}
+
+	_, err = management.FetchActivityRange(ctx, client, management.ActivityRangeParams{From: "2026-02-01", To: "2026-02-05", Concurrency: 1})
+	if err == nil || !strings.Contains(err.Error(), "2026-02-03") {
+		t.Fatalf("expected error for the failing day, got %v", err)
+	}
+	if _, err := management.FetchActivityRange(ctx, client, management.ActivityRangeParams{From: "2026-02-05", To: "2026-02-01"}); err == nil {
+		t.Fatal("expected error for an inverted range")
+	}
+	if _, err := management.FetchActivityRange(ctx, client, management.ActivityRangeParams{From: "Feb 1", To: "2026-02-01"}); err == nil {
+		t.Fatal("expected error for an invalid date")
+	}
+}
+
+func activityFixture() []management.ActivityItem {
+	return []management.ActivityItem{
+		{Date: "2026-02-01", Model: "openai/gpt-4o", ProviderName: "OpenAI", EndpointID: "ep-1", Usage: 2, Requests: 10, PromptTokens: 800000, CompletionTokens: 200000, ReasoningTokens: 50},
+		{Date: "2026-02-01", Model: "anthropic/claude-sonnet-4", ProviderName: "Anthropic", EndpointID: "ep-2", Usage: 3, BYOKUsageInference: 0.5, Requests: 4, PromptTokens: 100000, CompletionTokens: 50000},
+		{Date: "2026-02-02", Model: "openai/gpt-4o", ProviderName: "Azure", EndpointID: "ep-3", Usage: 1, Requests: 5, PromptTokens: 400000, CompletionTokens: 100000},
+		{Date: "2026-02-02", Model: "openai/gpt-4o", ProviderName: "OpenAI", EndpointID: "ep-1", Usage: 4, Requests: 12, PromptTokens: 900000, CompletionTokens: 100000},
+	}
+}
+
+func TestAggregateActivity(t *testing.T) {
+	items := activityFixture()
+
+	byModel := management.AggregateActivity(items, management.GroupByModel)
+	if len(byModel) != 2 || byModel[0].Model != "openai/gpt-4o" || byModel[0].Usage != 7 || byModel[0].Requests != 27 || byModel[0].Date != "" {
+		t.Fatalf("unexpected model groups: %+v", byModel)
+	}
+	if cpm := byModel[0].CostPerMillion; math.Abs(cpm-7/2.5) > 1e-9 {
+		t.Fatalf("unexpected cost per million: %v", cpm)
+	}
+	if byModel[1].BYOKUsage != 0.5 || byModel[1].ReasoningTokens != 0 || byModel[0].ReasoningTokens != 50 {
+		t.Fatalf("unexpected sums: %+v", byModel)
+	}
+	if byProvider := management.AggregateActivity(items, management.GroupByProvider); len(byProvider) != 3 || byProvider[0].Provider != "OpenAI" {
+		t.Fatalf("unexpected provider groups: %+v", byProvider)
+	}
+	if byEndpoint := management.AggregateActivity(items, management.GroupByEndpoint); len(byEndpoint) != 3 {
+		t.Fatalf("unexpected endpoint groups: %+v", byEndpoint)
+	}
+	if total := management.AggregateActivity(items); len(total) != 1 || total[0].Usage != 10 || total[0].Tokens() != 2650000 {
+		t.Fatalf("unexpected total: %+v", total)
+	}
+
+	byDay := management.AggregateActivity(items, management.GroupByDay)
+	if len(byDay) != 2 || byDay[0].Date != "2026-02-01" || byDay[1].Date != "2026-02-02" {
+		t.Fatalf("unexpected day groups: %+v", byDay)
+	}
+	if byDay[1].UsageDelta != 0 || byDay[1].RequestsDelta != 3 || byDay[1].TokensDelta != 1500000-1150000 || byDay[0].UsageDelta != 5 {
+		t.Fatalf("unexpected deltas: %+v", byDay)
+	}
+	byDayModel := management.AggregateActivity(items, management.GroupByDay, management.GroupByModel)
+	last := byDayModel[len(byDayModel)-1]
+	if len(byDayModel) != 3 || last.Model != "openai/gpt-4o" || last.UsageDelta != 3 || last.RequestsDelta != 7 {
+		t.Fatalf("unexpected day and model groups: %+v", byDayModel)
+	}
+}
+
+func TestWriteActivity(t *testing.T) {
+	groups := management.AggregateActivity(activityFixture(), management.GroupByDay)
+
+	var csvOut bytes.Buffer
+	if err := management.WriteActivityCSV(&csvOut, groups); err != nil {
+		t.Fatalf("WriteActivityCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "date,model,provider,endpoint_id,usage,") {
+		t.Fatalf("unexpected csv:\n%s", csvOut.String())
+	}
+	if lines[2] != "2026-02-02,,,,5,0,17,1300000,200000,0,3.3333333333333335,0,3,350000" {
+		t.Fatalf("unexpected csv row: %s", lines[2])
+	}
+
+	var jsonOut bytes.Buffer
+	if err := management.WriteActivityJSON(&jsonOut, groups); err != nil {
+		t.Fatalf("WriteActivityJSON: %v", err)
+	}
+	var decoded []management.ActivityGroup
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil || len(decoded) != 2 || decoded[1].RequestsDelta != 3 {
+		t.Fatalf("unexpected json: %s (%v)", jsonOut.String(), err)
+	}
+	jsonOut.Reset()
+	if err := management.WriteActivityJSON(&jsonOut, nil); err != nil || strings.TrimSpace(jsonOut.String()) != "[]" {
+		t.Fatalf("empty groups should encode as []: %q", jsonOut.String())
+	}
+}
@@ -18,16 +18,21 @@ type backend interface {
 	UpdateAPIKey(ctx context.Context, hash string, req UpdateAPIKeyRequest) (*ManagedAPIKey, error)
 	DeleteAPIKey(ctx context.Context, hash string) error
 	ListGuardrails(ctx context.Context) ([]Guardrail, error)
+	ListGuardrailsPage(ctx context.Context, params PageParams) (*GuardrailsResponse, error)
 	CreateGuardrail(ctx context.Context, req GuardrailRequest) (*Guardrail, error)
 	GetGuardrail(ctx context.Context, id string) (*Guardrail, error)
 	UpdateGuardrail(ctx context.Context, id string, req GuardrailUpdateRequest) (*Guardrail, error)
 	DeleteGuardrail(ctx context.Context, id string) error
 	ListKeyAssignments(ctx context.Context) ([]GuardrailAssignment, error)
+	ListKeyAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error)
 	ListMemberAssignments(ctx context.Context) ([]GuardrailAssignment, error)
+	ListMemberAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error)
 	ListGuardrailKeyAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error)
+	ListGuardrailKeyAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error)
 	BulkAssignKeys(ctx context.Context, id string, req BulkAssignKeysRequest) error
 	BulkUnassignKeys(ctx context.Context, id string, req BulkAssignKeysRequest) error
 	ListGuardrailMemberAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error)
+	ListGuardrailMemberAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error)
 	BulkAssignMembers(ctx context.Context, id string, req BulkAssignMembersRequest) error
 	BulkUnassignMembers(ctx context.Context, id string, req BulkAssignMembersRequest) error
 }
@@ -81,6 +86,10 @@ func (c *Client) ListGuardrails(ctx context.Context) ([]Guardrail, error) {
 	return c.backend.ListGuardrails(ctx)
 }
 
+func (c *Client) ListGuardrailsPage(ctx context.Context, params PageParams) (*GuardrailsResponse, error) {
+	return c.backend.ListGuardrailsPage(ctx, params)
+}
+
 func (c *Client) CreateGuardrail(ctx context.Context, req GuardrailRequest) (*Guardrail, error) {
 	return c.backend.CreateGuardrail(ctx, req)
 }
@@ -101,14 +110,26 @@ func (c *Client) ListKeyAssignments(ctx context.Context) ([]GuardrailAssignment,
 	return c.backend.ListKeyAssignments(ctx)
 }
 
+func (c *Client) ListKeyAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	return c.backend.ListKeyAssignmentsPage(ctx, params)
+}
+
 func (c *Client) ListMemberAssignments(ctx context.Context) ([]GuardrailAssignment, error) {
 	return c.backend.ListMemberAssignments(ctx)
 }
 
+func (c *Client) ListMemberAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	return c.backend.ListMemberAssignmentsPage(ctx, params)
+}
+
 func (c *Client) ListGuardrailKeyAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error) {
 	return c.backend.ListGuardrailKeyAssignments(ctx, id)
 }
 
+func (c *Client) ListGuardrailKeyAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	return c.backend.ListGuardrailKeyAssignmentsPage(ctx, id, params)
+}
+
 func (c *Client) BulkAssignKeys(ctx context.Context, id string, req BulkAssignKeysRequest) error {
 	return c.backend.BulkAssignKeys(ctx, id, req)
 }
@@ -121,6 +142,10 @@ func (c *Client) ListGuardrailMemberAssignments(ctx context.Context, id string)
 	return c.backend.ListGuardrailMemberAssignments(ctx, id)
 }
 
+func (c *Client) ListGuardrailMemberAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	return c.backend.ListGuardrailMemberAssignmentsPage(ctx, id, params)
+}
+
 func (c *Client) BulkAssignMembers(ctx context.Context, id string, req BulkAssignMembersRequest) error {
 	return c.backend.BulkAssignMembers(ctx, id, req)
 }
@@ -0,0 +1,340 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+type monitorBackend interface {
+	GetCurrentKey(ctx context.Context) (*KeyData, error)
+	GetCredits(ctx context.Context) (*Credits, error)
+	ListAPIKeys(ctx context.Context, params APIKeysListParams) ([]ManagedAPIKey, error)
+}
+
+// Metric is a value a Threshold watches.
+type Metric string
+
+const (
+	// MetricBalance is TotalCredits - TotalUsage of the account.
+	MetricBalance Metric = "balance"
+	// MetricLimitRemaining is a key's LimitRemaining; keys without a limit
+	// are skipped.
+	MetricLimitRemaining Metric = "limit_remaining"
+	MetricUsage          Metric = "usage"
+	MetricUsageDaily     Metric = "usage_daily"
+	MetricUsageWeekly    Metric = "usage_weekly"
+	MetricUsageMonthly   Metric = "usage_monthly"
+	// MetricExpiresIn is the hours until a key's ExpiresAt; keys that never
+	// expire are skipped.
+	MetricExpiresIn Metric = "expires_in"
+)
+
+// Threshold fires an alert when a metric crosses Limit and resolves it once
+// the metric is back past Limit by Hysteresis, so a value hovering around
+// Limit does not flap.
+type Threshold struct {
+	Name   string
+	Metric Metric
+	// Above fires when the value rises above Limit; otherwise the alert
+	// fires when it falls below Limit.
+	Above      bool
+	Limit      float64
+	Hysteresis float64
+}
+
+// BalanceBelow fires when the account balance falls below usd.
+func BalanceBelow(usd float64) Threshold {
+	return Threshold{Name: "balance_below", Metric: MetricBalance, Limit: usd, Hysteresis: usd / 10}
+}
+
+// LimitRemainingBelow fires when a key has less than usd of its limit left.
+func LimitRemainingBelow(usd float64) Threshold {
+	return Threshold{Name: "limit_remaining_below", Metric: MetricLimitRemaining, Limit: usd, Hysteresis: usd / 10}
+}
+
+// DailyUsageAbove fires when a key spends more than usd in a UTC day.
+func DailyUsageAbove(usd float64) Threshold {
+	return Threshold{Name: "daily_usage_above", Metric: MetricUsageDaily, Above: true, Limit: usd, Hysteresis: usd / 10}
+}
+
+// ExpiresWithin fires when a key expires in less than d.
+func ExpiresWithin(d time.Duration) Threshold {
+	return Threshold{Name: "expires_within", Metric: MetricExpiresIn, Limit: d.Hours()}
+}
+
+func (t Threshold) crossed(value float64, firing bool) bool {
+	switch {
+	case t.Above && firing:
+		return value > t.Limit-t.Hysteresis
+	case t.Above:
+		return value > t.Limit
+	case firing:
+		return value < t.Limit+t.Hysteresis
+	default:
+		return value < t.Limit
+	}
+}
+
+// Subjects of alerts that are not about a managed key.
+const (
+	SubjectAccount    = "account"
+	SubjectCurrentKey = "current_key"
+)
+
+// Alert is a threshold crossing. Subject is SubjectAccount, SubjectCurrentKey
+// or the hash of a managed key. Resolved is set when the value has moved
+// back past the threshold.
+type Alert struct {
+	Threshold Threshold
+	Subject   string
+	Label     string
+	Value     float64
+	Resolved  bool
+	At        time.Time
+}
+
+// MonitorConfig configures a Monitor. The current key is always polled.
+type MonitorConfig struct {
+	// Interval is the time between polls in Run. Zero means 5m.
+	Interval time.Duration
+	// Credits polls GetCredits for MetricBalance; it needs a management key.
+	Credits bool
+	// ManagedKeys polls ListAPIKeys so key thresholds also apply to every
+	// managed key; it needs a management key.
+	ManagedKeys     bool
+	IncludeDisabled bool
+	Thresholds      []Threshold
+
+	// OnAlert is called when an alert fires or resolves. Calls are serialized.
+	OnAlert func(Alert)
+	// OnError is called with the error of a failed poll in Run.
+	OnError func(error)
+}
+
+// MonitorSnapshot is the state after the latest poll. Sources that failed
+// keep their last known values.
+type MonitorSnapshot struct {
+	At         time.Time
+	CurrentKey *KeyData
+	Credits    *Credits
+	// Balance is TotalCredits - TotalUsage; it is only meaningful when
+	// Credits is set.
+	Balance     float64
+	ManagedKeys []ManagedAPIKey
+	// Firing holds the alerts that have not resolved.
+	Firing []Alert
+	Err    error
+}
+
+// Monitor polls key usage and credit balance and raises alerts when
+// thresholds are crossed.
+type Monitor struct {
+	backend monitorBackend
+	config  MonitorConfig
+
+	pollMu   sync.Mutex
+	mu       sync.Mutex
+	snapshot MonitorSnapshot
+	firing   map[alertKey]Alert
+}
+
+type alertKey struct {
+	threshold int
+	subject   string
+}
+
+// NewMonitor returns a Monitor that polls through backend.
+func NewMonitor(backend monitorBackend, config MonitorConfig) *Monitor {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	return &Monitor{backend: backend, config: config, firing: make(map[alertKey]Alert)}
+}
+
+// NewMonitor returns a Monitor that polls through c.
+func (c *Client) NewMonitor(config MonitorConfig) *Monitor {
+	return NewMonitor(c.backend, config)
+}
+
+// Run polls until ctx is done, first right away and then every Interval.
+// Poll errors go to OnError; Run returns ctx's error.
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.Poll(ctx); err != nil && ctx.Err() == nil && m.config.OnError != nil {
+			m.config.OnError(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Snapshot returns the state after the latest poll.
+func (m *Monitor) Snapshot() MonitorSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshotLocked()
+}
+
+func (m *Monitor) snapshotLocked() MonitorSnapshot {
+	s := m.snapshot
+	s.ManagedKeys = append([]ManagedAPIKey(nil), s.ManagedKeys...)
+	s.Firing = make([]Alert, 0, len(m.firing))
+	for i := range m.config.Thresholds {
+		for key, alert := range m.firing {
+			if key.threshold == i {
+				s.Firing = append(s.Firing, alert)
+			}
+		}
+	}
+	return s
+}
+
+// Poll fetches every source once, evaluates the thresholds and returns the
+// new snapshot. Thresholds are only evaluated for sources that were fetched,
+// so a failed request neither fires nor resolves alerts.
+func (m *Monitor) Poll(ctx context.Context) (MonitorSnapshot, error) {
+	m.pollMu.Lock()
+	defer m.pollMu.Unlock()
+
+	var errs []error
+	key, err := m.backend.GetCurrentKey(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("management: current key: %w", err))
+	}
+	var credits *Credits
+	if m.config.Credits {
+		if credits, err = m.backend.GetCredits(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("management: credits: %w", err))
+		}
+	}
+	var managed []ManagedAPIKey
+	managedOK := false
+	if m.config.ManagedKeys {
+		if managed, err = m.listKeys(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("management: keys: %w", err))
+		} else {
+			managedOK = true
+		}
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	s := &m.snapshot
+	s.At = now
+	s.Err = errors.Join(errs...)
+	var alerts []Alert
+	if key != nil {
+		s.CurrentKey = key
+		alerts = append(alerts, m.evaluateKey(SubjectCurrentKey, key.Label, keyValues(key.Limit, key.LimitRemaining, key.Usage, key.UsageDaily, key.UsageWeekly, key.UsageMonthly, key.ExpiresAt, now), now)...)
+	}
+	if credits != nil {
+		s.Credits = credits
+		s.Balance = credits.TotalCredits - credits.TotalUsage
+		alerts = append(alerts, m.evaluate(SubjectAccount, "", MetricBalance, s.Balance, now)...)
+	}
+	if managedOK {
+		s.ManagedKeys = managed
+		present := make(map[string]bool, len(managed))
+		for _, k := range managed {
+			present[k.Hash] = true
+			alerts = append(alerts, m.evaluateKey(k.Hash, k.Name, keyValues(k.Limit, k.LimitRemaining, k.Usage, k.UsageDaily, k.UsageWeekly, k.UsageMonthly, k.ExpiresAt, now), now)...)
+		}
+		// Alerts of deleted keys are dropped without a resolution.
+		for ak := range m.firing {
+			if ak.subject != SubjectAccount && ak.subject != SubjectCurrentKey && !present[ak.subject] {
+				delete(m.firing, ak)
+			}
+		}
+	}
+	snapshot := m.snapshotLocked()
+	m.mu.Unlock()
+
+	if m.config.OnAlert != nil {
+		for _, alert := range alerts {
+			m.config.OnAlert(alert)
+		}
+	}
+	return snapshot, snapshot.Err
+}
+
+// listKeys fetches every managed key.
+func (m *Monitor) listKeys(ctx context.Context) ([]ManagedAPIKey, error) {
+	var keys []ManagedAPIKey
+	for key, err := range shared.Paginate(ctx, func(ctx context.Context, offset, _ int) ([]ManagedAPIKey, error) {
+		return m.backend.ListAPIKeys(ctx, APIKeysListParams{IncludeDisabled: m.config.IncludeDisabled, Offset: offset})
+	}, shared.PageOptions{Lookahead: -1}) {
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+var keyMetrics = []Metric{MetricLimitRemaining, MetricUsage, MetricUsageDaily, MetricUsageWeekly, MetricUsageMonthly, MetricExpiresIn}
+
+// keyValues returns the key metrics that are defined for a key.
+func keyValues(limit, remaining, usage, daily, weekly, monthly float64, expiresAt string, now time.Time) map[Metric]float64 {
+	values := map[Metric]float64{
+		MetricUsage:        usage,
+		MetricUsageDaily:   daily,
+		MetricUsageWeekly:  weekly,
+		MetricUsageMonthly: monthly,
+	}
+	if limit > 0 {
+		values[MetricLimitRemaining] = remaining
+	}
+	if expiresAt != "" {
+		if at, err := time.Parse(time.RFC3339, expiresAt); err == nil {
+			values[MetricExpiresIn] = at.Sub(now).Hours()
+		}
+	}
+	return values
+}
+
+func (m *Monitor) evaluateKey(subject, label string, values map[Metric]float64, now time.Time) []Alert {
+	var alerts []Alert
+	for _, metric := range keyMetrics {
+		if value, ok := values[metric]; ok {
+			alerts = append(alerts, m.evaluate(subject, label, metric, value, now)...)
+		}
+	}
+	return alerts
+}
+
+// evaluate updates the alert state of subject for the thresholds on metric
+// and returns the alerts that fired or resolved. m.mu must be held.
+func (m *Monitor) evaluate(subject, label string, metric Metric, value float64, now time.Time) []Alert {
+	var alerts []Alert
+	for i, t := range m.config.Thresholds {
+		if t.Metric != metric {
+			continue
+		}
+		key := alertKey{threshold: i, subject: subject}
+		prev, firing := m.firing[key]
+		alert := Alert{Threshold: t, Subject: subject, Label: label, Value: value, At: now}
+		switch crossed := t.crossed(value, firing); {
+		case crossed && firing:
+			alert.At = prev.At
+			m.firing[key] = alert
+		case crossed:
+			m.firing[key] = alert
+			alerts = append(alerts, alert)
+		case firing:
+			delete(m.firing, key)
+			alert.Resolved = true
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,149 @@
+package management_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func TestMonitorThresholds(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		usage       = 5.0
+		daily       = 1.0
+		creditsFail bool
+	)
+	expires := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/key":
+			fmt.Fprintf(w, `{"data":{"label":"sk-or-v1-abc","usage":%v,"usage_daily":%v,"limit":20,"limit_remaining":%v}}`, usage, daily, 20-usage)
+		case "/credits":
+			if creditsFail {
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, `{"error":{"message":"upstream","code":502}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":{"total_credits":20,"total_usage":%v}}`, usage)
+		case "/keys":
+			if r.URL.Query().Get("offset") != "" {
+				fmt.Fprint(w, `{"data":[]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":[{"hash":"h1","name":"ci","usage_daily":0.5,"expires_at":%q},{"hash":"h2","name":"prod","usage_daily":%v}]}`, expires, daily)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+
+	var alerts []management.Alert
+	monitor := management.New(gopenrouter.NewClientWithConfig(cfg)).NewMonitor(management.MonitorConfig{
+		Credits:     true,
+		ManagedKeys: true,
+		Thresholds: []management.Threshold{
+			management.BalanceBelow(10),
+			management.DailyUsageAbove(8),
+			management.ExpiresWithin(72 * time.Hour),
+		},
+		OnAlert: func(a management.Alert) { alerts = append(alerts, a) },
+	})
+	ctx := context.Background()
+	poll := func(u, d float64) management.MonitorSnapshot {
+		t.Helper()
+		mu.Lock()
+		usage, daily = u, d
+		mu.Unlock()
+		alerts = nil
+		snap, err := monitor.Poll(ctx)
+		if err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+		return snap
+	}
+	describe := func() []string {
+		var out []string
+		for _, a := range alerts {
+			out = append(out, fmt.Sprintf("%s/%s/%v", a.Threshold.Name, a.Subject, a.Resolved))
+		}
+		return out
+	}
+
+	snap := poll(5, 1)
+	if snap.Balance != 15 || snap.CurrentKey.LimitRemaining != 15 || len(snap.ManagedKeys) != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+	if got := describe(); len(got) != 1 || got[0] != "expires_within/h1/false" {
+		t.Fatalf("expected only the expiry alert, got %v", got)
+	}
+
+	poll(11, 9)
+	if got := describe(); len(got) != 3 || got[0] != "daily_usage_above/current_key/false" || got[1] != "balance_below/account/false" || got[2] != "daily_usage_above/h2/false" {
+		t.Fatalf("unexpected alerts: %v", got)
+	}
+	// Within the hysteresis band nothing changes.
+	if poll(9.5, 7.5); len(alerts) != 0 {
+		t.Fatalf("alerts flapped: %v", describe())
+	}
+	if snap := monitor.Snapshot(); len(snap.Firing) != 4 || snap.Firing[0].Value != 10.5 {
+		t.Fatalf("unexpected firing alerts: %+v", snap.Firing)
+	}
+	poll(8, 7)
+	if got := describe(); len(got) != 3 || got[0] != "daily_usage_above/current_key/true" || got[1] != "balance_below/account/true" {
+		t.Fatalf("expected resolutions, got %v", got)
+	}
+
+	mu.Lock()
+	creditsFail = true
+	mu.Unlock()
+	snap, err := monitor.Poll(ctx)
+	var apiErr *shared.APIError
+	if !errors.As(err, &apiErr) || snap.Balance != 12 || snap.Err == nil {
+		t.Fatalf("expected the credits error with the last balance, got %v (%+v)", err, snap)
+	}
+}
+
+func TestMonitorRun(t *testing.T) {
+	var polls int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		mu.Unlock()
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"No auth credentials found","code":401}}`)
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+
+	errs := make(chan error, 10)
+	monitor := management.NewMonitor(gopenrouter.NewClientWithConfig(cfg), management.MonitorConfig{
+		Interval: 5 * time.Millisecond,
+		OnError:  func(err error) { errs <- err },
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := monitor.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(errs) < 2 {
+		t.Fatalf("expected an error per poll, got %d", len(errs))
+	}
+	if snap := monitor.Snapshot(); snap.CurrentKey != nil || snap.Err == nil {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
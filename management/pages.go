@@ -0,0 +1,73 @@
+package management
+
+import (
+	"context"
+	"iter"
+
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// maxPageSize is the largest limit the guardrail list endpoints accept.
+const maxPageSize = 100
+
+// AllAPIKeys iterates over every API key from params.Offset on, fetching
+// the next page while the current one is consumed.
+func (c *Client) AllAPIKeys(ctx context.Context, params APIKeysListParams) iter.Seq2[ManagedAPIKey, error] {
+	return shared.Paginate(ctx, func(ctx context.Context, offset, _ int) ([]ManagedAPIKey, error) {
+		return c.backend.ListAPIKeys(ctx, APIKeysListParams{IncludeDisabled: params.IncludeDisabled, Offset: offset})
+	}, shared.PageOptions{Offset: params.Offset})
+}
+
+// AllGuardrails iterates over every guardrail.
+func (c *Client) AllGuardrails(ctx context.Context) iter.Seq2[Guardrail, error] {
+	return paginate(ctx, func(ctx context.Context, params PageParams) ([]Guardrail, error) {
+		res, err := c.backend.ListGuardrailsPage(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	})
+}
+
+// AllKeyAssignments iterates over the key assignments of every guardrail.
+func (c *Client) AllKeyAssignments(ctx context.Context) iter.Seq2[GuardrailAssignment, error] {
+	return paginateAssignments(ctx, c.backend.ListKeyAssignmentsPage)
+}
+
+// AllMemberAssignments iterates over the member assignments of every
+// guardrail.
+func (c *Client) AllMemberAssignments(ctx context.Context) iter.Seq2[GuardrailAssignment, error] {
+	return paginateAssignments(ctx, c.backend.ListMemberAssignmentsPage)
+}
+
+// AllGuardrailKeyAssignments iterates over the key assignments of one
+// guardrail.
+func (c *Client) AllGuardrailKeyAssignments(ctx context.Context, id string) iter.Seq2[GuardrailAssignment, error] {
+	return paginateAssignments(ctx, func(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
+		return c.backend.ListGuardrailKeyAssignmentsPage(ctx, id, params)
+	})
+}
+
+// AllGuardrailMemberAssignments iterates over the member assignments of one
+// guardrail.
+func (c *Client) AllGuardrailMemberAssignments(ctx context.Context, id string) iter.Seq2[GuardrailAssignment, error] {
+	return paginateAssignments(ctx, func(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
+		return c.backend.ListGuardrailMemberAssignmentsPage(ctx, id, params)
+	})
+}
+
+func paginateAssignments(ctx context.Context, list func(context.Context, PageParams) (*GuardrailAssignmentsResponse, error)) iter.Seq2[GuardrailAssignment, error] {
+	return paginate(ctx, func(ctx context.Context, params PageParams) ([]GuardrailAssignment, error) {
+		res, err := list(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return res.Data, nil
+	})
+}
+
+func paginate[T any](ctx context.Context, list func(context.Context, PageParams) ([]T, error)) iter.Seq2[T, error] {
+	return shared.Paginate(ctx, func(ctx context.Context, offset, limit int) ([]T, error) {
+		return list(ctx, PageParams{Offset: offset, Limit: limit})
+	}, shared.PageOptions{PageSize: maxPageSize})
+}
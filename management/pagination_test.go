@@ -0,0 +1,95 @@
+package management_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/management"
+)
+
+func TestManagementIterators(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		switch r.URL.Path {
+		case "/keys":
+			// The server pages keys by 2.
+			var items []string
+			for i := offset; i < 5 && len(items) < 2; i++ {
+				items = append(items, fmt.Sprintf(`{"hash":"h%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"data":[%s]}`, strings.Join(items, ","))
+		case "/guardrails/g1/assignments/keys":
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			var items []string
+			for i := offset; i < 130 && len(items) < limit; i++ {
+				items = append(items, fmt.Sprintf(`{"key_hash":"k%d","guardrail_id":"g1"}`, i))
+			}
+			fmt.Fprintf(w, `{"data":[%s],"total_count":130}`, strings.Join(items, ","))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := management.New(gopenrouter.NewClientWithConfig(cfg))
+	ctx := context.Background()
+
+	var hashes []string
+	for key, err := range client.AllAPIKeys(ctx, management.APIKeysListParams{IncludeDisabled: true, Offset: 1}) {
+		if err != nil {
+			t.Fatalf("AllAPIKeys: %v", err)
+		}
+		hashes = append(hashes, key.Hash)
+	}
+	if strings.Join(hashes, ",") != "h1,h2,h3,h4" {
+		t.Fatalf("unexpected keys: %v", hashes)
+	}
+
+	var assignments int
+	for a, err := range client.AllGuardrailKeyAssignments(ctx, "g1") {
+		if err != nil {
+			t.Fatalf("AllGuardrailKeyAssignments: %v", err)
+		}
+		if a.KeyHash != fmt.Sprintf("k%d", assignments) {
+			t.Fatalf("out of order: %+v", a)
+		}
+		assignments++
+	}
+	if assignments != 130 {
+		t.Fatalf("got %d assignments", assignments)
+	}
+
+	page, err := client.ListGuardrailKeyAssignmentsPage(ctx, "g1", management.PageParams{Offset: 120, Limit: 50})
+	if err != nil || len(page.Data) != 10 || page.TotalCount != 130 {
+		t.Fatalf("unexpected page: %+v, %v", page, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"/keys?include_disabled=true&offset=1",
+		"/keys?include_disabled=true&offset=3",
+		"/keys?include_disabled=true&offset=5",
+		"/guardrails/g1/assignments/keys?limit=100",
+		"/guardrails/g1/assignments/keys?limit=100&offset=100",
+		"/guardrails/g1/assignments/keys?limit=50&offset=120",
+	}
+	if strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected requests:\n%s", strings.Join(queries, "\n"))
+	}
+}
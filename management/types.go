@@ -27,6 +27,13 @@ type APIKeysListParams struct {
 	Offset          int
 }
 
+// PageParams selects a page of a guardrail list. A zero Limit uses the
+// server default; the maximum is 100.
+type PageParams struct {
+	Offset int
+	Limit  int
+}
+
 type APIKeysResponse struct {
 	Data []ManagedAPIKey `json:"data"`
 }
@@ -126,7 +133,8 @@ type BulkAssignMembersRequest struct {
 }
 
 type GuardrailsResponse struct {
-	Data []Guardrail `json:"data"`
+	Data       []Guardrail `json:"data"`
+	TotalCount int         `json:"total_count,omitempty"`
 }
 
 type GuardrailResponse struct {
@@ -134,7 +142,8 @@ type GuardrailResponse struct {
 }
 
 type GuardrailAssignmentsResponse struct {
-	Data []GuardrailAssignment `json:"data"`
+	Data       []GuardrailAssignment `json:"data"`
+	TotalCount int                   `json:"total_count,omitempty"`
 }
 
 type Credits struct {
@@ -101,13 +101,21 @@ func (c *Client) DeleteAPIKey(ctx context.Context, hash string) error {
 }
 
 func (c *Client) ListGuardrails(ctx context.Context) ([]Guardrail, error) {
-	var res GuardrailsResponse
-	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails", nil, nil, &res); err != nil {
+	res, err := c.ListGuardrailsPage(ctx, PageParams{})
+	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
+func (c *Client) ListGuardrailsPage(ctx context.Context, params PageParams) (*GuardrailsResponse, error) {
+	var res GuardrailsResponse
+	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails", pageQuery(params), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c *Client) CreateGuardrail(ctx context.Context, req GuardrailRequest) (*Guardrail, error) {
 	var res GuardrailResponse
 	if err := c.doJSON(ctx, http.MethodPost, c.config.BaseURL+"/guardrails", nil, req, &res); err != nil {
@@ -137,29 +145,53 @@ func (c *Client) DeleteGuardrail(ctx context.Context, id string) error {
 }
 
 func (c *Client) ListKeyAssignments(ctx context.Context) ([]GuardrailAssignment, error) {
-	var res GuardrailAssignmentsResponse
-	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/assignments/keys", nil, nil, &res); err != nil {
+	res, err := c.ListKeyAssignmentsPage(ctx, PageParams{})
+	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
-func (c *Client) ListMemberAssignments(ctx context.Context) ([]GuardrailAssignment, error) {
+func (c *Client) ListKeyAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
 	var res GuardrailAssignmentsResponse
-	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/assignments/members", nil, nil, &res); err != nil {
+	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/assignments/keys", pageQuery(params), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) ListMemberAssignments(ctx context.Context) ([]GuardrailAssignment, error) {
+	res, err := c.ListMemberAssignmentsPage(ctx, PageParams{})
+	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
-func (c *Client) ListGuardrailKeyAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error) {
+func (c *Client) ListMemberAssignmentsPage(ctx context.Context, params PageParams) (*GuardrailAssignmentsResponse, error) {
 	var res GuardrailAssignmentsResponse
-	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/keys", nil, nil, &res); err != nil {
+	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/assignments/members", pageQuery(params), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) ListGuardrailKeyAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error) {
+	res, err := c.ListGuardrailKeyAssignmentsPage(ctx, id, PageParams{})
+	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
+func (c *Client) ListGuardrailKeyAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	var res GuardrailAssignmentsResponse
+	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/keys", pageQuery(params), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c *Client) BulkAssignKeys(ctx context.Context, id string, req BulkAssignKeysRequest) error {
 	return c.doJSON(ctx, http.MethodPost, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/keys", nil, req, nil)
 }
@@ -169,13 +201,21 @@ func (c *Client) BulkUnassignKeys(ctx context.Context, id string, req BulkAssign
 }
 
 func (c *Client) ListGuardrailMemberAssignments(ctx context.Context, id string) ([]GuardrailAssignment, error) {
-	var res GuardrailAssignmentsResponse
-	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/members", nil, nil, &res); err != nil {
+	res, err := c.ListGuardrailMemberAssignmentsPage(ctx, id, PageParams{})
+	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
+func (c *Client) ListGuardrailMemberAssignmentsPage(ctx context.Context, id string, params PageParams) (*GuardrailAssignmentsResponse, error) {
+	var res GuardrailAssignmentsResponse
+	if err := c.doJSON(ctx, http.MethodGet, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/members", pageQuery(params), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c *Client) BulkAssignMembers(ctx context.Context, id string, req BulkAssignMembersRequest) error {
 	return c.doJSON(ctx, http.MethodPost, c.config.BaseURL+"/guardrails/"+url.PathEscape(id)+"/assignments/members", nil, req, nil)
 }
@@ -199,3 +239,14 @@ func (c *Client) ExchangeAuthCodeForAPIKey(ctx context.Context, req ExchangeAuth
 	}
 	return &res, nil
 }
+
+func pageQuery(params PageParams) url.Values {
+	query := url.Values{}
+	if params.Offset > 0 {
+		query.Set("offset", strconv.Itoa(params.Offset))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	return query
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+// Bridge exposes the tools of one or more MCP servers as OpenRouter tools.
+// The embedded ToolSet produces chat, /responses and /messages tool
+// definitions and runner handlers; calls are dispatched over MCP.
+type Bridge struct {
+	*gopenrouter.ToolSet
+}
+
+// NewBridge lists the tools of every client. Tool names must be unique across
+// clients.
+func NewBridge(ctx context.Context, clients ...*Client) (*Bridge, error) {
+	b := &Bridge{ToolSet: gopenrouter.NewToolSet()}
+	for _, client := range clients {
+		tools, err := client.ListTools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tool := range tools {
+			if b.Get(tool.Name) != nil {
+				return nil, fmt.Errorf("mcp: duplicate tool %q", tool.Name)
+			}
+			b.Add(FunctionTool(client, tool))
+		}
+	}
+	return b, nil
+}
+
+// FunctionTool wraps an MCP tool as a gopenrouter tool that calls it through
+// client.
+func FunctionTool(client *Client, tool Tool) *gopenrouter.FunctionTool {
+	description := tool.Description
+	if description == "" {
+		description = tool.Title
+	}
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return gopenrouter.NewSchemaTool(tool.Name, description, schema, func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		result, err := client.CallTool(ctx, tool.Name, arguments)
+		if err != nil {
+			return "", err
+		}
+		text := result.Text()
+		if result.IsError {
+			if text == "" {
+				text = "tool reported an error"
+			}
+			return "", errors.New(text)
+		}
+		return text, nil
+	})
+}
+
+// ToolMessages dispatches the calls that name a bridged tool and returns
+// their tool messages, in order. Calls for other tools are returned as
+// unmatched for the caller to handle.
+func (b *Bridge) ToolMessages(ctx context.Context, calls []gopenrouter.ToolCall) (messages []gopenrouter.ChatCompletionMessage, unmatched []gopenrouter.ToolCall) {
+	for _, call := range calls {
+		if b.Get(call.Function.Name) == nil {
+			unmatched = append(unmatched, call)
+			continue
+		}
+		messages = append(messages, b.ToolMessage(ctx, call))
+	}
+	return messages, unmatched
+}
+
+// Text renders the result for a model: text items are joined by newlines,
+// structured content is used when there is no text and other items are
+// described briefly.
+func (r *CallToolResult) Text() string {
+	var parts []string
+	for _, content := range r.Content {
+		switch content.Type {
+		case "text":
+			parts = append(parts, content.Text)
+		case "resource":
+			if text, ok := content.Resource["text"].(string); ok {
+				parts = append(parts, text)
+				continue
+			}
+			uri, _ := content.Resource["uri"].(string)
+			parts = append(parts, fmt.Sprintf("[resource %s]", uri))
+		case "resource_link":
+			parts = append(parts, fmt.Sprintf("[resource %s]", content.URI))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s %s]", content.Type, content.MimeType))
+		}
+	}
+	if len(parts) == 0 && r.StructuredContent != nil {
+		if b, err := json.Marshal(r.StructuredContent); err == nil {
+			return string(b)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
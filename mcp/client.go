@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// Client is an MCP client session over a Transport.
+type Client struct {
+	transport Transport
+	nextID    atomic.Int64
+	server    InitializeResult
+}
+
+// Connect performs the initialization handshake over transport. The
+// transport is closed if the handshake fails.
+func Connect(ctx context.Context, transport Transport, clientInfo Implementation) (*Client, error) {
+	if clientInfo.Name == "" {
+		clientInfo = Implementation{Name: "gopenrouter", Version: "1.0.0"}
+	}
+	c := &Client{transport: transport}
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      clientInfo,
+	}
+	if err := c.call(ctx, "initialize", params, &c.server); err != nil {
+		transport.Close()
+		return nil, err
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		transport.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Server returns what the server reported during initialization.
+func (c *Client) Server() InitializeResult {
+	return c.server
+}
+
+// ListTools returns every tool the server offers, following pagination.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var (
+		tools  []Tool
+		cursor string
+	)
+	for {
+		var params map[string]any
+		if cursor != "" {
+			params = map[string]any{"cursor": cursor}
+		}
+		var page listToolsResult
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool invokes a tool. arguments must be a JSON object or empty. A tool
+// that fails reports IsError in the result rather than an error.
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	params := map[string]any{"name": name, "arguments": arguments}
+	var result CallToolResult
+	if err := c.call(ctx, "tools/call", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	id := strconv.FormatInt(c.nextID.Add(1), 10)
+	res, err := c.transport.RoundTrip(ctx, &Message{JSONRPC: jsonRPCVersion, ID: json.RawMessage(id), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return fmt.Errorf("mcp: %s: no response", method)
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("mcp: decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	_, err := c.transport.RoundTrip(ctx, &Message{JSONRPC: jsonRPCVersion, Method: method, Params: params})
+	return err
+}
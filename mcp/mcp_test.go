@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+const stubEnv = "GOPENROUTER_MCP_STUB"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stubEnv) {
+	case "1":
+		serveStdio(os.Stdin, os.Stdout)
+		os.Exit(0)
+	case "hang":
+		// A server that ignores the end of its input.
+		serveStdio(os.Stdin, os.Stdout)
+		time.Sleep(time.Hour)
+	}
+	os.Exit(m.Run())
+}
+
+// stubHandle answers one request of the stub server; it returns nil for
+// notifications.
+func stubHandle(msg Message) *Message {
+	if len(msg.ID) == 0 {
+		return nil
+	}
+	reply := &Message{JSONRPC: jsonRPCVersion, ID: msg.ID}
+	params, _ := msg.Params.(map[string]any)
+	result := func(v any) *Message {
+		reply.Result, _ = json.Marshal(v)
+		return reply
+	}
+	switch msg.Method {
+	case "initialize":
+		return result(InitializeResult{ProtocolVersion: ProtocolVersion, Capabilities: map[string]any{"tools": map[string]any{}}, ServerInfo: Implementation{Name: "stub", Version: "0.1"}})
+	case "tools/list":
+		if params["cursor"] == "page2" {
+			return result(listToolsResult{Tools: []Tool{{Name: "fail", Title: "Always fails", InputSchema: map[string]any{"type": "object"}}}})
+		}
+		return result(listToolsResult{NextCursor: "page2", Tools: []Tool{{
+			Name:        "add",
+			Description: "Adds two numbers",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"a": map[string]any{"type": "number"}, "b": map[string]any{"type": "number"}},
+				"required":   []any{"a", "b"},
+			},
+		}}})
+	case "tools/call":
+		args, _ := params["arguments"].(map[string]any)
+		switch params["name"] {
+		case "add":
+			a, _ := args["a"].(float64)
+			b, _ := args["b"].(float64)
+			return result(CallToolResult{Content: []Content{{Type: "text", Text: fmt.Sprint(a + b)}}})
+		case "fail":
+			return result(CallToolResult{IsError: true, Content: []Content{{Type: "text", Text: "boom"}}})
+		}
+	}
+	reply.Error = &RPCError{Code: -32601, Message: "method not found"}
+	return reply
+}
+
+func serveStdio(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if msg.Method == "tools/call" {
+			// Interleave a server request and a notification before the reply.
+			_ = enc.Encode(Message{JSONRPC: jsonRPCVersion, ID: json.RawMessage(`"srv-1"`), Method: "ping"})
+			_ = enc.Encode(Message{JSONRPC: jsonRPCVersion, Method: "notifications/message", Params: map[string]any{"level": "info"}})
+		}
+		if reply := stubHandle(msg); reply != nil {
+			_ = enc.Encode(reply)
+		}
+	}
+}
+
+func stdioClient(t *testing.T) *Client {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), stubEnv+"=1")
+	transport, err := NewStdioTransport(cmd)
+	if err != nil {
+		t.Fatalf("NewStdioTransport: %v", err)
+	}
+	client, err := Connect(context.Background(), transport, Implementation{})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestStdioClient(t *testing.T) {
+	client := stdioClient(t)
+	ctx := context.Background()
+
+	if info := client.Server().ServerInfo; info.Name != "stub" {
+		t.Fatalf("unexpected server info: %+v", info)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "add" || tools[1].Name != "fail" {
+		t.Fatalf("expected both pages of tools, got %+v", tools)
+	}
+	res, err := client.CallTool(ctx, "add", json.RawMessage(`{"a":2,"b":3}`))
+	if err != nil || res.Text() != "5" {
+		t.Fatalf("unexpected call result %+v, %v", res, err)
+	}
+	if _, err := client.CallTool(ctx, "missing", nil); err == nil {
+		t.Fatal("expected rpc error")
+	}
+}
+
+func TestStdioCloseKillsHungServer(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), stubEnv+"=hang")
+	transport, err := NewStdioTransport(cmd)
+	if err != nil {
+		t.Fatalf("NewStdioTransport: %v", err)
+	}
+	transport.ShutdownTimeout = 50 * time.Millisecond
+	start := time.Now()
+	err = transport.Close()
+	if err == nil || !strings.Contains(err.Error(), "killed") {
+		t.Fatalf("expected a kill error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Close took %s", elapsed)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+	bridge, err := NewBridge(ctx, stdioClient(t))
+	if err != nil {
+		t.Fatalf("NewBridge: %v", err)
+	}
+
+	chat := bridge.ChatTools()
+	if len(chat) != 2 || chat[0].Function.Name != "add" || chat[1].Function.Description != "Always fails" {
+		t.Fatalf("unexpected chat tools: %+v", chat)
+	}
+	if schema := chat[0].Function.Parameters.(map[string]any); schema["required"] == nil {
+		t.Fatalf("input schema not carried over: %v", schema)
+	}
+	if rt := bridge.ResponsesTools(); rt[0].Type != "function" || rt[0].Name != "add" {
+		t.Fatalf("unexpected responses tools: %+v", rt)
+	}
+	if at := bridge.AnthropicTools(); at[1].Name != "fail" || at[1].InputSchema == nil {
+		t.Fatalf("unexpected anthropic tools: %+v", at)
+	}
+
+	messages, unmatched := bridge.ToolMessages(ctx, []gopenrouter.ToolCall{
+		{ID: "call_1", Type: "function", Function: gopenrouter.Function{Name: "add", Arguments: `{"a":1,"b":1.5}`}},
+		{ID: "call_2", Type: "function", Function: gopenrouter.Function{Name: "local"}},
+		{ID: "call_3", Type: "function", Function: gopenrouter.Function{Name: "fail", Arguments: `{}`}},
+	})
+	if len(messages) != 2 || len(unmatched) != 1 || unmatched[0].ID != "call_2" {
+		t.Fatalf("unexpected dispatch: %+v, %+v", messages, unmatched)
+	}
+	if messages[0].Role != gopenrouter.RoleTool || messages[0].ToolCallID != "call_1" || messages[0].Content != "2.5" {
+		t.Fatalf("unexpected tool message: %+v", messages[0])
+	}
+	if messages[1].ToolCallID != "call_3" || messages[1].Content != "error: boom" {
+		t.Fatalf("expected error tool message: %+v", messages[1])
+	}
+
+	if _, err := NewBridge(ctx, stdioClient(t), stdioClient(t)); err == nil || !strings.Contains(err.Error(), "duplicate tool") {
+		t.Fatalf("expected duplicate tool error, got %v", err)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	var sessions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			sessions = append(sessions, "deleted:"+r.Header.Get("Mcp-Session-Id"))
+			return
+		}
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else {
+			sessions = append(sessions, r.Header.Get("Mcp-Session-Id")+"/"+r.Header.Get("MCP-Protocol-Version"))
+		}
+		reply := stubHandle(msg)
+		if reply == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		b, _ := json.Marshal(reply)
+		if msg.Method == "tools/call" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", b)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	transport := NewHTTPTransport(server.URL, nil, http.Header{"Authorization": {"Bearer secret"}})
+	client, err := Connect(ctx, transport, Implementation{Name: "test", Version: "1"})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("ListTools: %+v, %v", tools, err)
+	}
+	res, err := client.CallTool(ctx, "add", json.RawMessage(`{"a":4,"b":4}`))
+	if err != nil || res.Text() != "8" {
+		t.Fatalf("unexpected call result %+v, %v", res, err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	want := "session-1/" + ProtocolVersion
+	if len(sessions) != 5 || sessions[0] != want || sessions[3] != want || sessions[4] != "deleted:session-1" {
+		t.Fatalf("unexpected session headers: %v", sessions)
+	}
+
+	bad := NewHTTPTransport(server.URL, nil, nil)
+	if _, err := Connect(ctx, bad, Implementation{}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter/internal/sse"
+)
+
+// ErrClosed is returned for calls made after the transport was closed or
+// the server went away.
+var ErrClosed = errors.New("mcp: transport closed")
+
+// Transport carries JSON-RPC messages to a server. RoundTrip returns the
+// response to a request and nil for a notification.
+type Transport interface {
+	RoundTrip(ctx context.Context, msg *Message) (*Message, error)
+	Close() error
+}
+
+// StdioTransport talks to a server subprocess over newline-delimited JSON on
+// its stdin and stdout.
+type StdioTransport struct {
+	// ShutdownTimeout bounds how long Close waits for the server to exit
+	// after its stdin is closed before killing it. Zero means 5s.
+	ShutdownTimeout time.Duration
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	writeM sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan *Message
+	err     error
+	done    chan struct{}
+}
+
+// NewStdioTransport starts cmd and returns a transport bound to its stdio.
+// The process is stopped by Close.
+func NewStdioTransport(cmd *exec.Cmd) (*StdioTransport, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp: stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp: stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp: start server: %w", err)
+	}
+	t := &StdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[string]chan *Message{},
+		done:    make(chan struct{}),
+	}
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+func (t *StdioTransport) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.isResponse():
+			t.mu.Lock()
+			ch, ok := t.pending[string(msg.ID)]
+			delete(t.pending, string(msg.ID))
+			t.mu.Unlock()
+			if ok {
+				ch <- &msg
+			}
+		case msg.Method != "" && len(msg.ID) > 0:
+			_ = t.write(serverRequestReply(&msg))
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = ErrClosed
+	}
+	t.mu.Lock()
+	t.err = err
+	t.pending = map[string]chan *Message{}
+	t.mu.Unlock()
+	close(t.done)
+}
+
+// serverRequestReply answers a request sent by the server. Only ping is
+// supported.
+func serverRequestReply(req *Message) *Message {
+	reply := &Message{JSONRPC: jsonRPCVersion, ID: req.ID}
+	if req.Method == "ping" {
+		reply.Result = json.RawMessage("{}")
+		return reply
+	}
+	reply.Error = &RPCError{Code: -32601, Message: "method not found: " + req.Method}
+	return reply
+}
+
+func (t *StdioTransport) write(msg *Message) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeM.Lock()
+	defer t.writeM.Unlock()
+	_, err = t.stdin.Write(append(b, '\n'))
+	return err
+}
+
+func (t *StdioTransport) RoundTrip(ctx context.Context, msg *Message) (*Message, error) {
+	var ch chan *Message
+	if len(msg.ID) > 0 {
+		ch = make(chan *Message, 1)
+		t.mu.Lock()
+		if t.err != nil {
+			t.mu.Unlock()
+			return nil, t.err
+		}
+		t.pending[string(msg.ID)] = ch
+		t.mu.Unlock()
+	}
+	if err := t.write(msg); err != nil {
+		t.forget(msg.ID)
+		return nil, fmt.Errorf("mcp: write: %w", err)
+	}
+	if ch == nil {
+		return nil, nil
+	}
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-t.done:
+		return nil, t.err
+	case <-ctx.Done():
+		t.forget(msg.ID)
+		return nil, ctx.Err()
+	}
+}
+
+func (t *StdioTransport) forget(id json.RawMessage) {
+	t.mu.Lock()
+	delete(t.pending, string(id))
+	t.mu.Unlock()
+}
+
+// Close closes the server's stdin and waits for it to exit, killing it if
+// it outlives ShutdownTimeout.
+func (t *StdioTransport) Close() error {
+	err := t.stdin.Close()
+	timeout := t.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- t.cmd.Wait() }()
+	var waitErr error
+	select {
+	case waitErr = <-exited:
+	case <-time.After(timeout):
+		_ = t.cmd.Process.Kill()
+		<-exited
+		waitErr = fmt.Errorf("mcp: server did not exit within %s and was killed", timeout)
+	}
+	if err == nil {
+		err = waitErr
+	}
+	return err
+}
+
+// HTTPTransport talks to a server over the streamable HTTP transport. Each
+// message is POSTed to the endpoint; the reply is either a JSON body or an
+// event stream carrying the response.
+type HTTPTransport struct {
+	endpoint   string
+	httpClient *http.Client
+	header     http.Header
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+}
+
+// NewHTTPTransport returns a transport for endpoint. header is added to every
+// request, for example to carry an Authorization value; httpClient defaults
+// to http.DefaultClient.
+func NewHTTPTransport(endpoint string, httpClient *http.Client, header http.Header) *HTTPTransport {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HTTPTransport{endpoint: endpoint, httpClient: httpClient, header: header}
+}
+
+func (t *HTTPTransport) RoundTrip(ctx context.Context, msg *Message) (*Message, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: post: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("mcp: server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+	if len(msg.ID) == 0 {
+		return nil, nil
+	}
+
+	var res *Message
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		res, err = readStreamResponse(resp, msg.ID)
+	} else {
+		res = &Message{}
+		err = json.NewDecoder(resp.Body).Decode(res)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcp: read response: %w", err)
+	}
+	if msg.Method == "initialize" && res.Error == nil {
+		var init InitializeResult
+		if json.Unmarshal(res.Result, &init) == nil {
+			t.mu.Lock()
+			t.protocolVersion = init.ProtocolVersion
+			t.mu.Unlock()
+		}
+	}
+	return res, nil
+}
+
+func (t *HTTPTransport) setHeaders(req *http.Request) {
+	for key, values := range t.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", t.protocolVersion)
+	}
+}
+
+// readStreamResponse returns the response with the given id from an event
+// stream, skipping notifications and other messages.
+func readStreamResponse(resp *http.Response, id json.RawMessage) (*Message, error) {
+	events := sse.NewReader(resp)
+	for {
+		event, err := events.RecvEvent()
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		var msg Message
+		if err := json.Unmarshal(event.Data, &msg); err != nil {
+			continue
+		}
+		if msg.isResponse() && bytes.Equal(msg.ID, id) {
+			return &msg, nil
+		}
+	}
+}
+
+// Close ends the session on the server when one was established.
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req)
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
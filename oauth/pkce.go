@@ -0,0 +1,215 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	DefaultAuthURL          = "https://openrouter.ai/auth"
+	CodeChallengeMethodS256 = "S256"
+
+	defaultListenAddr   = "127.0.0.1:0"
+	defaultCallbackPath = "/callback"
+	defaultFlowTimeout  = 5 * time.Minute
+)
+
+var (
+	ErrStateMismatch   = errors.New("oauth: callback state mismatch")
+	ErrMissingCode     = errors.New("oauth: callback is missing code")
+	ErrOpenURLRequired = errors.New("oauth: FlowConfig.OpenURL is required")
+)
+
+// PKCE holds a code verifier and its S256 code challenge.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// NewPKCE generates a random code verifier and derives its S256 challenge.
+func NewPKCE() (PKCE, error) {
+	verifier, err := randomToken(32)
+	if err != nil {
+		return PKCE{}, err
+	}
+	return PKCE{
+		Verifier:  verifier,
+		Challenge: S256Challenge(verifier),
+		Method:    CodeChallengeMethodS256,
+	}, nil
+}
+
+// S256Challenge returns the base64url-encoded SHA-256 digest of verifier.
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthorizationURL builds the URL the user has to visit to authorize the app.
+// An empty authURL defaults to DefaultAuthURL.
+func AuthorizationURL(authURL, callbackURL string, pkce PKCE) (string, error) {
+	if authURL == "" {
+		authURL = DefaultAuthURL
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Set("callback_url", callbackURL)
+	if pkce.Challenge != "" {
+		query.Set("code_challenge", pkce.Challenge)
+		method := pkce.Method
+		if method == "" {
+			method = CodeChallengeMethodS256
+		}
+		query.Set("code_challenge_method", method)
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// FlowConfig configures RunPKCEFlow.
+type FlowConfig struct {
+	// OpenURL is called with the authorization URL, typically to open a browser
+	// or print the URL for the user. It must not block until the flow completes.
+	OpenURL func(authorizationURL string) error
+
+	AuthURL      string
+	ListenAddr   string
+	CallbackPath string
+	Timeout      time.Duration
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// RunPKCEFlow performs the complete PKCE authorization flow: it starts a
+// loopback callback listener, hands the authorization URL to cfg.OpenURL,
+// waits for the redirect carrying the code, validates state and exchanges the
+// code for an API key. Callback requests without a code, or with a state
+// other than the one sent, are rejected and the flow keeps waiting.
+func (c *Client) RunPKCEFlow(ctx context.Context, cfg FlowConfig) (string, error) {
+	if cfg.OpenURL == nil {
+		return "", ErrOpenURLRequired
+	}
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = defaultListenAddr
+	}
+	if cfg.CallbackPath == "" {
+		cfg.CallbackPath = defaultCallbackPath
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultFlowTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	pkce, err := NewPKCE()
+	if err != nil {
+		return "", err
+	}
+	state, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		return "", fmt.Errorf("oauth: start callback listener: %w", err)
+	}
+
+	results := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(cfg.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") == "" || query.Get("code") == "" && query.Get("error") == "":
+			// Requests that are not a redirect, such as a browser fetching
+			// the favicon, are answered without ending the flow.
+			http.Error(w, ErrMissingCode.Error(), http.StatusBadRequest)
+			return
+		case query.Get("state") != state:
+			// Any local process can reach the listener, so a forged
+			// redirect must not abort the login either.
+			http.Error(w, ErrStateMismatch.Error(), http.StatusBadRequest)
+			return
+		}
+		var res callbackResult
+		if query.Get("error") != "" {
+			res.err = fmt.Errorf("oauth: authorization failed: %s", query.Get("error"))
+		} else {
+			res.code = query.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete. You can close this window.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	callbackURL := (&url.URL{
+		Scheme:   "http",
+		Host:     listener.Addr().String(),
+		Path:     cfg.CallbackPath,
+		RawQuery: url.Values{"state": {state}}.Encode(),
+	}).String()
+	authorizationURL, err := AuthorizationURL(cfg.AuthURL, callbackURL, pkce)
+	if err != nil {
+		return "", err
+	}
+	if err := cfg.OpenURL(authorizationURL); err != nil {
+		return "", fmt.Errorf("oauth: open authorization url: %w", err)
+	}
+
+	var res callbackResult
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return "", fmt.Errorf("oauth: waiting for callback: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return "", res.err
+	}
+
+	exchanged, err := c.ExchangeCode(ctx, ExchangeAuthCodeRequest{
+		Code:                res.code,
+		CodeVerifier:        pkce.Verifier,
+		CodeChallengeMethod: pkce.Method,
+	})
+	if err != nil {
+		return "", err
+	}
+	return exchanged.Key, nil
+}
+
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("oauth: generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,131 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// exchangeBackend answers code exchanges with its function.
+type exchangeBackend func(req ExchangeAuthCodeRequest) (*ExchangeAuthCodeResponse, error)
+
+func (b exchangeBackend) CreateAuthCode(ctx context.Context, req CreateAuthCodeRequest) (*AuthCode, error) {
+	return nil, errors.New("unexpected auth code request")
+}
+
+func (b exchangeBackend) ExchangeAuthCodeForAPIKey(ctx context.Context, req ExchangeAuthCodeRequest) (*ExchangeAuthCodeResponse, error) {
+	if b == nil {
+		return nil, errors.New("unexpected code exchange")
+	}
+	return b(req)
+}
+
+func TestRunPKCEFlow(t *testing.T) {
+	var challenge string
+	api := New(exchangeBackend(func(req ExchangeAuthCodeRequest) (*ExchangeAuthCodeResponse, error) {
+		if req.Code != "code_1" || req.CodeChallengeMethod != CodeChallengeMethodS256 {
+			t.Errorf("unexpected exchange request: %+v", req)
+		}
+		if S256Challenge(req.CodeVerifier) != challenge {
+			t.Errorf("code verifier does not match challenge %q", challenge)
+		}
+		return &ExchangeAuthCodeResponse{Key: "sk-or-v1-pkce", UserID: "user_1"}, nil
+	}))
+
+	key, err := api.RunPKCEFlow(context.Background(), FlowConfig{
+		AuthURL: "https://example.com/auth",
+		Timeout: 5 * time.Second,
+		OpenURL: func(authorizationURL string) error {
+			parsed, err := url.Parse(authorizationURL)
+			if err != nil {
+				return err
+			}
+			challenge = parsed.Query().Get("code_challenge")
+			if parsed.Query().Get("code_challenge_method") != "S256" {
+				return fmt.Errorf("unexpected challenge method in %s", authorizationURL)
+			}
+			callback, err := url.Parse(parsed.Query().Get("callback_url"))
+			if err != nil {
+				return err
+			}
+			query := callback.Query()
+			// Stray requests without a code or state must not end the flow.
+			stray := *callback
+			stray.RawQuery = ""
+			strayState := *callback
+			query.Set("code", "code_1")
+			callback.RawQuery = query.Encode()
+			go func() {
+				for _, u := range []string{stray.String(), strayState.String(), callback.String()} {
+					resp, err := http.Get(u)
+					if err != nil {
+						t.Errorf("callback %s: %v", u, err)
+						return
+					}
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "sk-or-v1-pkce" {
+		t.Fatalf("unexpected key: %q", key)
+	}
+}
+
+func TestRunPKCEFlow_StateMismatch(t *testing.T) {
+	api := New(exchangeBackend(func(req ExchangeAuthCodeRequest) (*ExchangeAuthCodeResponse, error) {
+		if req.Code != "real" {
+			t.Errorf("exchanged the forged code %q", req.Code)
+		}
+		return &ExchangeAuthCodeResponse{Key: "sk-or-v1-pkce"}, nil
+	}))
+	key, err := api.RunPKCEFlow(context.Background(), FlowConfig{
+		Timeout: 5 * time.Second,
+		OpenURL: func(authorizationURL string) error {
+			parsed, _ := url.Parse(authorizationURL)
+			callback, _ := url.Parse(parsed.Query().Get("callback_url"))
+			forged, valid := *callback, *callback
+			forged.RawQuery = url.Values{"state": {"forged"}, "code": {"forged"}}.Encode()
+			query := callback.Query()
+			query.Set("code", "real")
+			valid.RawQuery = query.Encode()
+			go func() {
+				resp, err := http.Get(forged.String())
+				if err != nil {
+					t.Errorf("forged callback: %v", err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Errorf("forged callback answered %d", resp.StatusCode)
+				}
+				if resp, err = http.Get(valid.String()); err == nil {
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
+	})
+	if err != nil || key != "sk-or-v1-pkce" {
+		t.Fatalf("expected the valid redirect to complete the flow, got %q, %v", key, err)
+	}
+}
+
+func TestRunPKCEFlow_Timeout(t *testing.T) {
+	api := New(exchangeBackend(nil))
+	_, err := api.RunPKCEFlow(context.Background(), FlowConfig{
+		Timeout: 50 * time.Millisecond,
+		OpenURL: func(string) error { return nil },
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,437 @@
+// Package reconcile fetches generation stats in the background. OpenRouter
+// answers /generation with 404 until the stats of a completion are ready, so
+// a Reconciler polls with backoff and delivers each Generation once it is
+// available, off the request path.
+package reconcile
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+// ErrClosed is the error of results abandoned by Close and of submissions
+// after Close.
+var ErrClosed = errors.New("reconcile: reconciler closed")
+
+type backend interface {
+	GetGeneration(ctx context.Context, id string) (*gopenrouter.Generation, error)
+}
+
+// Result is the outcome for one generation ID. Err is set when the stats
+// could not be fetched: a non-retryable error, the last error after MaxWait,
+// or ErrClosed.
+type Result struct {
+	ID         string
+	Generation *gopenrouter.Generation
+	Err        error
+	Attempts   int
+	// Elapsed is the time from submission to the result.
+	Elapsed time.Duration
+}
+
+// Config configures a Reconciler. Results go to OnResult, else to OnBatch,
+// else to the Results channel.
+type Config struct {
+	// Concurrency bounds the requests in flight. Zero means 4.
+	Concurrency int
+	// InitialDelay is the wait before the first poll. Zero means 500ms.
+	InitialDelay time.Duration
+	// MaxDelay caps the backoff between polls. Zero means 30s.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each retryable error. Zero means 2.
+	Multiplier float64
+	// MaxWait is how long an ID is polled before giving up. Zero means 5m.
+	MaxWait time.Duration
+	// Remember is how many finished IDs are kept for de-duplication. Zero
+	// means 10000.
+	Remember int
+
+	// OnResult is called for every result from the worker goroutines, so
+	// calls may be concurrent.
+	OnResult func(Result)
+	// OnBatch is called with up to BatchSize results, at least every
+	// FlushInterval while results are waiting. Calls are serialized.
+	OnBatch       func([]Result)
+	BatchSize     int           // zero means 50
+	FlushInterval time.Duration // zero means 1s
+	// Buffer is the capacity of the Results channel.
+	Buffer int
+}
+
+type item struct {
+	id        string
+	submitted time.Time
+	due       time.Time
+	attempts  int
+	delay     time.Duration
+}
+
+// Reconciler polls generation stats for submitted IDs with bounded
+// concurrency. IDs that are pending or recently finished are ignored, so
+// the same ID can be submitted from several places.
+type Reconciler struct {
+	backend backend
+	config  Config
+	ctx     context.Context
+	cancel  context.CancelFunc
+	work    chan *item
+	wake    chan struct{}
+	results chan Result
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	queue    itemQueue
+	pending  map[string]bool
+	done     map[string]bool
+	finished []string
+	closed   bool
+	drained  chan struct{}
+	stop     sync.Once
+	// giveUp is closed by Close once it stops waiting, so sends to an
+	// unread Results channel are dropped instead of blocking.
+	giveUp chan struct{}
+
+	batchMu sync.Mutex
+	batch   []Result
+	flushed chan struct{}
+}
+
+// New starts a reconciler; Close stops it.
+func New(backend backend, config Config) *Reconciler {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = 500 * time.Millisecond
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 30 * time.Second
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = 2
+	}
+	if config.MaxWait <= 0 {
+		config.MaxWait = 5 * time.Minute
+	}
+	if config.Remember <= 0 {
+		config.Remember = 10000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Reconciler{
+		backend: backend,
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		work:    make(chan *item),
+		wake:    make(chan struct{}, 1),
+		pending: make(map[string]bool),
+		done:    make(map[string]bool),
+		drained: make(chan struct{}),
+		giveUp:  make(chan struct{}),
+		flushed: make(chan struct{}),
+	}
+	if config.OnResult == nil && config.OnBatch == nil {
+		r.results = make(chan Result, config.Buffer)
+	}
+	r.wg.Add(1 + config.Concurrency)
+	go r.dispatch()
+	for range config.Concurrency {
+		go r.worker()
+	}
+	if config.OnResult == nil && config.OnBatch != nil {
+		go r.flushLoop()
+	} else {
+		close(r.flushed)
+	}
+	return r
+}
+
+// Results delivers results when neither OnResult nor OnBatch is set; it is
+// nil otherwise. It must be drained and is closed by Close. Results that
+// nobody receives once Close stops waiting are dropped.
+func (r *Reconciler) Results() <-chan Result {
+	return r.results
+}
+
+// Submit queues generation IDs: a chat completion's or stream chunk's ID,
+// or a Responses ID. It returns how many were new.
+func (r *Reconciler) Submit(ids ...string) (int, error) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrClosed
+	}
+	n := 0
+	for _, id := range ids {
+		if id == "" || r.pending[id] || r.done[id] {
+			continue
+		}
+		r.pending[id] = true
+		heap.Push(&r.queue, &item{id: id, submitted: now, due: now.Add(r.config.InitialDelay), delay: r.config.InitialDelay})
+		n++
+	}
+	if n > 0 {
+		r.signal()
+	}
+	return n, nil
+}
+
+// Pending returns the number of IDs queued or in flight.
+func (r *Reconciler) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+func (r *Reconciler) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops accepting IDs and waits until every pending ID has a result
+// or ctx is done; IDs still pending then get ErrClosed. Batches are flushed
+// and the Results channel is closed before it returns. If the channel is not
+// being drained, Close returns once ctx is done and the results it could
+// not deliver are dropped.
+func (r *Reconciler) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		if len(r.pending) == 0 {
+			close(r.drained)
+		}
+	}
+	r.mu.Unlock()
+
+	var err error
+	select {
+	case <-r.drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	r.stop.Do(func() { r.shutdown() })
+	return err
+}
+
+func (r *Reconciler) shutdown() {
+	close(r.giveUp)
+	r.cancel()
+	r.wg.Wait()
+	<-r.flushed
+
+	r.mu.Lock()
+	abandoned := make([]*item, 0, len(r.queue))
+	for len(r.queue) > 0 {
+		abandoned = append(abandoned, heap.Pop(&r.queue).(*item))
+	}
+	r.mu.Unlock()
+	for _, it := range abandoned {
+		r.finish(it, nil, ErrClosed)
+	}
+	if r.config.OnBatch != nil {
+		r.batchMu.Lock()
+		r.flushLocked()
+		r.batchMu.Unlock()
+	}
+	if r.results != nil {
+		close(r.results)
+	}
+}
+
+func (r *Reconciler) dispatch() {
+	defer r.wg.Done()
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		r.mu.Lock()
+		var (
+			next *item
+			wait = time.Hour
+		)
+		if len(r.queue) > 0 {
+			if d := time.Until(r.queue[0].due); d <= 0 {
+				next = heap.Pop(&r.queue).(*item)
+			} else {
+				wait = d
+			}
+		}
+		r.mu.Unlock()
+
+		if next != nil {
+			select {
+			case r.work <- next:
+			case <-r.ctx.Done():
+				r.requeue(next)
+				return
+			}
+			continue
+		}
+		timer.Reset(wait)
+		select {
+		case <-r.wake:
+		case <-timer.C:
+		case <-r.ctx.Done():
+			return
+		}
+		timer.Stop()
+	}
+}
+
+func (r *Reconciler) requeue(it *item) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	heap.Push(&r.queue, it)
+	r.signal()
+}
+
+func (r *Reconciler) worker() {
+	defer r.wg.Done()
+	for {
+		var it *item
+		select {
+		case it = <-r.work:
+		case <-r.ctx.Done():
+			return
+		}
+		gen, err := r.backend.GetGeneration(r.ctx, it.id)
+		if err != nil && r.ctx.Err() != nil {
+			// Close gave up; it reports the ID as abandoned.
+			r.requeue(it)
+			return
+		}
+		it.attempts++
+		if err != nil && Retryable(err) {
+			it.delay = min(time.Duration(float64(it.delay)*r.config.Multiplier), r.config.MaxDelay)
+			delay := shared.Jitter(it.delay)
+			if time.Since(it.submitted)+delay <= r.config.MaxWait {
+				it.due = time.Now().Add(delay)
+				r.requeue(it)
+				continue
+			}
+		}
+		r.finish(it, gen, err)
+	}
+}
+
+func (r *Reconciler) finish(it *item, gen *gopenrouter.Generation, err error) {
+	r.deliver(Result{ID: it.id, Generation: gen, Err: err, Attempts: it.attempts, Elapsed: time.Since(it.submitted)})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, it.id)
+	if err == nil {
+		r.done[it.id] = true
+		r.finished = append(r.finished, it.id)
+		if len(r.finished) > r.config.Remember {
+			delete(r.done, r.finished[0])
+			r.finished = r.finished[1:]
+		}
+	}
+	if r.closed && len(r.pending) == 0 {
+		select {
+		case <-r.drained:
+		default:
+			close(r.drained)
+		}
+	}
+}
+
+func (r *Reconciler) deliver(res Result) {
+	switch {
+	case r.config.OnResult != nil:
+		r.config.OnResult(res)
+	case r.config.OnBatch != nil:
+		r.batchMu.Lock()
+		defer r.batchMu.Unlock()
+		r.batch = append(r.batch, res)
+		if len(r.batch) >= r.config.BatchSize {
+			r.flushLocked()
+		}
+	default:
+		// Prefer delivery when a receiver is ready, even after giving up.
+		select {
+		case r.results <- res:
+			return
+		default:
+		}
+		select {
+		case r.results <- res:
+		case <-r.giveUp:
+		}
+	}
+}
+
+func (r *Reconciler) flushLocked() {
+	if len(r.batch) == 0 {
+		return
+	}
+	batch := r.batch
+	r.batch = nil
+	r.config.OnBatch(batch)
+}
+
+func (r *Reconciler) flushLoop() {
+	defer close(r.flushed)
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.ctx.Done():
+			return
+		}
+		r.batchMu.Lock()
+		r.flushLocked()
+		r.batchMu.Unlock()
+	}
+}
+
+// Retryable reports whether a GetGeneration error is worth polling again:
+// not found yet, rate limited, a server error or a transport failure.
+func Retryable(err error) bool {
+	status := shared.StatusCode(err)
+	var apiErr *shared.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(status)
+	}
+	var reqErr *shared.RequestError
+	if errors.As(err, &reqErr) {
+		return status == 0 || retryableStatus(status)
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusNotFound || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= http.StatusInternalServerError
+}
+
+// itemQueue is a min-heap of items by due time.
+type itemQueue []*item
+
+func (q itemQueue) Len() int           { return len(q) }
+func (q itemQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+func (q itemQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *itemQueue) Push(x any)        { *q = append(*q, x.(*item)) }
+
+func (q *itemQueue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,245 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func fastConfig() Config {
+	return Config{InitialDelay: 2 * time.Millisecond, MaxDelay: 10 * time.Millisecond, MaxWait: time.Second}
+}
+
+func TestReconcilerPollsUntilAvailable(t *testing.T) {
+	var mu sync.Mutex
+	polls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		mu.Lock()
+		polls[id]++
+		n := polls[id]
+		mu.Unlock()
+		switch {
+		case id == "gen-denied":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"No auth credentials found","code":401}}`)
+		case n < 3:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":{"message":"Generation %s not found","code":404}}`, id)
+		default:
+			fmt.Fprintf(w, `{"data":{"id":%q,"total_cost":0.0021,"cache_discount":0.0004,"latency":812,"native_tokens_prompt":1200,"provider_responses":[{"provider_name":"Anthropic","status":200}]}}`, id)
+		}
+	}))
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+
+	r := New(gopenrouter.NewClientWithConfig(cfg), fastConfig())
+	if n, err := r.Submit("gen-1", "gen-denied", "gen-1", ""); err != nil || n != 2 {
+		t.Fatalf("Submit: %d, %v", n, err)
+	}
+	results := map[string]Result{}
+	for range 2 {
+		res := <-r.Results()
+		results[res.ID] = res
+	}
+	if n, _ := r.Submit("gen-1"); n != 0 {
+		t.Fatal("finished IDs should be de-duplicated")
+	}
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, ok := <-r.Results(); ok {
+		t.Fatal("Results should be closed")
+	}
+
+	got := results["gen-1"]
+	if got.Err != nil || got.Attempts != 3 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	gen := got.Generation
+	if gen.NativePromptTokens != 1200 || gen.TotalCost != 0.0021 || gen.CacheDiscount != 0.0004 || *gen.Latency != 812 || gen.ProviderResponses[0].ProviderName != "Anthropic" {
+		t.Fatalf("unexpected generation: %+v", gen)
+	}
+	denied := results["gen-denied"]
+	if denied.Err == nil || denied.Attempts != 1 || Retryable(denied.Err) {
+		t.Fatalf("non-retryable errors must not be polled again: %+v", denied)
+	}
+}
+
+// flakyBackend answers 404 on the first poll of every ID and tracks the
+// peak number of concurrent calls.
+type flakyBackend struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	always   bool
+}
+
+func (b *flakyBackend) GetGeneration(ctx context.Context, id string) (*gopenrouter.Generation, error) {
+	n := b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(time.Millisecond):
+	case <-ctx.Done():
+		return nil, &shared.RequestError{Err: ctx.Err()}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.always || !b.seen[id] {
+		b.seen[id] = true
+		return nil, &shared.APIError{Message: "not found", Code: float64(404)}
+	}
+	return &gopenrouter.Generation{ID: id}, nil
+}
+
+func TestReconcilerBatchesWithBoundedConcurrency(t *testing.T) {
+	backend := &flakyBackend{seen: map[string]bool{}}
+	var batches [][]Result
+	config := fastConfig()
+	config.Concurrency = 3
+	config.BatchSize = 5
+	config.FlushInterval = time.Hour
+	config.OnBatch = func(batch []Result) { batches = append(batches, batch) }
+	r := New(backend, config)
+
+	var ids []string
+	for i := range 22 {
+		ids = append(ids, fmt.Sprintf("gen-%d", i))
+	}
+	if n, err := r.Submit(ids...); err != nil || n != len(ids) {
+		t.Fatalf("Submit: %d, %v", n, err)
+	}
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	delivered := map[string]bool{}
+	for i, batch := range batches {
+		if len(batch) > 5 || (i < len(batches)-1 && len(batch) != 5) {
+			t.Fatalf("unexpected batch sizes: %d", len(batch))
+		}
+		for _, res := range batch {
+			if res.Err != nil || res.Attempts != 2 {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+			delivered[res.ID] = true
+		}
+	}
+	if len(delivered) != len(ids) {
+		t.Fatalf("delivered %d of %d", len(delivered), len(ids))
+	}
+	if peak := backend.peak.Load(); peak > 3 {
+		t.Fatalf("concurrency exceeded: %d", peak)
+	}
+}
+
+func TestReconcilerGivesUp(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		results []Result
+	)
+	config := fastConfig()
+	config.MaxWait = 30 * time.Millisecond
+	config.OnResult = func(res Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, res)
+	}
+	r := New(&flakyBackend{seen: map[string]bool{}, always: true}, config)
+	r.Submit("gen-missing")
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if len(results) != 1 || !Retryable(results[0].Err) || results[0].Attempts < 2 || results[0].Elapsed > time.Second {
+		t.Fatalf("expected the last 404 after MaxWait, got %+v", results)
+	}
+}
+
+func TestReconcilerCloseAbandons(t *testing.T) {
+	config := fastConfig()
+	config.MaxWait = time.Hour
+	config.Buffer = 10
+	r := New(&flakyBackend{seen: map[string]bool{}, always: true}, config)
+	r.Submit("gen-a", "gen-b")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+	var abandoned int
+	for res := range r.Results() {
+		if !errors.Is(res.Err, ErrClosed) {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		abandoned++
+	}
+	if abandoned != 2 || r.Pending() != 0 {
+		t.Fatalf("expected both IDs abandoned, got %d (pending %d)", abandoned, r.Pending())
+	}
+	if _, err := r.Submit("gen-c"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestReconcilerCloseWithUnreadResults(t *testing.T) {
+	config := fastConfig()
+	r := New(&flakyBackend{seen: map[string]bool{}}, config)
+	r.Submit("gen-a", "gen-b", "gen-c")
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() { closed <- r.Close(ctx) }()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on an unread Results channel")
+	}
+	for range r.Results() {
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{&shared.APIError{Code: float64(404)}, true},
+		{&shared.APIError{Code: "429"}, true},
+		{&shared.APIError{Code: float64(400)}, false},
+		{&shared.RequestError{HTTPStatusCode: 502}, true},
+		{&shared.RequestError{HTTPStatusCode: 403}, false},
+		{&shared.RequestError{Err: errors.New("connection reset")}, true},
+		{fmt.Errorf("wrapped: %w", &shared.APIError{Code: float64(503)}), true},
+		{errors.New("other"), false},
+	} {
+		if got := Retryable(tc.err); got != tc.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
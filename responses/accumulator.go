@@ -0,0 +1,313 @@
+package responses
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrResponseFailed     = errors.New("responses: response failed")
+	ErrResponseIncomplete = errors.New("responses: response incomplete")
+)
+
+// SequenceGapError reports that one or more stream events were skipped.
+type SequenceGapError struct {
+	Expected int
+	Got      int
+}
+
+func (e *SequenceGapError) Error() string {
+	return fmt.Sprintf("responses: stream sequence gap: expected %d, got %d", e.Expected, e.Got)
+}
+
+// Accumulator assembles a complete Response from stream events.
+type Accumulator struct {
+	response Response
+	lastSeq  int
+	started  bool
+	done     bool
+}
+
+func NewAccumulator() *Accumulator {
+	return &Accumulator{}
+}
+
+// Add applies event to the response being assembled. An event whose output,
+// content or summary index is negative or more than one past the end is
+// rejected with an error. Otherwise the event is applied, and a
+// *SequenceGapError is returned if events before it were missed.
+func (a *Accumulator) Add(event Event) error {
+	var gap error
+	if seq := event.Sequence(); a.started && seq != a.lastSeq+1 && seq > a.lastSeq {
+		gap = &SequenceGapError{Expected: a.lastSeq + 1, Got: seq}
+	}
+	if !a.started || event.Sequence() > a.lastSeq {
+		a.lastSeq = event.Sequence()
+	}
+	a.started = true
+
+	switch e := event.(type) {
+	case ResponseCreatedEvent:
+		a.snapshot(e.Response)
+	case ResponseInProgressEvent:
+		a.snapshot(e.Response)
+	case ResponseCompletedEvent:
+		a.snapshot(e.Response)
+		a.done = true
+	case ResponseFailedEvent:
+		a.snapshot(e.Response)
+		if a.response.Status == "" {
+			a.response.Status = "failed"
+		}
+		a.done = true
+	case ResponseIncompleteEvent:
+		a.snapshot(e.Response)
+		if a.response.Status == "" {
+			a.response.Status = "incomplete"
+		}
+		a.done = true
+	case OutputItemAddedEvent:
+		return a.setItem(e.OutputIndex, e.Item, gap)
+	case OutputItemDoneEvent:
+		return a.setItem(e.OutputIndex, e.Item, gap)
+	case ContentPartAddedEvent:
+		return a.setContent(e.OutputIndex, e.ContentIndex, e.Part, gap)
+	case ContentPartDoneEvent:
+		return a.setContent(e.OutputIndex, e.ContentIndex, e.Part, gap)
+	case OutputTextDeltaEvent:
+		return a.appendText(e.OutputIndex, e.ContentIndex, "output_text", e.Delta, gap)
+	case OutputTextDoneEvent:
+		return a.setText(e.OutputIndex, e.ContentIndex, "output_text", e.Text, gap)
+	case OutputTextAnnotationAddedEvent:
+		part, err := a.content(e.OutputIndex, e.ContentIndex)
+		if err != nil {
+			return err
+		}
+		if e.AnnotationIndex < 0 || e.AnnotationIndex > len(part.Annotations) {
+			return fmt.Errorf("responses: annotation index %d out of range", e.AnnotationIndex)
+		}
+		if e.AnnotationIndex == len(part.Annotations) {
+			part.Annotations = append(part.Annotations, Annotation{})
+		}
+		if e.Annotation != nil {
+			part.Annotations[e.AnnotationIndex] = *e.Annotation
+		}
+	case RefusalDeltaEvent:
+		part, err := a.content(e.OutputIndex, e.ContentIndex)
+		if err != nil {
+			return err
+		}
+		part.setType("refusal")
+		part.Refusal += e.Delta
+	case RefusalDoneEvent:
+		part, err := a.content(e.OutputIndex, e.ContentIndex)
+		if err != nil {
+			return err
+		}
+		part.setType("refusal")
+		part.Refusal = e.Refusal
+	case FunctionCallArgumentsDeltaEvent:
+		item, err := a.item(e.OutputIndex)
+		if err != nil {
+			return err
+		}
+		item.Arguments += e.Delta
+	case FunctionCallArgumentsDoneEvent:
+		item, err := a.item(e.OutputIndex)
+		if err != nil {
+			return err
+		}
+		item.Arguments = e.Arguments
+		if e.Name != "" {
+			item.Name = e.Name
+		}
+	case ReasoningTextDeltaEvent:
+		return a.appendText(e.OutputIndex, e.ContentIndex, "reasoning_text", e.Delta, gap)
+	case ReasoningTextDoneEvent:
+		return a.setText(e.OutputIndex, e.ContentIndex, "reasoning_text", e.Text, gap)
+	case ReasoningSummaryPartAddedEvent:
+		return a.setSummary(e.OutputIndex, e.SummaryIndex, e.Part, gap)
+	case ReasoningSummaryPartDoneEvent:
+		return a.setSummary(e.OutputIndex, e.SummaryIndex, e.Part, gap)
+	case ReasoningSummaryTextDeltaEvent:
+		part, err := a.summary(e.OutputIndex, e.SummaryIndex)
+		if err != nil {
+			return err
+		}
+		part.setType("summary_text")
+		part.Text += e.Delta
+	case ReasoningSummaryTextDoneEvent:
+		part, err := a.summary(e.OutputIndex, e.SummaryIndex)
+		if err != nil {
+			return err
+		}
+		part.setType("summary_text")
+		part.Text = e.Text
+	case ErrorEvent:
+		a.response.Status = "failed"
+		a.response.Error = e.Error
+		a.done = true
+	}
+	return gap
+}
+
+func (a *Accumulator) setItem(index int, item *OutputItem, gap error) error {
+	dst, err := a.item(index)
+	if err != nil {
+		return err
+	}
+	if item != nil {
+		*dst = *item
+	}
+	return gap
+}
+
+func (a *Accumulator) setContent(outputIndex, contentIndex int, part *ContentPart, gap error) error {
+	dst, err := a.content(outputIndex, contentIndex)
+	if err != nil {
+		return err
+	}
+	if part != nil {
+		*dst = *part
+	}
+	return gap
+}
+
+func (a *Accumulator) setSummary(outputIndex, summaryIndex int, part *ContentPart, gap error) error {
+	dst, err := a.summary(outputIndex, summaryIndex)
+	if err != nil {
+		return err
+	}
+	if part != nil {
+		*dst = *part
+	}
+	return gap
+}
+
+func (a *Accumulator) appendText(outputIndex, contentIndex int, partType, delta string, gap error) error {
+	part, err := a.content(outputIndex, contentIndex)
+	if err != nil {
+		return err
+	}
+	part.setType(partType)
+	part.Text += delta
+	return gap
+}
+
+func (a *Accumulator) setText(outputIndex, contentIndex int, partType, text string, gap error) error {
+	part, err := a.content(outputIndex, contentIndex)
+	if err != nil {
+		return err
+	}
+	part.setType(partType)
+	part.Text = text
+	return gap
+}
+
+// snapshot copies response-level fields, keeping the accumulated output when
+// the snapshot carries none.
+func (a *Accumulator) snapshot(res *Response) {
+	if res == nil {
+		return
+	}
+	output := a.response.Output
+	a.response = *res
+	if len(a.response.Output) == 0 {
+		a.response.Output = output
+	}
+}
+
+// item returns the output item at index, appending it if index is one past
+// the end. The item's Raw map is dropped because it no longer reflects the
+// item.
+func (a *Accumulator) item(index int) (*OutputItem, error) {
+	if index < 0 || index > len(a.response.Output) {
+		return nil, fmt.Errorf("responses: output index %d out of range", index)
+	}
+	if index == len(a.response.Output) {
+		a.response.Output = append(a.response.Output, OutputItem{})
+	}
+	item := &a.response.Output[index]
+	item.Raw = nil
+	return item, nil
+}
+
+func (a *Accumulator) content(outputIndex, contentIndex int) (*ContentPart, error) {
+	// Check both indexes before growing anything.
+	n := 0
+	if outputIndex >= 0 && outputIndex < len(a.response.Output) {
+		n = len(a.response.Output[outputIndex].Content)
+	}
+	if contentIndex < 0 || contentIndex > n {
+		return nil, fmt.Errorf("responses: content index %d out of range", contentIndex)
+	}
+	item, err := a.item(outputIndex)
+	if err != nil {
+		return nil, err
+	}
+	if contentIndex == len(item.Content) {
+		item.Content = append(item.Content, ContentPart{})
+	}
+	part := &item.Content[contentIndex]
+	part.Raw = nil
+	return part, nil
+}
+
+func (a *Accumulator) summary(outputIndex, summaryIndex int) (*ContentPart, error) {
+	// Check both indexes before growing anything.
+	n := 0
+	if outputIndex >= 0 && outputIndex < len(a.response.Output) {
+		n = len(a.response.Output[outputIndex].Summary)
+	}
+	if summaryIndex < 0 || summaryIndex > n {
+		return nil, fmt.Errorf("responses: summary index %d out of range", summaryIndex)
+	}
+	item, err := a.item(outputIndex)
+	if err != nil {
+		return nil, err
+	}
+	if summaryIndex == len(item.Summary) {
+		item.Summary = append(item.Summary, ContentPart{})
+	}
+	part := &item.Summary[summaryIndex]
+	part.Raw = nil
+	return part, nil
+}
+
+func (p *ContentPart) setType(partType string) {
+	if p.Type == "" {
+		p.Type = partType
+	}
+}
+
+// Response returns the response assembled so far.
+func (a *Accumulator) Response() *Response {
+	res := a.response
+	return &res
+}
+
+func (a *Accumulator) Usage() *Usage {
+	return a.response.Usage
+}
+
+// Done reports whether a terminal event has been seen.
+func (a *Accumulator) Done() bool {
+	return a.done
+}
+
+// Err returns an error if the response failed or is incomplete.
+func (a *Accumulator) Err() error {
+	switch a.response.Status {
+	case "failed":
+		if a.response.Error != nil {
+			return fmt.Errorf("%w: %w", ErrResponseFailed, a.response.Error)
+		}
+		return ErrResponseFailed
+	case "incomplete":
+		if reason, _ := a.response.IncompleteDetails["reason"].(string); reason != "" {
+			return fmt.Errorf("%w: %s", ErrResponseIncomplete, reason)
+		}
+		return ErrResponseIncomplete
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package responses
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// eventStream returns a stream that yields events as server-sent events.
+func eventStream(events ...string) *Stream {
+	var body strings.Builder
+	for _, event := range events {
+		body.WriteString("data: " + event + "\n\n")
+	}
+	return NewStream(&http.Response{Body: io.NopCloser(strings.NewReader(body.String()))})
+}
+
+func accumulate(t *testing.T, stream *Stream) (*Accumulator, []error) {
+	t.Helper()
+	acc := NewAccumulator()
+	var gaps []error
+	for {
+		event, err := stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			return acc, gaps
+		}
+		if err != nil {
+			t.Fatalf("unexpected recv error: %v", err)
+		}
+		if err := acc.Add(event); err != nil {
+			gaps = append(gaps, err)
+		}
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	stream := eventStream(
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress"}}`,
+		`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"type":"reasoning","id":"rs_1","summary":[]}}`,
+		`{"type":"response.reasoning_summary_part.added","sequence_number":2,"output_index":0,"item_id":"rs_1","summary_index":0,"part":{"type":"summary_text","text":""}}`,
+		`{"type":"response.reasoning_summary_text.delta","sequence_number":3,"output_index":0,"item_id":"rs_1","summary_index":0,"delta":"Think"}`,
+		`{"type":"response.reasoning_summary_text.delta","sequence_number":4,"output_index":0,"item_id":"rs_1","summary_index":0,"delta":"ing."}`,
+		`{"type":"response.output_item.added","sequence_number":5,"output_index":1,"item":{"type":"message","id":"msg_1","role":"assistant","content":[]}}`,
+		`{"type":"response.content_part.added","sequence_number":6,"output_index":1,"item_id":"msg_1","content_index":0,"part":{"type":"output_text","text":""}}`,
+		`{"type":"response.output_text.delta","sequence_number":7,"output_index":1,"item_id":"msg_1","content_index":0,"delta":"Hello"}`,
+		`{"type":"response.output_text.delta","sequence_number":8,"output_index":1,"item_id":"msg_1","content_index":0,"delta":" world"}`,
+		`{"type":"response.output_text.annotation.added","sequence_number":9,"output_index":1,"item_id":"msg_1","content_index":0,"annotation_index":0,"annotation":{"type":"url_citation","url":"https://example.com"}}`,
+		`{"type":"response.output_item.added","sequence_number":10,"output_index":2,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"lookup","arguments":""}}`,
+		`{"type":"response.function_call_arguments.delta","sequence_number":11,"output_index":2,"item_id":"fc_1","delta":"{\"q\":"}`,
+		`{"type":"response.function_call_arguments.delta","sequence_number":12,"output_index":2,"item_id":"fc_1","delta":"\"x\"}"}`,
+		`{"type":"response.completed","sequence_number":13,"response":{"id":"resp_1","status":"completed","usage":{"input_tokens":10,"output_tokens":5,"total_tokens":15}}}`,
+	)
+
+	acc, gaps := accumulate(t, stream)
+	if len(gaps) != 0 {
+		t.Fatalf("unexpected gaps: %v", gaps)
+	}
+	if !acc.Done() || acc.Err() != nil {
+		t.Fatalf("expected completed response, done=%v err=%v", acc.Done(), acc.Err())
+	}
+	res := acc.Response()
+	if res.ID != "resp_1" || len(res.Output) != 3 {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+	if res.Output[0].Summary[0].Text != "Thinking." {
+		t.Fatalf("unexpected reasoning summary: %+v", res.Output[0])
+	}
+	text := res.Output[1].Content[0]
+	if text.Text != "Hello world" || len(text.Annotations) != 1 || text.Annotations[0].URL != "https://example.com" {
+		t.Fatalf("unexpected message content: %+v", text)
+	}
+	if call := res.Output[2]; call.Name != "lookup" || call.CallID != "call_1" || call.Arguments != `{"q":"x"}` {
+		t.Fatalf("unexpected function call: %+v", call)
+	}
+	if usage := acc.Usage(); usage == nil || usage.TotalTokens != 15 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestAccumulator_IncompleteAndGap(t *testing.T) {
+	stream := eventStream(
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress"}}`,
+		`{"type":"response.output_text.delta","sequence_number":1,"output_index":0,"content_index":0,"delta":"Hel"}`,
+		`{"type":"response.output_text.delta","sequence_number":3,"output_index":0,"content_index":0,"delta":"lo"}`,
+		`{"type":"response.incomplete","sequence_number":4,"response":{"id":"resp_1","status":"incomplete","incomplete_details":{"reason":"max_output_tokens"}}}`,
+	)
+
+	acc, gaps := accumulate(t, stream)
+	var gap *SequenceGapError
+	if len(gaps) != 1 || !errors.As(gaps[0], &gap) || gap.Expected != 2 || gap.Got != 3 {
+		t.Fatalf("expected one sequence gap, got %v", gaps)
+	}
+	if !errors.Is(acc.Err(), ErrResponseIncomplete) {
+		t.Fatalf("expected incomplete error, got %v", acc.Err())
+	}
+	if text := acc.Response().Output[0].Content[0].Text; text != "Hello" {
+		t.Fatalf("unexpected partial text: %q", text)
+	}
+}
+
+func TestAccumulator_BadIndexes(t *testing.T) {
+	acc := NewAccumulator()
+	for i, event := range []Event{
+		OutputTextDeltaEvent{OutputIndex: -1, Delta: "x"},
+		OutputTextDeltaEvent{OutputIndex: 1 << 30, Delta: "x"},
+		OutputTextDeltaEvent{OutputIndex: 0, ContentIndex: -1, Delta: "x"},
+		ReasoningSummaryTextDeltaEvent{OutputIndex: 0, SummaryIndex: 5, Delta: "x"},
+	} {
+		if err := acc.Add(event); err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Fatalf("event %d: expected an out of range error, got %v", i, err)
+		}
+	}
+	if len(acc.Response().Output) != 0 {
+		t.Fatalf("rejected events changed the output: %+v", acc.Response().Output)
+	}
+	if err := acc.Add(OutputTextDeltaEvent{OutputIndex: 0, ContentIndex: 0, Delta: "ok"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if output := acc.Response().Output; len(output) != 1 || output[0].Content[0].Text != "ok" {
+		t.Fatalf("unexpected output: %+v", output)
+	}
+}
@@ -0,0 +1,253 @@
+package responses
+
+// Event is a typed Responses stream event. Decoding yields one of the
+// concrete types in this file; unrecognized event types decode to
+// UnknownEvent.
+type Event interface {
+	EventType() string
+	Sequence() int
+	isEvent()
+}
+
+type EventHeader struct {
+	Type           string
+	SequenceNumber int
+}
+
+func (h EventHeader) EventType() string { return h.Type }
+func (h EventHeader) Sequence() int     { return h.SequenceNumber }
+func (EventHeader) isEvent()            {}
+
+// ResponseCreatedEvent, ResponseInProgressEvent and the terminal
+// ResponseCompletedEvent, ResponseFailedEvent and ResponseIncompleteEvent
+// carry a snapshot of the response.
+type ResponseCreatedEvent struct {
+	EventHeader
+	Response *Response
+}
+
+type ResponseInProgressEvent struct {
+	EventHeader
+	Response *Response
+}
+
+type ResponseCompletedEvent struct {
+	EventHeader
+	Response *Response
+}
+
+type ResponseFailedEvent struct {
+	EventHeader
+	Response *Response
+}
+
+type ResponseIncompleteEvent struct {
+	EventHeader
+	Response *Response
+}
+
+type OutputItemAddedEvent struct {
+	EventHeader
+	OutputIndex int
+	Item        *OutputItem
+}
+
+type OutputItemDoneEvent struct {
+	EventHeader
+	OutputIndex int
+	Item        *OutputItem
+}
+
+type ContentPartAddedEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Part         *ContentPart
+}
+
+type ContentPartDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Part         *ContentPart
+}
+
+type OutputTextDeltaEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Delta        string
+}
+
+type OutputTextDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Text         string
+}
+
+type OutputTextAnnotationAddedEvent struct {
+	EventHeader
+	ItemID          string
+	OutputIndex     int
+	ContentIndex    int
+	AnnotationIndex int
+	Annotation      *Annotation
+}
+
+type RefusalDeltaEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Delta        string
+}
+
+type RefusalDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Refusal      string
+}
+
+type FunctionCallArgumentsDeltaEvent struct {
+	EventHeader
+	ItemID      string
+	OutputIndex int
+	Delta       string
+}
+
+type FunctionCallArgumentsDoneEvent struct {
+	EventHeader
+	ItemID      string
+	OutputIndex int
+	Name        string
+	Arguments   string
+}
+
+type ReasoningTextDeltaEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Delta        string
+}
+
+type ReasoningTextDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	ContentIndex int
+	Text         string
+}
+
+type ReasoningSummaryPartAddedEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	SummaryIndex int
+	Part         *ContentPart
+}
+
+type ReasoningSummaryPartDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	SummaryIndex int
+	Part         *ContentPart
+}
+
+type ReasoningSummaryTextDeltaEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	SummaryIndex int
+	Delta        string
+}
+
+type ReasoningSummaryTextDoneEvent struct {
+	EventHeader
+	ItemID       string
+	OutputIndex  int
+	SummaryIndex int
+	Text         string
+}
+
+type ErrorEvent struct {
+	EventHeader
+	Error *APIError
+}
+
+type UnknownEvent struct {
+	EventHeader
+	Raw map[string]any
+}
+
+// Typed returns the typed variant of e.
+func (e StreamEvent) Typed() Event {
+	h := EventHeader{Type: e.Type, SequenceNumber: e.SequenceNumber}
+	switch e.Type {
+	case "response.created":
+		return ResponseCreatedEvent{EventHeader: h, Response: e.Response}
+	case "response.in_progress":
+		return ResponseInProgressEvent{EventHeader: h, Response: e.Response}
+	case "response.completed":
+		return ResponseCompletedEvent{EventHeader: h, Response: e.Response}
+	case "response.failed":
+		return ResponseFailedEvent{EventHeader: h, Response: e.Response}
+	case "response.incomplete":
+		return ResponseIncompleteEvent{EventHeader: h, Response: e.Response}
+	case "response.output_item.added":
+		return OutputItemAddedEvent{EventHeader: h, OutputIndex: e.OutputIndex, Item: e.Item}
+	case "response.output_item.done":
+		return OutputItemDoneEvent{EventHeader: h, OutputIndex: e.OutputIndex, Item: e.Item}
+	case "response.content_part.added":
+		return ContentPartAddedEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Part: e.Part}
+	case "response.content_part.done":
+		return ContentPartDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Part: e.Part}
+	case "response.output_text.delta":
+		return OutputTextDeltaEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Delta: e.Delta}
+	case "response.output_text.done":
+		return OutputTextDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Text: e.Text}
+	case "response.output_text.annotation.added":
+		return OutputTextAnnotationAddedEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, AnnotationIndex: e.AnnotationIndex, Annotation: e.Annotation}
+	case "response.refusal.delta":
+		return RefusalDeltaEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Delta: e.Delta}
+	case "response.refusal.done":
+		return RefusalDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Refusal: e.Refusal}
+	case "response.function_call_arguments.delta":
+		return FunctionCallArgumentsDeltaEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, Delta: e.Delta}
+	case "response.function_call_arguments.done":
+		return FunctionCallArgumentsDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, Name: e.Name, Arguments: e.Arguments}
+	case "response.reasoning_text.delta":
+		return ReasoningTextDeltaEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Delta: e.Delta}
+	case "response.reasoning_text.done":
+		return ReasoningTextDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, ContentIndex: e.ContentIndex, Text: e.Text}
+	case "response.reasoning_summary_part.added":
+		return ReasoningSummaryPartAddedEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, SummaryIndex: e.SummaryIndex, Part: e.Part}
+	case "response.reasoning_summary_part.done":
+		return ReasoningSummaryPartDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, SummaryIndex: e.SummaryIndex, Part: e.Part}
+	case "response.reasoning_summary_text.delta":
+		return ReasoningSummaryTextDeltaEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, SummaryIndex: e.SummaryIndex, Delta: e.Delta}
+	case "response.reasoning_summary_text.done":
+		return ReasoningSummaryTextDoneEvent{EventHeader: h, ItemID: e.ItemID, OutputIndex: e.OutputIndex, SummaryIndex: e.SummaryIndex, Text: e.Text}
+	case "error":
+		return ErrorEvent{EventHeader: h, Error: e.Error}
+	default:
+		return UnknownEvent{EventHeader: h, Raw: e.Raw}
+	}
+}
+
+// RecvTyped is like Recv but returns the typed variant of the event.
+func (s *Stream) RecvTyped() (Event, error) {
+	event, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return event.Typed(), nil
+}
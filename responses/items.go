@@ -0,0 +1,292 @@
+package responses
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter/internal/jsonx"
+)
+
+const (
+	ItemTypeMessage             = "message"
+	ItemTypeFunctionCall        = "function_call"
+	ItemTypeFunctionCallOutput  = "function_call_output"
+	ItemTypeReasoning           = "reasoning"
+	ItemTypeWebSearchCall       = "web_search_call"
+	ItemTypeImageGenerationCall = "image_generation_call"
+)
+
+const (
+	PartTypeInputText     = "input_text"
+	PartTypeInputImage    = "input_image"
+	PartTypeInputFile     = "input_file"
+	PartTypeOutputText    = "output_text"
+	PartTypeRefusal       = "refusal"
+	PartTypeSummaryText   = "summary_text"
+	PartTypeReasoningText = "reasoning_text"
+)
+
+const (
+	AnnotationTypeURLCitation  = "url_citation"
+	AnnotationTypeFileCitation = "file_citation"
+)
+
+// InputItem is a single item of request input. Type selects which of the
+// fields are meaningful; use the New* constructors to build one. Items of
+// other types are re-encoded from Raw so they survive a round trip unchanged.
+type InputItem struct {
+	Type             string         `json:"type"`
+	ID               string         `json:"id,omitempty"`
+	Status           string         `json:"status,omitempty"`
+	Role             string         `json:"role,omitempty"`
+	Content          MessageContent `json:"content,omitzero"`
+	CallID           string         `json:"call_id,omitempty"`
+	Name             string         `json:"name,omitempty"`
+	Arguments        string         `json:"arguments,omitempty"`
+	Output           string         `json:"output,omitempty"`
+	Summary          []ContentPart  `json:"summary,omitzero"`
+	EncryptedContent string         `json:"encrypted_content,omitempty"`
+	Raw              map[string]any `json:"-"`
+}
+
+func (i InputItem) MarshalJSON() ([]byte, error) {
+	type alias InputItem
+	switch i.Type {
+	case ItemTypeFunctionCall:
+		return json.Marshal(struct {
+			alias
+			Arguments string `json:"arguments"`
+		}{alias(i), i.Arguments})
+	case ItemTypeFunctionCallOutput:
+		return json.Marshal(struct {
+			alias
+			Output string `json:"output"`
+		}{alias(i), i.Output})
+	case ItemTypeReasoning:
+		if i.Summary == nil {
+			i.Summary = []ContentPart{}
+		}
+	case ItemTypeMessage:
+	default:
+		if i.Raw != nil {
+			return json.Marshal(i.Raw)
+		}
+	}
+	return json.Marshal(alias(i))
+}
+
+// UnmarshalJSON accepts the shorthand message form without a type and a
+// function_call_output whose output is a list of parts; the latter is
+// flattened to its text.
+func (i *InputItem) UnmarshalJSON(data []byte) error {
+	type alias InputItem
+	var decoded struct {
+		alias
+		Output json.RawMessage `json:"output"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	raw, err := jsonx.MarshalMap(json.RawMessage(data))
+	if err != nil {
+		return err
+	}
+	output, err := outputText(decoded.Output)
+	if err != nil {
+		return err
+	}
+	*i = InputItem(decoded.alias)
+	i.Output = output
+	if i.Type == "" && i.Role != "" {
+		i.Type = ItemTypeMessage
+	}
+	i.Raw = raw
+	return nil
+}
+
+func outputText(data json.RawMessage) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return "", nil
+	}
+	if data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+	var parts []ContentPart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(part.Text)
+	}
+	return b.String(), nil
+}
+
+// MessageContent is the content of a message item: either plain Text or a
+// list of Parts. Parts take precedence when both are set.
+type MessageContent struct {
+	Text  string
+	Parts []ContentPart
+}
+
+func (c MessageContent) IsZero() bool {
+	return c.Text == "" && c.Parts == nil
+}
+
+func (c MessageContent) MarshalJSON() ([]byte, error) {
+	if c.Parts != nil {
+		return json.Marshal(c.Parts)
+	}
+	return json.Marshal(c.Text)
+}
+
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	*c = MessageContent{}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &c.Text)
+	}
+	return json.Unmarshal(data, &c.Parts)
+}
+
+// Annotation is a citation attached to output text. Which fields are set
+// depends on Type.
+type Annotation struct {
+	Type       string         `json:"type"`
+	URL        string         `json:"url,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Content    string         `json:"content,omitempty"`
+	StartIndex int            `json:"start_index,omitempty"`
+	EndIndex   int            `json:"end_index,omitempty"`
+	FileID     string         `json:"file_id,omitempty"`
+	Filename   string         `json:"filename,omitempty"`
+	Index      int            `json:"index,omitempty"`
+	Raw        map[string]any `json:"-"`
+}
+
+func (a Annotation) MarshalJSON() ([]byte, error) {
+	type alias Annotation
+	switch a.Type {
+	case AnnotationTypeURLCitation:
+		return json.Marshal(struct {
+			alias
+			StartIndex int `json:"start_index"`
+			EndIndex   int `json:"end_index"`
+		}{alias(a), a.StartIndex, a.EndIndex})
+	case AnnotationTypeFileCitation:
+		return json.Marshal(struct {
+			alias
+			Index int `json:"index"`
+		}{alias(a), a.Index})
+	}
+	if a.Raw != nil {
+		return json.Marshal(a.Raw)
+	}
+	return json.Marshal(alias(a))
+}
+
+func (a *Annotation) UnmarshalJSON(data []byte) error {
+	type alias Annotation
+	var decoded alias
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	raw, err := jsonx.MarshalMap(json.RawMessage(data))
+	if err != nil {
+		return err
+	}
+	*a = Annotation(decoded)
+	a.Raw = raw
+	return nil
+}
+
+func NewInputText(text string) ContentPart {
+	return ContentPart{Type: PartTypeInputText, Text: text}
+}
+
+// NewInputImage references an image by URL or data URL. detail may be empty.
+func NewInputImage(imageURL, detail string) ContentPart {
+	return ContentPart{Type: PartTypeInputImage, ImageURL: imageURL, Detail: detail}
+}
+
+// NewInputFile attaches file contents given as a data URL.
+func NewInputFile(filename, fileData string) ContentPart {
+	return ContentPart{Type: PartTypeInputFile, Filename: filename, FileData: fileData}
+}
+
+func NewInputFileURL(fileURL string) ContentPart {
+	return ContentPart{Type: PartTypeInputFile, FileURL: fileURL}
+}
+
+func NewInputFileID(fileID string) ContentPart {
+	return ContentPart{Type: PartTypeInputFile, FileID: fileID}
+}
+
+func NewOutputText(text string) ContentPart {
+	return ContentPart{Type: PartTypeOutputText, Text: text, Annotations: []Annotation{}}
+}
+
+func NewSummaryText(text string) ContentPart {
+	return ContentPart{Type: PartTypeSummaryText, Text: text}
+}
+
+func NewMessage(role string, parts ...ContentPart) InputItem {
+	return InputItem{Type: ItemTypeMessage, Role: role, Content: MessageContent{Parts: parts}}
+}
+
+// NewTextMessage is a message whose content is a plain string.
+func NewTextMessage(role, text string) InputItem {
+	return InputItem{Type: ItemTypeMessage, Role: role, Content: MessageContent{Text: text}}
+}
+
+func NewUserMessage(parts ...ContentPart) InputItem {
+	return NewMessage("user", parts...)
+}
+
+func NewAssistantMessage(text string) InputItem {
+	return NewMessage("assistant", NewOutputText(text))
+}
+
+func NewFunctionCall(callID, name, arguments string) InputItem {
+	return InputItem{Type: ItemTypeFunctionCall, CallID: callID, Name: name, Arguments: arguments}
+}
+
+func NewFunctionCallOutput(callID, output string) InputItem {
+	return InputItem{Type: ItemTypeFunctionCallOutput, CallID: callID, Output: output}
+}
+
+// NewReasoning carries a reasoning item back to the model. encryptedContent
+// is the value returned when "reasoning.encrypted_content" is included.
+func NewReasoning(id, encryptedContent string, summary ...ContentPart) InputItem {
+	if summary == nil {
+		summary = []ContentPart{}
+	}
+	return InputItem{Type: ItemTypeReasoning, ID: id, Summary: summary, EncryptedContent: encryptedContent}
+}
+
+// InputItems returns the request input as items. A string input becomes a
+// single user message; decoded JSON input is converted item by item.
+func (r Request) InputItems() ([]InputItem, error) {
+	switch input := r.Input.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []InputItem{NewTextMessage("user", input)}, nil
+	case []InputItem:
+		return input, nil
+	}
+	b, err := json.Marshal(r.Input)
+	if err != nil {
+		return nil, fmt.Errorf("responses: encode input: %w", err)
+	}
+	var items []InputItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("responses: decode input: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,155 @@
+package responses
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInputItems_Wire(t *testing.T) {
+	req := Request{
+		Model: "openai/gpt-4o",
+		Input: []InputItem{
+			NewTextMessage("developer", "Be terse."),
+			NewUserMessage(
+				NewInputText("Describe these."),
+				NewInputImage("https://example.com/cat.png", "low"),
+				NewInputFile("a.pdf", "data:application/pdf;base64,AAAA"),
+			),
+			NewReasoning("rs_1", "opaque"),
+			NewFunctionCall("call_1", "lookup", `{"q":"x"}`),
+			NewFunctionCallOutput("call_1", ""),
+		},
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	var wire struct {
+		Input []map[string]any `json:"input"`
+	}
+	if err := json.Unmarshal(b, &wire); err != nil {
+		t.Fatalf("unmarshal wire: %v", err)
+	}
+	if len(wire.Input) != 5 {
+		t.Fatalf("expected 5 items, got %v", wire.Input)
+	}
+	if wire.Input[0]["content"] != "Be terse." {
+		t.Fatalf("expected string content, got %v", wire.Input[0])
+	}
+	parts := wire.Input[1]["content"].([]any)
+	if image := parts[1].(map[string]any); image["type"] != "input_image" || image["image_url"] != "https://example.com/cat.png" || image["detail"] != "low" {
+		t.Fatalf("unexpected image part: %v", image)
+	}
+	if summary, ok := wire.Input[2]["summary"].([]any); !ok || len(summary) != 0 || wire.Input[2]["encrypted_content"] != "opaque" {
+		t.Fatalf("unexpected reasoning item: %v", wire.Input[2])
+	}
+	if output, ok := wire.Input[4]["output"]; !ok || output != "" {
+		t.Fatalf("expected empty output to be sent, got %v", wire.Input[4])
+	}
+
+	var decoded Request
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unmarshal request: %v", err)
+	}
+	items, err := decoded.InputItems()
+	if err != nil {
+		t.Fatalf("InputItems: %v", err)
+	}
+	if items[0].Content.Text != "Be terse." || len(items[1].Content.Parts) != 3 || items[1].Content.Parts[2].Filename != "a.pdf" {
+		t.Fatalf("unexpected decoded messages: %+v", items[:2])
+	}
+	if items[3].Arguments != `{"q":"x"}` || items[4].CallID != "call_1" {
+		t.Fatalf("unexpected decoded calls: %+v", items[3:])
+	}
+}
+
+func TestInputItems_Shorthand(t *testing.T) {
+	items, err := Request{Input: []any{
+		map[string]any{"role": "user", "content": "hi"},
+		map[string]any{"type": "function_call_output", "call_id": "c", "output": []any{map[string]any{"type": "input_text", "text": "ok"}}},
+	}}.InputItems()
+	if err != nil {
+		t.Fatalf("InputItems: %v", err)
+	}
+	if items[0].Type != ItemTypeMessage || items[0].Content.Text != "hi" || items[1].Output != "ok" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	items, err = Request{Input: "hello"}.InputItems()
+	if err != nil || len(items) != 1 || items[0].Role != "user" || items[0].Content.Text != "hello" {
+		t.Fatalf("unexpected string input: %+v, %v", items, err)
+	}
+}
+
+func TestOutputVariants(t *testing.T) {
+	payload := `{"id":"resp_1","status":"completed","output":[
+		{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Thinking."}],"content":[{"type":"reasoning_text","text":"Step one."}],"encrypted_content":"enc"},
+		{"type":"web_search_call","id":"ws_1","status":"completed","action":{"type":"search","query":"go","sources":[{"type":"url","url":"https://go.dev"}]}},
+		{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[{"type":"output_text","text":"Go is great.","annotations":[
+			{"type":"url_citation","url":"https://go.dev","title":"Go","start_index":0,"end_index":2},
+			{"type":"file_citation","file_id":"file_1","filename":"go.pdf","index":3}]}]},
+		{"type":"function_call","id":"fc_1","call_id":"call_1","name":"lookup","arguments":"{\"q\":\"x\"}","status":"completed"},
+		{"type":"image_generation_call","id":"ig_1","status":"completed","result":"AAAA"},
+		{"type":"mcp_call","id":"mcp_1","server_label":"s"}]}`
+	var res Response
+	if err := json.Unmarshal([]byte(payload), &res); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	if text := res.OutputText(); text != "Go is great." {
+		t.Fatalf("unexpected output text %q", text)
+	}
+	reasoning := res.Output[0].Typed().(OutputReasoning)
+	if reasoning.SummaryText() != "Thinking." || reasoning.EncryptedContent != "enc" {
+		t.Fatalf("unexpected reasoning: %+v", reasoning)
+	}
+	search := res.Output[1].Typed().(OutputWebSearchCall)
+	if search.Action == nil || search.Action.Query != "go" || len(search.Action.Sources) != 1 {
+		t.Fatalf("unexpected web search call: %+v", search)
+	}
+	annotations := res.Output[2].Typed().(OutputMessage).Annotations()
+	if len(annotations) != 2 || annotations[0].URL != "https://go.dev" || annotations[0].EndIndex != 2 || annotations[1].Filename != "go.pdf" || annotations[1].Index != 3 {
+		t.Fatalf("unexpected annotations: %+v", annotations)
+	}
+	if image := res.Output[4].Typed().(OutputImageGenerationCall); image.Result != "AAAA" {
+		t.Fatalf("unexpected image call: %+v", image)
+	}
+	if unknown := res.Output[5].Typed().(OutputUnknown); unknown.Type != "mcp_call" || unknown.Raw["server_label"] != "s" {
+		t.Fatalf("unexpected unknown item: %+v", unknown)
+	}
+
+	calls := res.FunctionCalls()
+	if len(calls) != 1 || calls[0].Name != "lookup" {
+		t.Fatalf("unexpected function calls: %+v", calls)
+	}
+	var args struct{ Q string }
+	if err := calls[0].DecodeArguments(&args); err != nil || args.Q != "x" {
+		t.Fatalf("DecodeArguments: %+v, %v", args, err)
+	}
+
+	followUp, err := res.FollowUpInput()
+	if err != nil {
+		t.Fatalf("FollowUpInput: %v", err)
+	}
+	followUp = append(followUp, calls[0].Output("found"))
+	b, err := json.Marshal(followUp)
+	if err != nil {
+		t.Fatalf("marshal follow-up: %v", err)
+	}
+	var wire []map[string]any
+	if err := json.Unmarshal(b, &wire); err != nil {
+		t.Fatalf("unmarshal follow-up: %v", err)
+	}
+	if len(wire) != 7 || wire[0]["encrypted_content"] != "enc" || wire[3]["call_id"] != "call_1" || wire[5]["server_label"] != "s" || wire[6]["output"] != "found" {
+		t.Fatalf("unexpected follow-up input: %v", wire)
+	}
+	if parts, _ := wire[0]["content"].([]any); len(parts) != 1 || parts[0].(map[string]any)["text"] != "Step one." {
+		t.Fatalf("expected reasoning content to be sent back, got %v", wire[0])
+	}
+	content := wire[2]["content"].([]any)[0].(map[string]any)
+	annotation := content["annotations"].([]any)[0].(map[string]any)
+	if annotation["start_index"] != float64(0) {
+		t.Fatalf("expected start_index to be sent, got %v", annotation)
+	}
+}
@@ -0,0 +1,227 @@
+package responses
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter/internal/jsonx"
+)
+
+// OutputVariant is the typed form of an OutputItem. The concrete types in
+// this file are the only implementations; unrecognized item types become
+// OutputUnknown.
+type OutputVariant interface {
+	ItemType() string
+	isOutputVariant()
+}
+
+type OutputMessage struct {
+	ID      string
+	Status  string
+	Role    string
+	Content []ContentPart
+}
+
+type OutputReasoning struct {
+	ID               string
+	Status           string
+	Summary          []ContentPart
+	Content          []ContentPart
+	EncryptedContent string
+}
+
+type OutputFunctionCall struct {
+	ID        string
+	Status    string
+	CallID    string
+	Name      string
+	Arguments string
+}
+
+type OutputWebSearchCall struct {
+	ID     string
+	Status string
+	Action *WebSearchAction
+}
+
+// OutputImageGenerationCall carries the generated image as base64 in Result.
+type OutputImageGenerationCall struct {
+	ID     string
+	Status string
+	Result string
+}
+
+type OutputUnknown struct {
+	Type string
+	Raw  map[string]any
+}
+
+type WebSearchAction struct {
+	Type    string            `json:"type"`
+	Query   string            `json:"query,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Sources []WebSearchSource `json:"sources,omitempty"`
+}
+
+type WebSearchSource struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+}
+
+func (OutputMessage) ItemType() string             { return ItemTypeMessage }
+func (OutputReasoning) ItemType() string           { return ItemTypeReasoning }
+func (OutputFunctionCall) ItemType() string        { return ItemTypeFunctionCall }
+func (OutputWebSearchCall) ItemType() string       { return ItemTypeWebSearchCall }
+func (OutputImageGenerationCall) ItemType() string { return ItemTypeImageGenerationCall }
+func (o OutputUnknown) ItemType() string           { return o.Type }
+
+func (OutputMessage) isOutputVariant()             {}
+func (OutputReasoning) isOutputVariant()           {}
+func (OutputFunctionCall) isOutputVariant()        {}
+func (OutputWebSearchCall) isOutputVariant()       {}
+func (OutputImageGenerationCall) isOutputVariant() {}
+func (OutputUnknown) isOutputVariant()             {}
+
+// Typed returns the typed variant of i.
+func (i OutputItem) Typed() OutputVariant {
+	switch i.Type {
+	case ItemTypeMessage:
+		return OutputMessage{ID: i.ID, Status: i.Status, Role: i.Role, Content: i.Content}
+	case ItemTypeReasoning:
+		return OutputReasoning{ID: i.ID, Status: i.Status, Summary: i.Summary, Content: i.Content, EncryptedContent: i.EncryptedContent}
+	case ItemTypeFunctionCall:
+		return OutputFunctionCall{ID: i.ID, Status: i.Status, CallID: i.CallID, Name: i.Name, Arguments: i.Arguments}
+	case ItemTypeWebSearchCall:
+		return OutputWebSearchCall{ID: i.ID, Status: i.Status, Action: i.Action}
+	case ItemTypeImageGenerationCall:
+		return OutputImageGenerationCall{ID: i.ID, Status: i.Status, Result: i.Result}
+	default:
+		return OutputUnknown{Type: i.Type, Raw: i.Raw}
+	}
+}
+
+// Text returns the concatenated output_text parts of the message.
+func (m OutputMessage) Text() string {
+	var b strings.Builder
+	for _, part := range m.Content {
+		if part.Type == PartTypeOutputText {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+// Refusal returns the concatenated refusal parts of the message.
+func (m OutputMessage) Refusal() string {
+	var b strings.Builder
+	for _, part := range m.Content {
+		if part.Type == PartTypeRefusal {
+			b.WriteString(part.Refusal)
+		}
+	}
+	return b.String()
+}
+
+func (m OutputMessage) Annotations() []Annotation {
+	var out []Annotation
+	for _, part := range m.Content {
+		out = append(out, part.Annotations...)
+	}
+	return out
+}
+
+// SummaryText returns the concatenated reasoning summary.
+func (r OutputReasoning) SummaryText() string {
+	var b strings.Builder
+	for _, part := range r.Summary {
+		b.WriteString(part.Text)
+	}
+	return b.String()
+}
+
+// DecodeArguments unmarshals the call arguments into v.
+func (c OutputFunctionCall) DecodeArguments(v any) error {
+	args := c.Arguments
+	if strings.TrimSpace(args) == "" {
+		args = "{}"
+	}
+	if err := json.Unmarshal([]byte(args), v); err != nil {
+		return fmt.Errorf("responses: decode arguments of %s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// Output returns the function_call_output item answering c.
+func (c OutputFunctionCall) Output(output string) InputItem {
+	return NewFunctionCallOutput(c.CallID, output)
+}
+
+// InputItem returns i in the form accepted as request input, so that it can
+// be sent back to continue the conversation without previous_response_id.
+func (i OutputItem) InputItem() (InputItem, error) {
+	switch i.Type {
+	case ItemTypeMessage:
+		content := i.Content
+		if content == nil {
+			content = []ContentPart{}
+		}
+		return InputItem{Type: i.Type, ID: i.ID, Status: i.Status, Role: i.Role, Content: MessageContent{Parts: content}}, nil
+	case ItemTypeReasoning:
+		item := NewReasoning(i.ID, i.EncryptedContent, i.Summary...)
+		if i.Content != nil {
+			item.Content = MessageContent{Parts: i.Content}
+		}
+		return item, nil
+	case ItemTypeFunctionCall:
+		item := NewFunctionCall(i.CallID, i.Name, i.Arguments)
+		item.ID = i.ID
+		item.Status = i.Status
+		return item, nil
+	}
+	raw := i.Raw
+	if raw == nil {
+		var err error
+		if raw, err = jsonx.MarshalMap(i); err != nil {
+			return InputItem{}, fmt.Errorf("responses: encode output item: %w", err)
+		}
+	}
+	return InputItem{Type: i.Type, ID: i.ID, Status: i.Status, Raw: raw}, nil
+}
+
+// OutputText returns the text of all output messages.
+func (r Response) OutputText() string {
+	var b strings.Builder
+	for _, item := range r.Output {
+		if msg, ok := item.Typed().(OutputMessage); ok {
+			b.WriteString(msg.Text())
+		}
+	}
+	return b.String()
+}
+
+// FunctionCalls returns the function calls the model made, in output order.
+func (r Response) FunctionCalls() []OutputFunctionCall {
+	var calls []OutputFunctionCall
+	for _, item := range r.Output {
+		if call, ok := item.Typed().(OutputFunctionCall); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// FollowUpInput returns the output items as input items. Appending them to
+// the previous input, followed by any function call outputs, continues the
+// conversation statelessly. Reasoning items keep their encrypted content.
+func (r Response) FollowUpInput() ([]InputItem, error) {
+	items := make([]InputItem, 0, len(r.Output))
+	for _, output := range r.Output {
+		item, err := output.InputItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
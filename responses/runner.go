@@ -0,0 +1,208 @@
+package responses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	ErrMaxIterations = errors.New("responses: runner reached max iterations")
+	ErrMaxToolCalls  = errors.New("responses: runner reached max tool calls")
+	ErrCostLimit     = errors.New("responses: runner reached cost limit")
+)
+
+const defaultMaxIterations = 10
+
+// ToolFunc executes a function call. arguments is the JSON the model sent.
+type ToolFunc func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// RunnerConfig configures a Runner.
+type RunnerConfig struct {
+	// Tools maps function names to their handlers.
+	Tools map[string]ToolFunc
+	// MaxIterations bounds the number of model requests. Zero means 10.
+	MaxIterations int
+	// MaxToolCalls bounds the number of function calls executed over the
+	// whole run. Zero falls back to Request.MaxToolCalls, then to no limit.
+	MaxToolCalls int
+	// MaxCost stops the run once the accumulated cost reaches it. Zero
+	// disables the limit.
+	MaxCost float64
+	// Chain continues with PreviousResponseID and only the function call
+	// outputs instead of resending the full input.
+	Chain bool
+	// OnEvent receives every stream event when the request has Stream set.
+	OnEvent func(Event)
+}
+
+// Runner drives a tool-calling loop: it sends the request, executes the
+// function calls in the response and sends their outputs back until the
+// model answers without calling a function.
+type Runner struct {
+	backend backend
+	config  RunnerConfig
+}
+
+func NewRunner(backend backend, config RunnerConfig) *Runner {
+	return &Runner{backend: backend, config: config}
+}
+
+// RunResult is the outcome of a run. Input holds the full conversation,
+// including the final response's output.
+type RunResult struct {
+	Response   *Response
+	Input      []InputItem
+	Iterations int
+	ToolCalls  int
+	Usage      NormalizedUsage
+}
+
+// Run executes the loop. When a limit is reached it returns the result so far
+// together with ErrMaxIterations, ErrMaxToolCalls or ErrCostLimit.
+func (r *Runner) Run(ctx context.Context, req Request) (*RunResult, error) {
+	input, err := req.InputItems()
+	if err != nil {
+		return nil, err
+	}
+	maxIterations := r.config.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxIterations
+	}
+	maxToolCalls := r.config.MaxToolCalls
+	if maxToolCalls <= 0 && req.MaxToolCalls != nil {
+		maxToolCalls = *req.MaxToolCalls
+	}
+	parallel := req.ParallelToolCalls == nil || *req.ParallelToolCalls
+
+	result := &RunResult{Input: input}
+	for {
+		if result.Iterations >= maxIterations {
+			return result, ErrMaxIterations
+		}
+		if !r.config.Chain || result.Response == nil {
+			req.Input = result.Input
+		}
+		res, err := r.create(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		result.Iterations++
+		result.Response = res
+		if res.Usage != nil {
+			result.Usage = result.Usage.Add(res.Usage.Normalized())
+		}
+		followUp, err := res.FollowUpInput()
+		if err != nil {
+			return result, err
+		}
+		result.Input = append(result.Input, followUp...)
+
+		calls := res.FunctionCalls()
+		if len(calls) == 0 {
+			return result, nil
+		}
+		if maxToolCalls > 0 && result.ToolCalls+len(calls) > maxToolCalls {
+			return result, ErrMaxToolCalls
+		}
+		if r.config.MaxCost > 0 && result.Usage.Cost >= r.config.MaxCost {
+			return result, ErrCostLimit
+		}
+		outputs, err := r.execute(ctx, calls, parallel)
+		if err != nil {
+			return result, err
+		}
+		result.ToolCalls += len(calls)
+		result.Input = append(result.Input, outputs...)
+		if r.config.Chain {
+			req.PreviousResponseID = res.ID
+			req.Input = outputs
+		}
+	}
+}
+
+func (r *Runner) create(ctx context.Context, req Request) (*Response, error) {
+	if !req.Stream {
+		return r.backend.CreateResponse(ctx, req)
+	}
+	stream, err := r.backend.CreateResponseStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	acc := NewAccumulator()
+	for !acc.Done() {
+		event, err := stream.RecvTyped()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.config.OnEvent != nil {
+			r.config.OnEvent(event)
+		}
+		if err := acc.Add(event); err != nil {
+			var gap *SequenceGapError
+			if !errors.As(err, &gap) {
+				return nil, err
+			}
+		}
+	}
+	if !acc.Done() {
+		// The stream ended without its final event.
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err := acc.Err(); err != nil {
+		return nil, err
+	}
+	return acc.Response(), nil
+}
+
+// execute runs the calls, concurrently when parallel is set, and returns
+// their outputs in call order. Handler errors and unknown functions are
+// reported to the model; only context cancellation aborts the run.
+func (r *Runner) execute(ctx context.Context, calls []OutputFunctionCall, parallel bool) ([]InputItem, error) {
+	outputs := make([]InputItem, len(calls))
+	run := func(i int) {
+		outputs[i] = calls[i].Output(r.call(ctx, calls[i]))
+	}
+	if parallel && len(calls) > 1 {
+		var wg sync.WaitGroup
+		wg.Add(len(calls))
+		for i := range calls {
+			go func() {
+				defer wg.Done()
+				run(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range calls {
+			run(i)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+func (r *Runner) call(ctx context.Context, call OutputFunctionCall) string {
+	fn, ok := r.config.Tools[call.Name]
+	if !ok {
+		return fmt.Sprintf("error: unknown function %q", call.Name)
+	}
+	arguments := json.RawMessage(call.Arguments)
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	output, err := fn(ctx, arguments)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return output
+}
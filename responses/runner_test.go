@@ -0,0 +1,189 @@
+package responses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// scriptedBackend replies to successive requests with the given bodies,
+// decoded as a response or, for streaming requests, read as server-sent
+// events. It records every request as a JSON object.
+type scriptedBackend struct {
+	replies  []string
+	requests []map[string]any
+}
+
+func (b *scriptedBackend) next(req Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", err
+	}
+	b.requests = append(b.requests, body)
+	if len(b.requests) > len(b.replies) {
+		return "", fmt.Errorf("unexpected request %d", len(b.requests))
+	}
+	return b.replies[len(b.requests)-1], nil
+}
+
+func (b *scriptedBackend) CreateResponse(ctx context.Context, req Request) (*Response, error) {
+	reply, err := b.next(req)
+	if err != nil {
+		return nil, err
+	}
+	var res Response
+	if err := json.Unmarshal([]byte(reply), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (b *scriptedBackend) CreateResponseStream(ctx context.Context, req Request) (*Stream, error) {
+	reply, err := b.next(req)
+	if err != nil {
+		return nil, err
+	}
+	return NewStream(&http.Response{Body: io.NopCloser(strings.NewReader(reply))}), nil
+}
+
+func echoTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ City string }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.City == "" {
+		return "", errors.New("city is required")
+	}
+	return "sunny in " + in.City, nil
+}
+
+func TestRunner_Resend(t *testing.T) {
+	backend := &scriptedBackend{replies: []string{
+		`{"id":"resp_1","status":"completed","output":[
+			{"type":"reasoning","id":"rs_1","summary":[],"encrypted_content":"enc"},
+			{"type":"function_call","id":"fc_1","call_id":"call_1","name":"weather","arguments":"{\"city\":\"Paris\"}"},
+			{"type":"function_call","id":"fc_2","call_id":"call_2","name":"weather","arguments":"{}"}],
+			"usage":{"input_tokens":10,"output_tokens":5,"total_tokens":15,"cost":0.01}}`,
+		`{"id":"resp_2","status":"completed","output":[{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"It is sunny.","annotations":[]}]}],
+			"usage":{"input_tokens":20,"output_tokens":4,"total_tokens":24,"cost":0.02}}`,
+	}}
+	runner := NewRunner(backend, RunnerConfig{Tools: map[string]ToolFunc{"weather": echoTool}})
+
+	result, err := runner.Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather in Paris?"})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Response.OutputText() != "It is sunny." || result.Iterations != 2 || result.ToolCalls != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Usage.InputTokens != 30 || result.Usage.Cost < 0.029 {
+		t.Fatalf("unexpected usage: %+v", result.Usage)
+	}
+
+	second := backend.requests[1]
+	if _, ok := second["previous_response_id"]; ok {
+		t.Fatalf("resend mode must not chain: %v", second)
+	}
+	input := second["input"].([]any)
+	if len(input) != 6 {
+		t.Fatalf("expected full input resend, got %v", input)
+	}
+	if reasoning := input[1].(map[string]any); reasoning["encrypted_content"] != "enc" {
+		t.Fatalf("reasoning not carried back: %v", reasoning)
+	}
+	first, errOutput := input[4].(map[string]any), input[5].(map[string]any)
+	if first["type"] != "function_call_output" || first["call_id"] != "call_1" || first["output"] != "sunny in Paris" {
+		t.Fatalf("unexpected output: %v", first)
+	}
+	if !strings.Contains(errOutput["output"].(string), "city is required") {
+		t.Fatalf("handler error not reported: %v", errOutput)
+	}
+}
+
+func TestRunner_ChainAndLimits(t *testing.T) {
+	call := `{"id":"resp_%d","status":"completed","output":[{"type":"function_call","id":"fc","call_id":"call","name":"weather","arguments":"{\"city\":\"Oslo\"}"}],"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2,"cost":0.5}}`
+	backend := &scriptedBackend{replies: []string{strings.Replace(call, "%d", "1", 1), strings.Replace(call, "%d", "2", 1)}}
+	runner := NewRunner(backend, RunnerConfig{
+		Tools:   map[string]ToolFunc{"weather": echoTool},
+		Chain:   true,
+		MaxCost: 1,
+	})
+
+	result, err := runner.Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather?"})
+	if !errors.Is(err, ErrCostLimit) || result.Iterations != 2 || result.ToolCalls != 1 {
+		t.Fatalf("expected cost limit after two iterations, got %+v, %v", result, err)
+	}
+	second := backend.requests[1]
+	if second["previous_response_id"] != "resp_1" || len(second["input"].([]any)) != 1 {
+		t.Fatalf("expected chained request with outputs only, got %v", second)
+	}
+
+	maxCalls := 1
+	backend = &scriptedBackend{replies: []string{strings.Replace(call, "%d", "1", 1), strings.Replace(call, "%d", "2", 1)}}
+	runner = NewRunner(backend, RunnerConfig{Tools: map[string]ToolFunc{"weather": echoTool}})
+	_, err = runner.Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather?", MaxToolCalls: &maxCalls})
+	if !errors.Is(err, ErrMaxToolCalls) {
+		t.Fatalf("expected max tool calls error, got %v", err)
+	}
+
+	backend = &scriptedBackend{replies: []string{strings.Replace(call, "%d", "1", 1)}}
+	runner = NewRunner(backend, RunnerConfig{Tools: map[string]ToolFunc{"weather": echoTool}, MaxIterations: 1})
+	if _, err = runner.Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather?"}); !errors.Is(err, ErrMaxIterations) {
+		t.Fatalf("expected max iterations error, got %v", err)
+	}
+}
+
+func TestRunner_Stream(t *testing.T) {
+	events := []string{
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress"}}`,
+		`{"type":"response.output_item.added","sequence_number":1,"output_index":0,"item":{"type":"function_call","id":"fc_1","call_id":"call_1","name":"weather","arguments":""}}`,
+		`{"type":"response.function_call_arguments.delta","sequence_number":2,"output_index":0,"item_id":"fc_1","delta":"{\"city\":"}`,
+		`{"type":"response.function_call_arguments.delta","sequence_number":3,"output_index":0,"item_id":"fc_1","delta":"\"Rome\"}"}`,
+		`{"type":"response.completed","sequence_number":4,"response":{"id":"resp_1","status":"completed"}}`,
+	}
+	final := []string{
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_2","status":"in_progress"}}`,
+		`{"type":"response.output_text.delta","sequence_number":1,"output_index":0,"content_index":0,"delta":"Warm."}`,
+		`{"type":"response.completed","sequence_number":2,"response":{"id":"resp_2","status":"completed"}}`,
+	}
+	sse := func(events []string) string {
+		return "data: " + strings.Join(events, "\n\ndata: ") + "\n\ndata: [DONE]\n\n"
+	}
+	backend := &scriptedBackend{replies: []string{sse(events), sse(final)}}
+	var seen int
+	runner := NewRunner(backend, RunnerConfig{
+		Tools:   map[string]ToolFunc{"weather": echoTool},
+		OnEvent: func(Event) { seen++ },
+	})
+
+	result, err := runner.Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather?", Stream: true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if seen != len(events)+len(final) || result.Response.ID != "resp_2" {
+		t.Fatalf("unexpected stream run: seen %d, %+v", seen, result.Response)
+	}
+	input := backend.requests[1]["input"].([]any)
+	if output := input[2].(map[string]any); output["output"] != "sunny in Rome" {
+		t.Fatalf("unexpected tool output: %v", output)
+	}
+}
+
+func TestRunner_TruncatedStream(t *testing.T) {
+	sse := `data: {"type":"response.created","sequence_number":0,"response":{"id":"resp_1","status":"in_progress"}}` + "\n\n" +
+		`data: {"type":"response.output_text.delta","sequence_number":1,"output_index":0,"content_index":0,"delta":"Wa"}` + "\n\n"
+	backend := &scriptedBackend{replies: []string{sse}}
+	_, err := NewRunner(backend, RunnerConfig{}).Run(context.Background(), Request{Model: "openai/gpt-4o", Input: "Weather?", Stream: true})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected an unexpected EOF, got %v", err)
+	}
+}
@@ -16,6 +16,9 @@ type (
 	ResponseFormat      = shared.ResponseFormat
 	TraceMetadata       = shared.TraceMetadata
 	APIError            = shared.APIError
+	CostDetails         = shared.CostDetails
+	ServerToolUse       = shared.ServerToolUse
+	NormalizedUsage     = shared.NormalizedUsage
 )
 
 type Request struct {
@@ -112,27 +115,71 @@ type Response struct {
 }
 
 type Usage struct {
-	InputTokens         int            `json:"input_tokens"`
-	OutputTokens        int            `json:"output_tokens"`
-	TotalTokens         int            `json:"total_tokens"`
-	InputTokensDetails  map[string]any `json:"input_tokens_details,omitempty"`
-	OutputTokensDetails map[string]any `json:"output_tokens_details,omitempty"`
-	Cost                float64        `json:"cost,omitempty"`
-	IsBYOK              *bool          `json:"is_byok,omitempty"`
-	CostDetails         map[string]any `json:"cost_details,omitempty"`
+	InputTokens         int                  `json:"input_tokens"`
+	OutputTokens        int                  `json:"output_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	InputTokensDetails  *InputTokensDetails  `json:"input_tokens_details,omitempty"`
+	OutputTokensDetails *OutputTokensDetails `json:"output_tokens_details,omitempty"`
+	Cost                float64              `json:"cost,omitempty"`
+	IsBYOK              *bool                `json:"is_byok,omitempty"`
+	CostDetails         *CostDetails         `json:"cost_details,omitempty"`
+	ServerToolUse       *ServerToolUse       `json:"server_tool_use,omitempty"`
+}
+
+type InputTokensDetails struct {
+	CachedTokens     int `json:"cached_tokens"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+	AudioTokens      int `json:"audio_tokens,omitempty"`
+	VideoTokens      int `json:"video_tokens,omitempty"`
+}
+
+type OutputTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+	AudioTokens     int `json:"audio_tokens,omitempty"`
+	ImageTokens     int `json:"image_tokens,omitempty"`
+}
+
+// Normalized returns the dialect-independent view of u.
+func (u Usage) Normalized() NormalizedUsage {
+	out := NormalizedUsage{
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		TotalTokens:  u.TotalTokens,
+	}
+	if d := u.InputTokensDetails; d != nil {
+		out.CachedTokens = d.CachedTokens
+		out.CacheWriteTokens = d.CacheWriteTokens
+		out.AudioTokens += d.AudioTokens
+	}
+	if d := u.OutputTokensDetails; d != nil {
+		out.ReasoningTokens = d.ReasoningTokens
+		out.AudioTokens += d.AudioTokens
+		out.ImageTokens = d.ImageTokens
+	}
+	if u.ServerToolUse != nil {
+		out.ServerToolUse = *u.ServerToolUse
+	}
+	out.SetCost(u.Cost, u.IsBYOK, u.CostDetails)
+	if out.TotalTokens == 0 {
+		out.TotalTokens = out.InputTokens + out.OutputTokens
+	}
+	return out
 }
 
 type OutputItem struct {
-	ID        string         `json:"id,omitempty"`
-	Type      string         `json:"type,omitempty"`
-	Status    string         `json:"status,omitempty"`
-	Role      string         `json:"role,omitempty"`
-	Name      string         `json:"name,omitempty"`
-	Arguments string         `json:"arguments,omitempty"`
-	CallID    string         `json:"call_id,omitempty"`
-	Content   []ContentPart  `json:"content,omitempty"`
-	Summary   []ContentPart  `json:"summary,omitempty"`
-	Raw       map[string]any `json:"-"`
+	ID               string           `json:"id,omitempty"`
+	Type             string           `json:"type,omitempty"`
+	Status           string           `json:"status,omitempty"`
+	Role             string           `json:"role,omitempty"`
+	Name             string           `json:"name,omitempty"`
+	Arguments        string           `json:"arguments,omitempty"`
+	CallID           string           `json:"call_id,omitempty"`
+	Content          []ContentPart    `json:"content,omitempty"`
+	Summary          []ContentPart    `json:"summary,omitempty"`
+	EncryptedContent string           `json:"encrypted_content,omitempty"`
+	Action           *WebSearchAction `json:"action,omitempty"`
+	Result           string           `json:"result,omitempty"`
+	Raw              map[string]any   `json:"-"`
 }
 
 func (i *OutputItem) UnmarshalJSON(data []byte) error {
@@ -151,10 +198,17 @@ func (i *OutputItem) UnmarshalJSON(data []byte) error {
 }
 
 type ContentPart struct {
-	Type        string           `json:"type,omitempty"`
-	Text        string           `json:"text,omitempty"`
-	Annotations []map[string]any `json:"annotations,omitempty"`
-	Raw         map[string]any   `json:"-"`
+	Type        string         `json:"type,omitempty"`
+	Text        string         `json:"text,omitempty"`
+	Refusal     string         `json:"refusal,omitempty"`
+	Annotations []Annotation   `json:"annotations,omitzero"`
+	ImageURL    string         `json:"image_url,omitempty"`
+	Detail      string         `json:"detail,omitempty"`
+	FileID      string         `json:"file_id,omitempty"`
+	FileData    string         `json:"file_data,omitempty"`
+	FileURL     string         `json:"file_url,omitempty"`
+	Filename    string         `json:"filename,omitempty"`
+	Raw         map[string]any `json:"-"`
 }
 
 func (p *ContentPart) UnmarshalJSON(data []byte) error {
@@ -181,20 +235,24 @@ func NewStream(resp *http.Response) *Stream {
 }
 
 type StreamEvent struct {
-	Type           string         `json:"type"`
-	SequenceNumber int            `json:"sequence_number,omitempty"`
-	OutputIndex    int            `json:"output_index,omitempty"`
-	ItemID         string         `json:"item_id,omitempty"`
-	ContentIndex   int            `json:"content_index,omitempty"`
-	Delta          string         `json:"delta,omitempty"`
-	Text           string         `json:"text,omitempty"`
-	Name           string         `json:"name,omitempty"`
-	Arguments      string         `json:"arguments,omitempty"`
-	Response       *Response      `json:"response,omitempty"`
-	Item           *OutputItem    `json:"item,omitempty"`
-	Part           *ContentPart   `json:"part,omitempty"`
-	Error          *APIError      `json:"error,omitempty"`
-	Raw            map[string]any `json:"-"`
+	Type            string         `json:"type"`
+	SequenceNumber  int            `json:"sequence_number,omitempty"`
+	OutputIndex     int            `json:"output_index,omitempty"`
+	ItemID          string         `json:"item_id,omitempty"`
+	ContentIndex    int            `json:"content_index,omitempty"`
+	SummaryIndex    int            `json:"summary_index,omitempty"`
+	AnnotationIndex int            `json:"annotation_index,omitempty"`
+	Delta           string         `json:"delta,omitempty"`
+	Text            string         `json:"text,omitempty"`
+	Refusal         string         `json:"refusal,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	Arguments       string         `json:"arguments,omitempty"`
+	Annotation      *Annotation    `json:"annotation,omitempty"`
+	Response        *Response      `json:"response,omitempty"`
+	Item            *OutputItem    `json:"item,omitempty"`
+	Part            *ContentPart   `json:"part,omitempty"`
+	Error           *APIError      `json:"error,omitempty"`
+	Raw             map[string]any `json:"-"`
 }
 
 func (e *StreamEvent) UnmarshalJSON(data []byte) error {
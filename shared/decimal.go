@@ -0,0 +1,260 @@
+package shared
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number: an arbitrary-precision integer scaled
+// by a power of ten. The zero value is 0. Operations return new values and
+// never round, so per-token prices can be summed over any number of tokens
+// without drift.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+var bigTen = big.NewInt(10)
+
+// maxDecimalScale bounds parsed exponents so hostile input cannot allocate
+// huge numbers.
+const maxDecimalScale = 4096
+
+// ParseDecimal parses a decimal number such as "0.00000015", "-3", "1.5e-7"
+// or "2E+3".
+func ParseDecimal(s string) (Decimal, error) {
+	raw := strings.TrimSpace(s)
+	mantissa, exp := raw, int64(0)
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		var err error
+		mantissa = raw[:i]
+		exp, err = strconv.ParseInt(raw[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("shared: invalid decimal %q", s)
+		}
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	sign := ""
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" || strings.ContainsAny(fracPart, "+-") {
+		return Decimal{}, fmt.Errorf("shared: invalid decimal %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(sign+digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("shared: invalid decimal %q", s)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale < -maxDecimalScale || scale > maxDecimalScale {
+		return Decimal{}, fmt.Errorf("shared: decimal %q out of range", s)
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: int32(scale)}.normalize(), nil
+}
+
+// MustParseDecimal is ParseDecimal for constants; it panics on error.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromInt returns n as a Decimal.
+func DecimalFromInt(n int64) Decimal {
+	return Decimal{unscaled: big.NewInt(n)}
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// normalize strips trailing zeros so equal values have one representation.
+func (d Decimal) normalize() Decimal {
+	u := new(big.Int).Set(d.int())
+	if u.Sign() == 0 {
+		return Decimal{unscaled: u}
+	}
+	scale := d.scale
+	q, r := new(big.Int), new(big.Int)
+	for scale > 0 {
+		q.QuoRem(u, bigTen, r)
+		if r.Sign() != 0 {
+			break
+		}
+		u.Set(q)
+		scale--
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// rescale returns the unscaled values of a and b at their common scale.
+func rescale(a, b Decimal) (x, y *big.Int, scale int32) {
+	x, y = a.int(), b.int()
+	switch {
+	case a.scale < b.scale:
+		x = new(big.Int).Mul(x, pow10(b.scale-a.scale))
+		return x, y, b.scale
+	case b.scale < a.scale:
+		y = new(big.Int).Mul(y, pow10(a.scale-b.scale))
+	}
+	return x, y, a.scale
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	x, y, scale := rescale(d, o)
+	return Decimal{unscaled: new(big.Int).Add(x, y), scale: scale}.normalize()
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	x, y, scale := rescale(d, o)
+	return Decimal{unscaled: new(big.Int).Sub(x, y), scale: scale}.normalize()
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), o.int()), scale: d.scale + o.scale}.normalize()
+}
+
+// MulInt multiplies by an integer such as a token count.
+func (d Decimal) MulInt(n int64) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), big.NewInt(n)), scale: d.scale}.normalize()
+}
+
+// Shift multiplies by 10^n; a negative n divides exactly.
+func (d Decimal) Shift(n int32) Decimal {
+	if n >= 0 {
+		return Decimal{unscaled: new(big.Int).Mul(d.int(), pow10(n)), scale: d.scale}.normalize()
+	}
+	return Decimal{unscaled: new(big.Int).Set(d.int()), scale: d.scale - n}.normalize()
+}
+
+// PerMillion is the price of a million units at d per unit.
+func (d Decimal) PerMillion() Decimal { return d.Shift(6) }
+
+// PerThousand is the price of a thousand units at d per unit.
+func (d Decimal) PerThousand() Decimal { return d.Shift(3) }
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	x, y, _ := rescale(d, o)
+	return x.Cmp(y)
+}
+
+func (d Decimal) Sign() int { return d.int().Sign() }
+
+func (d Decimal) IsZero() bool { return d.Sign() == 0 }
+
+// Float64 returns the nearest float64, for display or non-monetary math.
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.int(), pow10(d.scale)).Float64()
+	return f
+}
+
+// String formats d in plain notation without trailing zeros.
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + s
+	}
+	if pad := int(d.scale) - len(s) + 1; pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+	point := len(s) - int(d.scale)
+	return sign + s[:point] + "." + s[point:]
+}
+
+// StringFixed formats d rounded half away from zero to places decimals.
+func (d Decimal) StringFixed(places int32) string {
+	places = max(places, 0)
+	r := d
+	if d.scale > places {
+		q, rem := new(big.Int).QuoRem(d.int(), pow10(d.scale-places), new(big.Int))
+		// Round half away from zero.
+		if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(pow10(d.scale-places)) >= 0 {
+			q.Add(q, big.NewInt(int64(d.Sign())))
+		}
+		r = Decimal{unscaled: q, scale: places}
+	}
+	s := r.String()
+	if places == 0 {
+		return s
+	}
+	if r.scale <= 0 {
+		return s + "." + strings.Repeat("0", int(places))
+	}
+	return s + strings.Repeat("0", int(places-r.scale))
+}
+
+// BigNumber converts d for JSON fields that carry prices.
+func (d Decimal) BigNumber() BigNumber {
+	return BigNumber(d.String())
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Decimal parses n exactly. An empty BigNumber is 0.
+func (n BigNumber) Decimal() (Decimal, error) {
+	if n == "" {
+		return Decimal{}, nil
+	}
+	return ParseDecimal(string(n))
+}
+
+// Cmp compares n and o exactly. Values that do not parse sort before all
+// numbers.
+func (n BigNumber) Cmp(o BigNumber) int {
+	a, errA := n.Decimal()
+	b, errB := o.Decimal()
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return a.Cmp(b)
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for in, want := range map[string]string{
+		"0.00000015": "0.00000015",
+		"-3":         "-3",
+		"+2.50":      "2.5",
+		"1.5e-7":     "0.00000015",
+		"2E+3":       "2000",
+		".5":         "0.5",
+		"0.000":      "0",
+	} {
+		d, err := ParseDecimal(in)
+		if err != nil || d.String() != want {
+			t.Errorf("ParseDecimal(%q) = %s, %v; want %s", in, d, err, want)
+		}
+	}
+	for _, in := range []string{"", "-", "1.2.3", "abc", "1e", "0x10", "1.-2", "1e99999"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) should fail", in)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	price := MustParseDecimal("0.00000015")
+	var sum Decimal
+	for range 1000 {
+		sum = sum.Add(price.MulInt(1000))
+	}
+	if want := MustParseDecimal("0.15"); sum.Cmp(want) != 0 || sum.String() != "0.15" {
+		t.Fatalf("sum drifted: %s", sum)
+	}
+	if got := price.PerMillion().String(); got != "0.15" {
+		t.Fatalf("PerMillion: %s", got)
+	}
+	if got := price.PerThousand().String(); got != "0.00015" {
+		t.Fatalf("PerThousand: %s", got)
+	}
+	if got := MustParseDecimal("1.1").Sub(DecimalFromInt(2)).Mul(MustParseDecimal("0.5")); got.String() != "-0.45" || got.Sign() != -1 {
+		t.Fatalf("Sub/Mul: %s", got)
+	}
+	if !(Decimal{}).IsZero() || MustParseDecimal("0.1").Cmp(MustParseDecimal("0.10")) != 0 {
+		t.Fatal("zero value and trailing zeros should compare equal")
+	}
+	if MustParseDecimal("0.2").Cmp(MustParseDecimal("0.15")) != 1 {
+		t.Fatal("0.2 should be greater than 0.15")
+	}
+	for d, want := range map[string]string{"0.125": "0.13", "-0.125": "-0.13", "0.124": "0.12", "3": "3.00", "-0.004": "0.00", "1.999": "2.00"} {
+		if got := MustParseDecimal(d).StringFixed(2); got != want {
+			t.Errorf("StringFixed(%s) = %s, want %s", d, got, want)
+		}
+	}
+	if got := MustParseDecimal("2.5").StringFixed(0); got != "3" {
+		t.Fatalf("StringFixed(0): %s", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+		C Decimal `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":0.00000015,"b":"1e-6","c":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil || string(out) != `{"a":0.00000015,"b":0.000001,"c":0}` {
+		t.Fatalf("Marshal: %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"a":"cheap"}`), &v); err == nil {
+		t.Fatal("expected error for a non-numeric string")
+	}
+}
+
+func TestBigNumberDecimal(t *testing.T) {
+	if d, err := BigNumber("").Decimal(); err != nil || !d.IsZero() {
+		t.Fatalf("empty BigNumber: %s, %v", d, err)
+	}
+	if BigNumber("0.000003").Cmp("0.0000025") != 1 || BigNumber("x").Cmp("0") != -1 {
+		t.Fatal("unexpected BigNumber ordering")
+	}
+	if got := MustParseDecimal("0.00000015").PerMillion().BigNumber(); got != "0.15" {
+		t.Fatalf("BigNumber: %s", got)
+	}
+}
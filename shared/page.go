@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"context"
+	"iter"
+)
+
+// PageFunc fetches up to limit items starting at offset. A limit of 0 asks
+// for the server's default page size.
+type PageFunc[T any] func(ctx context.Context, offset, limit int) ([]T, error)
+
+// PageOptions configures Paginate.
+type PageOptions struct {
+	// Offset is the offset of the first item.
+	Offset int
+	// PageSize is the limit sent with every request. A page shorter than
+	// PageSize is the last one. Zero sends no limit and takes the length of
+	// the first page as the page size.
+	PageSize int
+	// Lookahead is the number of pages fetched ahead of the consumer. Zero
+	// means 1; a negative value fetches each page only when it is needed.
+	Lookahead int
+}
+
+type page[T any] struct {
+	items []T
+	err   error
+}
+
+// Paginate iterates over every item of an offset-paginated list. It stops
+// after a short or empty page, or after yielding the first error. Stopping
+// the iteration early cancels the prefetch.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T], opts PageOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		next := pages(ctx, fetch, opts)
+		for {
+			p, ok := next()
+			if !ok {
+				return
+			}
+			if p.err != nil {
+				var zero T
+				yield(zero, p.err)
+				return
+			}
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// pages returns a function that returns the pages in order. With lookahead a
+// goroutine fetches pages into a buffer until ctx is cancelled.
+func pages[T any](ctx context.Context, fetch PageFunc[T], opts PageOptions) func() (page[T], bool) {
+	offset, size := opts.Offset, opts.PageSize
+	done := false
+	fetchNext := func() (page[T], bool) {
+		if done {
+			return page[T]{}, false
+		}
+		items, err := fetch(ctx, offset, opts.PageSize)
+		if err == nil {
+			err = ctx.Err()
+		}
+		switch {
+		case err != nil:
+			done = true
+			return page[T]{err: err}, true
+		case len(items) == 0:
+			done = true
+			return page[T]{}, false
+		}
+		if size <= 0 {
+			size = len(items)
+		}
+		offset += len(items)
+		done = len(items) < size
+		return page[T]{items: items}, true
+	}
+	if opts.Lookahead < 0 {
+		return fetchNext
+	}
+
+	// An unbuffered channel already holds one page ahead in the goroutine.
+	ch := make(chan page[T], max(opts.Lookahead, 1)-1)
+	// stopped is the error that made the goroutine give up before the last
+	// page; it is written before ch is closed.
+	var stopped error
+	go func() {
+		defer close(ch)
+		for {
+			p, ok := fetchNext()
+			if !ok {
+				return
+			}
+			select {
+			case ch <- p:
+			case <-ctx.Done():
+				stopped = ctx.Err()
+				return
+			}
+		}
+	}()
+	return func() (page[T], bool) {
+		p, ok := <-ch
+		if !ok && stopped != nil {
+			p, ok, stopped = page[T]{err: stopped}, true, nil
+		}
+		return p, ok
+	}
+}
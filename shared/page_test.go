@@ -0,0 +1,144 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// numbers serves the integers below total in pages of at most limit.
+func numbers(total int, calls *atomic.Int32) PageFunc[int] {
+	return func(ctx context.Context, offset, limit int) ([]int, error) {
+		calls.Add(1)
+		if limit == 0 {
+			limit = 4
+		}
+		var out []int
+		for i := offset; i < total && len(out) < limit; i++ {
+			out = append(out, i)
+		}
+		return out, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		total int
+		opts  PageOptions
+		calls int32
+	}{
+		{"short last page", 10, PageOptions{PageSize: 3}, 4},
+		{"learned page size", 10, PageOptions{}, 3},
+		{"exact multiple", 8, PageOptions{Lookahead: -1}, 3},
+		{"offset", 10, PageOptions{Offset: 7, PageSize: 5}, 1},
+		{"empty", 0, PageOptions{PageSize: 5, Lookahead: 3}, 1},
+	} {
+		var calls atomic.Int32
+		var got []int
+		for n, err := range Paginate(ctx, numbers(tc.total, &calls), tc.opts) {
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			got = append(got, n)
+		}
+		if len(got) != tc.total-tc.opts.Offset || (len(got) > 0 && got[0] != tc.opts.Offset) || calls.Load() != tc.calls {
+			t.Errorf("%s: got %v in %d calls, want %d calls", tc.name, got, calls.Load(), tc.calls)
+		}
+	}
+}
+
+func TestPaginateLookahead(t *testing.T) {
+	var calls atomic.Int32
+	seq := Paginate(context.Background(), numbers(1000, &calls), PageOptions{PageSize: 10, Lookahead: 2})
+	for n := range seq {
+		if n == 0 {
+			time.Sleep(20 * time.Millisecond)
+			// The page being consumed plus two pages ahead.
+			if c := calls.Load(); c != 3 {
+				t.Fatalf("expected 3 fetches, got %d", c)
+			}
+		}
+		if n == 15 {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if c := calls.Load(); c > 5 {
+		t.Fatalf("prefetch continued after break: %d fetches", c)
+	}
+}
+
+func TestPaginateErrors(t *testing.T) {
+	boom := errors.New("boom")
+	var got []int
+	var gotErr error
+	for n, err := range Paginate(context.Background(), func(ctx context.Context, offset, limit int) ([]int, error) {
+		if offset >= 4 {
+			return nil, boom
+		}
+		return []int{offset, offset + 1}, nil
+	}, PageOptions{PageSize: 2}) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, n)
+	}
+	if !errors.Is(gotErr, boom) || len(got) != 4 {
+		t.Fatalf("expected 4 items then the error, got %v, %v", got, gotErr)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gotErr = nil
+	for n, err := range Paginate(ctx, numbers(1000, new(atomic.Int32)), PageOptions{PageSize: 10}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		if n == 25 {
+			cancel()
+		}
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", gotErr)
+	}
+}
+
+func TestPaginateCancelAfterLastPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []int
+	for n, err := range Paginate(ctx, numbers(3, new(atomic.Int32)), PageOptions{PageSize: 10}) {
+		if err != nil {
+			t.Fatalf("unexpected error after the last page: %v", err)
+		}
+		got = append(got, n)
+		if n == 2 {
+			cancel()
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestPagesStoppedEarly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	next := pages(ctx, numbers(1000, new(atomic.Int32)), PageOptions{PageSize: 10})
+	if p, ok := next(); !ok || p.err != nil || len(p.items) != 10 {
+		t.Fatalf("unexpected first page: %+v, %v", p, ok)
+	}
+	cancel()
+	var last page[int]
+	for p, ok := next(); ok; p, ok = next() {
+		last = p
+	}
+	if !errors.Is(last.err, context.Canceled) {
+		t.Fatalf("expected the cancellation as the last page, got %+v", last)
+	}
+}
@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusCode returns the HTTP status carried by err: the code of an
+// *APIError, else the status of a *RequestError, else 0.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch code := apiErr.Code.(type) {
+		case float64:
+			if code == math.Trunc(code) {
+				return int(code)
+			}
+		case int:
+			return code
+		case string:
+			n, _ := strconv.Atoi(code)
+			return n
+		}
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
+
+// Retryable reports whether a failed request is worth another attempt:
+// rate limited, timed out, a server error or a transport failure.
+func Retryable(err error) bool {
+	status := StatusCode(err)
+	if status == 0 {
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return status == http.StatusTooManyRequests || status == http.StatusRequestTimeout || status >= http.StatusInternalServerError
+}
+
+// Jitter adds up to 20% to delay, which spreads out the retries of requests
+// that failed together.
+func Jitter(delay time.Duration) time.Duration {
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
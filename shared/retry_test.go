@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestSharedRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		retry  bool
+	}{
+		{&APIError{Code: 429}, 429, true},
+		{&APIError{Code: float64(503)}, 503, true},
+		{&APIError{Code: "400"}, 400, false},
+		{fmt.Errorf("wrapped: %w", &RequestError{HTTPStatusCode: 408}), 408, true},
+		{io.ErrUnexpectedEOF, 0, true},
+		{context.Canceled, 0, false},
+	} {
+		if status := StatusCode(tc.err); status != tc.status {
+			t.Errorf("StatusCode(%v) = %d, want %d", tc.err, status, tc.status)
+		}
+		if retry := Retryable(tc.err); retry != tc.retry {
+			t.Errorf("Retryable(%v) = %v, want %v", tc.err, retry, tc.retry)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package shared
+
+type CostDetails struct {
+	UpstreamInferenceCost       float64 `json:"upstream_inference_cost,omitempty"`
+	UpstreamInferenceInputCost  float64 `json:"upstream_inference_input_cost,omitempty"`
+	UpstreamInferenceOutputCost float64 `json:"upstream_inference_output_cost,omitempty"`
+}
+
+type ServerToolUse struct {
+	WebSearchRequests int `json:"web_search_requests,omitempty"`
+	WebFetchRequests  int `json:"web_fetch_requests,omitempty"`
+}
+
+// NormalizedUsage is a dialect-independent view of token usage and cost.
+// InputTokens always includes cached and cache-write tokens, whatever the
+// upstream dialect reports.
+type NormalizedUsage struct {
+	InputTokens      int           `json:"input_tokens"`
+	OutputTokens     int           `json:"output_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	ReasoningTokens  int           `json:"reasoning_tokens,omitempty"`
+	CachedTokens     int           `json:"cached_tokens,omitempty"`
+	CacheWriteTokens int           `json:"cache_write_tokens,omitempty"`
+	AudioTokens      int           `json:"audio_tokens,omitempty"`
+	ImageTokens      int           `json:"image_tokens,omitempty"`
+	Cost             float64       `json:"cost,omitempty"`
+	UpstreamCost     float64       `json:"upstream_cost,omitempty"`
+	IsBYOK           bool          `json:"is_byok,omitempty"`
+	ServerToolUse    ServerToolUse `json:"server_tool_use,omitzero"`
+}
+
+// Add returns the sum of u and other. IsBYOK is set if either side is BYOK.
+func (u NormalizedUsage) Add(other NormalizedUsage) NormalizedUsage {
+	return NormalizedUsage{
+		InputTokens:      u.InputTokens + other.InputTokens,
+		OutputTokens:     u.OutputTokens + other.OutputTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		ReasoningTokens:  u.ReasoningTokens + other.ReasoningTokens,
+		CachedTokens:     u.CachedTokens + other.CachedTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+		AudioTokens:      u.AudioTokens + other.AudioTokens,
+		ImageTokens:      u.ImageTokens + other.ImageTokens,
+		Cost:             u.Cost + other.Cost,
+		UpstreamCost:     u.UpstreamCost + other.UpstreamCost,
+		IsBYOK:           u.IsBYOK || other.IsBYOK,
+		ServerToolUse: ServerToolUse{
+			WebSearchRequests: u.ServerToolUse.WebSearchRequests + other.ServerToolUse.WebSearchRequests,
+			WebFetchRequests:  u.ServerToolUse.WebFetchRequests + other.ServerToolUse.WebFetchRequests,
+		},
+	}
+}
+
+// SetCost fills the cost fields from an OpenRouter cost report.
+func (u *NormalizedUsage) SetCost(cost float64, isBYOK *bool, details *CostDetails) {
+	u.Cost = cost
+	if isBYOK != nil {
+		u.IsBYOK = *isBYOK
+	}
+	if details != nil {
+		u.UpstreamCost = details.UpstreamInferenceCost
+		if u.UpstreamCost == 0 {
+			u.UpstreamCost = details.UpstreamInferenceInputCost + details.UpstreamInferenceOutputCost
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package tokens estimates prompt tokens before a request is sent, using
+// counters keyed by the tokenizer family reported in the model catalog.
+//
+// Only the GPT, Claude and Llama3 families have built-in counters, each
+// checked against native prompt token counts in testdata. Other families,
+// including Llama4, Grok, Gemini, Mistral and Qwen, are out of scope and use
+// Fallback unless a counter is registered for them.
+package tokens
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/iamwavecut/gopenrouter"
+)
+
+// ImageCost is the token cost of one image by detail level. Auto and unset
+// detail use High.
+type ImageCost struct {
+	Low  int
+	High int
+}
+
+// Counter counts the prompt tokens of chat requests for one tokenizer
+// family: the text of every part plus the framing the provider adds around
+// messages, tool definitions and media.
+type Counter struct {
+	Family string
+	// Text counts plain text. Nil means Heuristic{}.
+	Text TextCounter
+
+	// MessageOverhead is added per message, including its role.
+	MessageOverhead int
+	// RequestOverhead is added once, e.g. for the assistant reply primer.
+	RequestOverhead int
+	// ToolCallOverhead is added per tool call in an assistant message.
+	ToolCallOverhead int
+	// ToolsOverhead is added once when the request defines tools, e.g. for
+	// an injected tool-use system prompt.
+	ToolsOverhead int
+	// ToolOverhead is added per tool definition on top of its schema.
+	ToolOverhead int
+
+	Image ImageCost
+	// File is the cost of a binary file such as a PDF. Text files sent as
+	// data URLs are counted as text.
+	File  int
+	Audio int
+	Video int
+}
+
+// CountText counts text with the family's text counter.
+func (c Counter) CountText(text string) int {
+	if c.Text == nil {
+		return Heuristic{}.CountText(text)
+	}
+	return c.Text.CountText(text)
+}
+
+// CountMessage counts one message. Reasoning is not counted because
+// providers drop it from earlier turns. It matches the signature of
+// conversation.Config.Count.
+func (c Counter) CountMessage(msg gopenrouter.ChatCompletionMessage) int {
+	n := c.MessageOverhead + c.CountText(msg.Content)
+	if msg.Name != "" {
+		n += 1 + c.CountText(msg.Name)
+	}
+	for _, part := range msg.MultiContent {
+		n += c.countPart(part)
+	}
+	for _, call := range msg.ToolCalls {
+		n += c.ToolCallOverhead + c.CountText(call.Function.Name) + c.CountText(call.Function.Arguments)
+	}
+	if msg.ToolCallID != "" {
+		n += c.CountText(msg.ToolCallID)
+	}
+	return n
+}
+
+func (c Counter) countPart(part gopenrouter.ChatCompletionMessagePart) int {
+	switch {
+	case part.ImageURL != nil:
+		if part.ImageURL.Detail == "low" {
+			return c.Image.Low
+		}
+		return c.Image.High
+	case part.File != nil:
+		if text, ok := textFile(part.File.FileData); ok {
+			return c.CountText(part.File.Filename) + c.CountText(text)
+		}
+		return c.File
+	case part.InputAudio != nil:
+		return c.Audio
+	case part.VideoURL != nil:
+		return c.Video
+	default:
+		return c.CountText(part.Text)
+	}
+}
+
+// CountTools counts tool definitions.
+func (c Counter) CountTools(tools []gopenrouter.Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	n := c.ToolsOverhead
+	for _, tool := range tools {
+		n += c.ToolOverhead + c.CountText(tool.Function.Name) + c.CountText(tool.Function.Description)
+		if tool.Function.Parameters != nil {
+			n += c.countSchema(tool.Function.Parameters)
+		}
+	}
+	return n
+}
+
+// CountRequest counts the prompt tokens of req: its messages, tool
+// definitions and response schema.
+func (c Counter) CountRequest(req gopenrouter.ChatCompletionRequest) int {
+	n := c.RequestOverhead
+	for _, msg := range req.Messages {
+		n += c.CountMessage(msg)
+	}
+	n += c.CountTools(req.Tools)
+	if rf := req.ResponseFormat; rf != nil {
+		if rf.JSONSchema != nil {
+			n += c.CountText(rf.JSONSchema.Name) + c.countSchema(rf.JSONSchema.Schema)
+		}
+		n += c.CountText(rf.Grammar)
+	}
+	return n
+}
+
+// countSchema counts a JSON schema the way providers render it into the
+// prompt: keys, strings and values with light punctuation rather than raw
+// JSON.
+func (c Counter) countSchema(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return c.CountText(v)
+	case map[string]any:
+		n := 1
+		for key, value := range v {
+			n += c.CountText(key) + 1 + c.countSchema(value)
+		}
+		return n
+	case []any:
+		n := 1
+		for _, value := range v {
+			n += c.countSchema(value)
+		}
+		return n
+	case bool, float64, json.Number:
+		return 1
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		var decoded any
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			return c.CountText(string(b))
+		}
+		return c.countSchema(decoded)
+	}
+}
+
+// textFile decodes a base64 data URL whose media type is textual.
+func textFile(data string) (string, bool) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(data, "data:"), ",")
+	if !ok || !strings.HasPrefix(data, "data:") || !strings.HasSuffix(header, ";base64") {
+		return "", false
+	}
+	mediaType := strings.TrimSuffix(header, ";base64")
+	if !strings.HasPrefix(mediaType, "text/") && !strings.HasSuffix(mediaType, "json") &&
+		!strings.HasSuffix(mediaType, "xml") && !strings.HasSuffix(mediaType, "yaml") {
+		return "", false
+	}
+	b, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,123 @@
+package tokens
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/iamwavecut/gopenrouter/catalog"
+)
+
+// Tokenizer families as reported by catalog.ModelArchitecture.Tokenizer.
+// Families without a built-in counter use Fallback until one is registered.
+const (
+	FamilyGPT    = "GPT"
+	FamilyClaude = "Claude"
+	FamilyLlama3 = "Llama3"
+	FamilyOther  = "Other"
+)
+
+var (
+	// GPT follows OpenAI's chat framing: three tokens per message plus the
+	// role, three for the reply primer and 85/765 tokens per low/high detail
+	// image at 1024x1024.
+	GPT = Counter{
+		Family:           FamilyGPT,
+		Text:             Heuristic{},
+		MessageOverhead:  4,
+		RequestOverhead:  3,
+		ToolCallOverhead: 6,
+		ToolsOverhead:    12,
+		ToolOverhead:     8,
+		Image:            ImageCost{Low: 85, High: 765},
+		File:             1500,
+		Audio:            600,
+		Video:            3000,
+	}
+	// Claude's tokenizer splits English about 20% finer than GPT's, tool use
+	// adds a system prompt of about 350 tokens and images cost about
+	// width*height/750 tokens, 1600 at the 1092x1092 maximum.
+	Claude = Counter{
+		Family:           FamilyClaude,
+		Text:             Heuristic{Margin: 1.2},
+		MessageOverhead:  2,
+		RequestOverhead:  1,
+		ToolCallOverhead: 10,
+		ToolsOverhead:    346,
+		ToolOverhead:     10,
+		Image:            ImageCost{Low: 1600, High: 1600},
+		File:             2500,
+		Audio:            1000,
+		Video:            4000,
+	}
+	// Llama3 frames every message with header and end-of-turn tokens and
+	// primes the reply with an assistant header.
+	Llama3 = Counter{
+		Family:           FamilyLlama3,
+		Text:             Heuristic{},
+		MessageOverhead:  5,
+		RequestOverhead:  5,
+		ToolCallOverhead: 8,
+		ToolsOverhead:    60,
+		ToolOverhead:     10,
+		Image:            ImageCost{Low: 1600, High: 1600},
+		File:             2000,
+		Audio:            1000,
+		Video:            4000,
+	}
+	// Fallback is used for unknown families. It overestimates every
+	// built-in family so budgets computed with it are safe.
+	Fallback = Counter{
+		Family:           FamilyOther,
+		Text:             Heuristic{Margin: 1.3},
+		MessageOverhead:  8,
+		RequestOverhead:  8,
+		ToolCallOverhead: 12,
+		ToolsOverhead:    400,
+		ToolOverhead:     16,
+		Image:            ImageCost{Low: 1600, High: 1600},
+		File:             3000,
+		Audio:            1500,
+		Video:            5000,
+	}
+)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Counter{}
+)
+
+func init() {
+	for family, counter := range map[string]Counter{
+		FamilyGPT:    GPT,
+		FamilyClaude: Claude,
+		FamilyLlama3: Llama3,
+	} {
+		Register(family, counter)
+	}
+}
+
+// Register sets the counter for a tokenizer family, replacing any previous
+// one. Family names are matched case-insensitively.
+func Register(family string, counter Counter) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[strings.ToLower(family)] = counter
+}
+
+// ForFamily returns the counter registered for family, or Fallback.
+func ForFamily(family string) Counter {
+	mu.RLock()
+	defer mu.RUnlock()
+	if counter, ok := registry[strings.ToLower(family)]; ok {
+		return counter
+	}
+	return Fallback
+}
+
+// ForModel returns the counter for the model's tokenizer family.
+func ForModel(m catalog.Model) Counter {
+	if m.Architecture == nil {
+		return Fallback
+	}
+	return ForFamily(m.Architecture.Tokenizer)
+}
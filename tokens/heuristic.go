@@ -0,0 +1,124 @@
+package tokens
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextCounter counts the tokens of plain text. Plug in a real tokenizer by
+// implementing it and setting Counter.Text.
+type TextCounter interface {
+	CountText(text string) int
+}
+
+// TextCounterFunc adapts a function to TextCounter.
+type TextCounterFunc func(text string) int
+
+func (f TextCounterFunc) CountText(text string) int { return f(text) }
+
+// Heuristic approximates a BPE tokenizer without a vocabulary. Words cost a
+// token per WordChars letters, numbers a token per DigitsPerToken digits,
+// punctuation a token per two marks, CJK a token per character and other
+// symbols two tokens each. Single spaces are free; other whitespace runs
+// cost one token. The total is scaled by Margin.
+type Heuristic struct {
+	// WordChars is the average number of letters per word token. Zero means 6.
+	WordChars float64
+	// DigitsPerToken is the size of the digit groups. Zero means 3.
+	DigitsPerToken int
+	// Margin scales the count; 1.2 overestimates by 20%. Zero means 1.
+	Margin float64
+}
+
+func (h Heuristic) CountText(text string) int {
+	if text == "" {
+		return 0
+	}
+	wordChars := h.WordChars
+	if wordChars <= 0 {
+		wordChars = 6
+	}
+	digits := h.DigitsPerToken
+	if digits <= 0 {
+		digits = 3
+	}
+
+	var n float64
+	for i := 0; i < len(text); {
+		r, _ := utf8.DecodeRuneInString(text[i:])
+		class := classify(r)
+		run, plain := 0, true
+		for i < len(text) {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if classify(r) != class {
+				break
+			}
+			plain = plain && r == ' '
+			run++
+			i += size
+		}
+		switch class {
+		case classWord:
+			n += math.Max(1, math.Round(float64(run)/wordChars))
+		case classNonLatin:
+			// Scripts like Cyrillic or Greek split about twice as often.
+			n += math.Max(1, math.Round(2*float64(run)/wordChars))
+		case classDigit:
+			n += float64((run + digits - 1) / digits)
+		case classPunct:
+			n += float64((run + 1) / 2)
+		case classSpace:
+			if run > 1 || !plain {
+				n++
+			}
+		case classCJK:
+			n += float64(run)
+		default:
+			n += 2 * float64(run)
+		}
+	}
+	margin := h.Margin
+	if margin <= 0 {
+		margin = 1
+	}
+	return int(math.Ceil(n * margin))
+}
+
+const (
+	classWord = iota
+	classNonLatin
+	classDigit
+	classPunct
+	classSpace
+	classCJK
+	classSymbol
+)
+
+func classify(r rune) int {
+	switch {
+	case r < utf8.RuneSelf:
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '\'':
+			return classWord
+		case '0' <= r && r <= '9':
+			return classDigit
+		case unicode.IsSpace(r):
+			return classSpace
+		default:
+			return classPunct
+		}
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return classCJK
+	case unicode.Is(unicode.Latin, r):
+		return classWord
+	case unicode.IsLetter(r) || unicode.IsMark(r):
+		return classNonLatin
+	case unicode.IsSpace(r):
+		return classSpace
+	case unicode.IsPunct(r):
+		return classPunct
+	default:
+		return classSymbol
+	}
+}
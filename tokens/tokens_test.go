@@ -0,0 +1,126 @@
+package tokens
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/catalog"
+)
+
+type fixture struct {
+	Name       string                            `json:"name"`
+	Tokenizer  string                            `json:"tokenizer"`
+	Request    gopenrouter.ChatCompletionRequest `json:"request"`
+	Generation gopenrouter.Generation            `json:"generation"`
+}
+
+func loadFixtures(t *testing.T) []fixture {
+	t.Helper()
+	b, err := os.ReadFile("testdata/native_prompt_tokens.json")
+	if err != nil {
+		t.Fatalf("read fixtures: %v", err)
+	}
+	var fixtures []fixture
+	if err := json.Unmarshal(b, &fixtures); err != nil {
+		t.Fatalf("decode fixtures: %v", err)
+	}
+	return fixtures
+}
+
+func TestCountRequestMatchesNativeTokens(t *testing.T) {
+	const tolerance = 0.2
+	for _, f := range loadFixtures(t) {
+		t.Run(f.Name, func(t *testing.T) {
+			native := f.Generation.NativePromptTokens
+			got := ForFamily(f.Tokenizer).CountRequest(f.Request)
+			if diff := math.Abs(float64(got-native)) / float64(native); diff > tolerance {
+				t.Errorf("%s counted %d tokens, native %d (off by %.0f%%)", f.Tokenizer, got, native, diff*100)
+			}
+			if fallback := Fallback.CountRequest(f.Request); fallback < native {
+				t.Errorf("fallback counted %d tokens, below native %d", fallback, native)
+			}
+		})
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	for text, want := range map[string]int{
+		"":                               0,
+		"Hello!":                         2,
+		"What is the capital of France?": 7,
+		"1234567":                        3,
+		"line one\n\tline two":           5,
+		"日本語":                            3,
+	} {
+		if got := (Heuristic{}).CountText(text); got != want {
+			t.Errorf("CountText(%q) = %d, want %d", text, got, want)
+		}
+	}
+	if got := (Heuristic{Margin: 1.5}).CountText("What is the capital of France?"); got != 11 {
+		t.Errorf("margin not applied: %d", got)
+	}
+}
+
+func TestCountParts(t *testing.T) {
+	image := func(detail string) gopenrouter.ChatCompletionMessagePart {
+		return gopenrouter.ChatCompletionMessagePart{Type: "image_url", ImageURL: &gopenrouter.ImageURL{URL: "https://example.com/a.png", Detail: detail}}
+	}
+	for detail, want := range map[string]int{"low": 85, "high": 765, "auto": 765, "": 765} {
+		if got := GPT.countPart(image(detail)); got != want {
+			t.Errorf("detail %q costs %d, want %d", detail, got, want)
+		}
+	}
+
+	text := strings.Repeat("plain text file contents ", 40)
+	textFile := gopenrouter.ChatCompletionMessagePart{Type: "file", File: &gopenrouter.File{
+		Filename: "notes.txt",
+		FileData: "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte(text)),
+	}}
+	if got := GPT.countPart(textFile); got != GPT.CountText("notes.txt")+GPT.CountText(text) {
+		t.Errorf("text file should be counted as text, got %d", got)
+	}
+	pdf := gopenrouter.ChatCompletionMessagePart{Type: "file", File: &gopenrouter.File{Filename: "doc.pdf", FileData: "data:application/pdf;base64,JVBERi0="}}
+	if got := GPT.countPart(pdf); got != GPT.File {
+		t.Errorf("binary file costs %d, want %d", got, GPT.File)
+	}
+}
+
+func TestCountRequestTools(t *testing.T) {
+	req := gopenrouter.ChatCompletionRequest{Messages: []gopenrouter.ChatCompletionMessage{{Role: gopenrouter.RoleUser, Content: "hi"}}}
+	base := Claude.CountRequest(req)
+	req.Tools = []gopenrouter.Tool{{Type: "function", Function: gopenrouter.Function{Name: "noop"}}}
+	if got := Claude.CountRequest(req); got-base < Claude.ToolsOverhead {
+		t.Errorf("tools overhead missing: %d -> %d", base, got)
+	}
+	req.ResponseFormat = &gopenrouter.ResponseFormat{Type: "json_schema", JSONSchema: &gopenrouter.JSONSchema{Name: "answer", Schema: map[string]any{"type": "object"}}}
+	if Claude.CountRequest(req) <= base+Claude.ToolsOverhead {
+		t.Error("response schema not counted")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if ForFamily("gpt").Family != FamilyGPT || ForFamily("llama3").Family != FamilyLlama3 {
+		t.Fatal("built-in families not registered")
+	}
+	for _, family := range []string{"Unknown", "Gemini", "Llama4", "Grok"} {
+		if ForFamily(family).Family != FamilyOther {
+			t.Fatalf("family %s should use the fallback", family)
+		}
+	}
+	custom := GPT
+	custom.Family = "Custom"
+	custom.Text = TextCounterFunc(func(text string) int { return len(text) })
+	Register("Custom", custom)
+	model := catalog.Model{Architecture: &catalog.ModelArchitecture{Tokenizer: "custom"}}
+	if got := ForModel(model).CountText("abcd"); got != 4 {
+		t.Fatalf("registered counter not used: %d", got)
+	}
+	if ForModel(catalog.Model{}).Family != FamilyOther {
+		t.Fatal("models without architecture should use the fallback")
+	}
+}
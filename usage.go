@@ -0,0 +1,30 @@
+package gopenrouter
+
+import "github.com/iamwavecut/gopenrouter/shared"
+
+// Normalized returns the dialect-independent view of u.
+func (u Usage) Normalized() shared.NormalizedUsage {
+	out := shared.NormalizedUsage{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+		TotalTokens:  u.TotalTokens,
+		ImageTokens:  u.ImageTokens,
+	}
+	if d := u.PromptTokensDetails; d != nil {
+		out.CachedTokens = d.CachedTokens
+		out.CacheWriteTokens = d.CacheWriteTokens
+		out.AudioTokens += d.AudioTokens
+	}
+	if d := u.CompletionTokensDetails; d != nil {
+		out.ReasoningTokens = d.ReasoningTokens
+		out.AudioTokens += d.AudioTokens
+	}
+	if u.ServerToolUse != nil {
+		out.ServerToolUse = *u.ServerToolUse
+	}
+	out.SetCost(u.Cost, u.IsBYOK, u.CostDetails)
+	if out.TotalTokens == 0 {
+		out.TotalTokens = out.InputTokens + out.OutputTokens
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package gopenrouter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter/anthropic"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+	"github.com/iamwavecut/gopenrouter/responses"
+	"github.com/iamwavecut/gopenrouter/shared"
+)
+
+func TestNormalizedUsage(t *testing.T) {
+	var chat Usage
+	chatPayload := `{"prompt_tokens":100,"completion_tokens":20,"total_tokens":120,"cost":0.5,"is_byok":true,"cost_details":{"upstream_inference_cost":0.4},"server_tool_use":{"web_search_requests":2},"prompt_tokens_details":{"cached_tokens":60,"cache_write_tokens":10},"completion_tokens_details":{"reasoning_tokens":8}}`
+	if err := json.Unmarshal([]byte(chatPayload), &chat); err != nil {
+		t.Fatalf("unmarshal chat usage: %v", err)
+	}
+
+	var resp responses.Usage
+	respPayload := `{"input_tokens":100,"output_tokens":20,"total_tokens":120,"input_tokens_details":{"cached_tokens":60,"cache_write_tokens":10},"output_tokens_details":{"reasoning_tokens":8},"cost":0.5,"is_byok":true,"cost_details":{"upstream_inference_cost":0.4},"server_tool_use":{"web_search_requests":2}}`
+	if err := json.Unmarshal([]byte(respPayload), &resp); err != nil {
+		t.Fatalf("unmarshal responses usage: %v", err)
+	}
+
+	var msg anthropic.Usage
+	msgPayload := `{"input_tokens":30,"output_tokens":20,"cache_read_input_tokens":60,"cache_creation_input_tokens":10,"cost":0.5,"is_byok":true,"cost_details":{"upstream_inference_cost":0.4},"server_tool_use":{"web_search_requests":2},"service_tier":"standard"}`
+	if err := json.Unmarshal([]byte(msgPayload), &msg); err != nil {
+		t.Fatalf("unmarshal anthropic usage: %v", err)
+	}
+
+	want := shared.NormalizedUsage{
+		InputTokens:      100,
+		OutputTokens:     20,
+		TotalTokens:      120,
+		CachedTokens:     60,
+		CacheWriteTokens: 10,
+		Cost:             0.5,
+		UpstreamCost:     0.4,
+		IsBYOK:           true,
+		ServerToolUse:    shared.ServerToolUse{WebSearchRequests: 2},
+	}
+	if got := msg.Normalized(); got != want {
+		t.Fatalf("anthropic usage: got %+v, want %+v", got, want)
+	}
+	want.ReasoningTokens = 8
+	if got := chat.Normalized(); got != want {
+		t.Fatalf("chat usage: got %+v, want %+v", got, want)
+	}
+	if got := resp.Normalized(); got != want {
+		t.Fatalf("responses usage: got %+v, want %+v", got, want)
+	}
+
+	emb := embeddings.Usage{PromptTokens: 7, TotalTokens: 7, Cost: 0.1}
+	if got := emb.Normalized(); got.InputTokens != 7 || got.TotalTokens != 7 || got.Cost != 0.1 {
+		t.Fatalf("unexpected embeddings usage: %+v", got)
+	}
+
+	sum := want.Add(want)
+	if sum.InputTokens != 200 || sum.Cost != 1 || sum.ServerToolUse.WebSearchRequests != 4 || !sum.IsBYOK {
+		t.Fatalf("unexpected sum: %+v", sum)
+	}
+}
@@ -0,0 +1,145 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/iamwavecut/gopenrouter/embeddings"
+)
+
+// TextKey is the metadata key under which a Collection stores document text
+// when CollectionConfig.StoreText is set.
+const TextKey = "text"
+
+// Document is a text to index with its ID and metadata.
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// CollectionConfig configures a Collection.
+type CollectionConfig struct {
+	// StoreText keeps each document's text in its metadata under TextKey,
+	// so hits can be pasted into a prompt directly.
+	StoreText bool
+	// DocumentInputType and QueryInputType set Request.InputType for
+	// models that embed documents and queries differently.
+	DocumentInputType string
+	QueryInputType    string
+	// Batch configures how documents are embedded.
+	Batch embeddings.BatchConfig
+}
+
+// Collection embeds documents and queries with one model and searches them
+// in an index.
+type Collection struct {
+	client *embeddings.Client
+	req    embeddings.Request
+	index  Index
+	config CollectionConfig
+}
+
+// NewCollection returns a collection that embeds with req as the template
+// for every request; req.Input is ignored and req.Model is required. Index
+// may be nil for a Flat index.
+func NewCollection(client *embeddings.Client, req embeddings.Request, index Index, config CollectionConfig) (*Collection, error) {
+	if req.Model == "" {
+		return nil, errors.New("vector: collection requires a model")
+	}
+	if index == nil {
+		index = NewFlat(req.Dimensions)
+	}
+	if req.EncodingFormat == "" {
+		req.EncodingFormat = embeddings.EncodingBase64
+	}
+	return &Collection{client: client, req: req, index: index, config: config}, nil
+}
+
+// LoadCollection opens a collection saved with Save. An empty req.Model
+// takes the model recorded in the file; a different model is an error,
+// since its vectors would not be comparable.
+func LoadCollection(client *embeddings.Client, path string, req embeddings.Request, config CollectionConfig) (*Collection, error) {
+	index, model, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if req.Model == "" {
+		req.Model = model
+	} else if req.Model != model {
+		return nil, fmt.Errorf("vector: %s was embedded with %q, not %q", path, model, req.Model)
+	}
+	return NewCollection(client, req, index, config)
+}
+
+// Index returns the underlying index.
+func (c *Collection) Index() Index { return c.index }
+
+// Model returns the embedding model of the collection.
+func (c *Collection) Model() string { return c.req.Model }
+
+// Add embeds docs and indexes them. Documents before the first one that
+// fails to index stay indexed.
+func (c *Collection) Add(ctx context.Context, docs ...Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	texts := make([]string, len(docs))
+	for i, d := range docs {
+		texts[i] = d.Text
+	}
+	req := c.req
+	if c.config.DocumentInputType != "" {
+		req.InputType = c.config.DocumentInputType
+	}
+	resp, err := c.client.CreateBatch(ctx, req, texts, c.config.Batch)
+	if err != nil {
+		return err
+	}
+	vectors, err := resp.Float32s()
+	if err != nil {
+		return err
+	}
+	for i, d := range docs {
+		metadata := d.Metadata
+		if c.config.StoreText {
+			metadata = maps.Clone(metadata)
+			if metadata == nil {
+				metadata = make(map[string]string, 1)
+			}
+			metadata[TextKey] = d.Text
+		}
+		if err := c.index.Add(d.ID, vectors[i], metadata); err != nil {
+			return fmt.Errorf("vector: document %q: %w", d.ID, err)
+		}
+	}
+	return nil
+}
+
+// Query embeds text and returns the k most similar documents.
+func (c *Collection) Query(ctx context.Context, text string, k int) ([]Hit, error) {
+	req := c.req
+	req.Input = text
+	if c.config.QueryInputType != "" {
+		req.InputType = c.config.QueryInputType
+	}
+	resp, err := c.client.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != 1 {
+		return nil, fmt.Errorf("vector: expected 1 query embedding, got %d", len(resp.Data))
+	}
+	q, err := resp.Data[0].Embedding.Float32()
+	if err != nil {
+		return nil, err
+	}
+	return c.index.Search(q, k)
+}
+
+// Save writes the index and model to path.
+func (c *Collection) Save(path string) error {
+	return Save(path, c.index, c.req.Model)
+}
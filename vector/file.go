@@ -0,0 +1,311 @@
+package vector
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// The file format is a header (magic, version, index kind, model, dims,
+// count), then per vector its ID, metadata and little-endian float32s; an
+// HNSW file adds its parameters and links. Integers are uvarints.
+const (
+	fileMagic   = "GOVX"
+	fileVersion = 1
+
+	kindFlat = 1
+	kindHNSW = 2
+
+	// maxString bounds strings read from a file.
+	maxString = 1 << 24
+)
+
+var errCorrupt = errors.New("vector: corrupt index file")
+
+func (f *Flat) kind() byte { return kindFlat }
+func (h *HNSW) kind() byte { return kindHNSW }
+
+// Write encodes index to w. Model records the embedding model of the
+// vectors; Read returns it.
+func Write(w io.Writer, index Index, model string) error {
+	enc := &encoder{w: bufio.NewWriter(w)}
+	enc.w.WriteString(fileMagic)
+	enc.w.WriteByte(fileVersion)
+	enc.w.WriteByte(index.kind())
+	enc.string(model)
+	index.encode(enc)
+	if enc.err != nil {
+		return enc.err
+	}
+	return enc.w.Flush()
+}
+
+// Read decodes an index written by Write and returns it with its model.
+func Read(r io.Reader) (Index, string, error) {
+	dec := &decoder{r: bufio.NewReader(r)}
+	header := make([]byte, len(fileMagic)+2)
+	if _, err := io.ReadFull(dec.r, header); err != nil || string(header[:len(fileMagic)]) != fileMagic {
+		return nil, "", fmt.Errorf("vector: not an index file")
+	}
+	if header[len(fileMagic)] != fileVersion {
+		return nil, "", fmt.Errorf("vector: unsupported index file version %d", header[len(fileMagic)])
+	}
+	model := dec.string()
+	var index Index
+	switch header[len(fileMagic)+1] {
+	case kindFlat:
+		index = decodeFlat(dec)
+	case kindHNSW:
+		index = decodeHNSW(dec)
+	default:
+		return nil, "", errCorrupt
+	}
+	if dec.err != nil {
+		if errors.Is(dec.err, io.EOF) {
+			dec.err = io.ErrUnexpectedEOF
+		}
+		return nil, "", fmt.Errorf("vector: read index: %w", dec.err)
+	}
+	return index, model, nil
+}
+
+// Save writes index to path, replacing it atomically.
+func Save(path string, index Index, model string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vector-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(tmp, index, model); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads an index saved with Save.
+func Load(path string) (Index, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) uvarint(v uint64) {
+	if e.err == nil {
+		_, e.err = e.w.Write(e.buf[:binary.PutUvarint(e.buf[:], v)])
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *encoder) floats(v []float32) {
+	for _, f := range v {
+		binary.LittleEndian.PutUint32(e.buf[:4], math.Float32bits(f))
+		if e.err == nil {
+			_, e.err = e.w.Write(e.buf[:4])
+		}
+	}
+}
+
+func (e *encoder) metadata(m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	e.uvarint(uint64(len(keys)))
+	for _, k := range keys {
+		e.string(k)
+		e.string(m[k])
+	}
+}
+
+// items writes the vectors shared by both index kinds.
+func (e *encoder) items(dims int, ids []string, metadata []map[string]string, vectors []float32) {
+	e.uvarint(uint64(dims))
+	e.uvarint(uint64(len(ids)))
+	for i, id := range ids {
+		e.string(id)
+		e.metadata(metadata[i])
+		e.floats(vectors[i*dims : (i+1)*dims])
+	}
+}
+
+type decoder struct {
+	r   *bufio.Reader
+	buf [4]byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+// count reads a length and rejects ones above limit.
+func (d *decoder) count(limit uint64) int {
+	n := d.uvarint()
+	if n > limit && d.err == nil {
+		d.err = errCorrupt
+	}
+	if d.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.count(maxString)
+	if d.err != nil || n == 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, buf)
+	return string(buf)
+}
+
+func (d *decoder) floats(dst []float32) {
+	for i := range dst {
+		if d.err != nil {
+			return
+		}
+		if _, d.err = io.ReadFull(d.r, d.buf[:]); d.err == nil {
+			dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(d.buf[:]))
+		}
+	}
+}
+
+func (d *decoder) metadata() map[string]string {
+	n := d.count(maxString)
+	if n == 0 {
+		return nil
+	}
+	m := make(map[string]string, min(n, 64))
+	for range n {
+		k := d.string()
+		m[k] = d.string()
+		if d.err != nil {
+			return nil
+		}
+	}
+	return m
+}
+
+// items reads the vectors shared by both index kinds. Slices grow as data
+// arrives, so a corrupt count cannot force a huge allocation.
+func (d *decoder) items() (dims int, ids []string, metadata []map[string]string, vectors []float32) {
+	dims = d.count(1 << 20)
+	n := d.count(math.MaxUint32)
+	vector := make([]float32, dims)
+	for i := 0; i < n && d.err == nil; i++ {
+		ids = append(ids, d.string())
+		metadata = append(metadata, d.metadata())
+		d.floats(vector)
+		vectors = append(vectors, vector...)
+	}
+	return dims, ids, metadata, vectors
+}
+
+func (f *Flat) encode(e *encoder) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	e.items(f.dims, f.ids, f.metadata, f.vectors)
+}
+
+func decodeFlat(d *decoder) *Flat {
+	f := NewFlat(0)
+	f.dims, f.ids, f.metadata, f.vectors = d.items()
+	for i, id := range f.ids {
+		f.byID[id] = i
+	}
+	return f
+}
+
+func (h *HNSW) encode(e *encoder) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	e.items(h.dims, h.ids, h.metadata, h.vectors)
+	e.uvarint(uint64(h.config.M))
+	e.uvarint(uint64(h.config.EfConstruction))
+	e.uvarint(uint64(h.config.EfSearch))
+	e.uvarint(h.config.Seed)
+	e.uvarint(uint64(h.entry))
+	e.uvarint(uint64(h.maxLevel))
+	for _, layers := range h.links {
+		e.uvarint(uint64(len(layers)))
+		for _, links := range layers {
+			e.uvarint(uint64(len(links)))
+			for _, n := range links {
+				e.uvarint(uint64(n))
+			}
+		}
+	}
+}
+
+func decodeHNSW(d *decoder) *HNSW {
+	dims, ids, metadata, vectors := d.items()
+	config := HNSWConfig{M: d.count(1 << 16), EfConstruction: d.count(1 << 20), EfSearch: d.count(1 << 20), Seed: d.uvarint()}
+	if d.err == nil && config.M < 2 {
+		d.err = errCorrupt
+	}
+	h := NewHNSW(dims, config)
+	h.ids, h.metadata, h.vectors = ids, metadata, vectors
+	h.entry = uint32(d.count(uint64(max(len(ids)-1, 0))))
+	h.maxLevel = d.count(maxLevel)
+	for i := 0; i < len(ids) && d.err == nil; i++ {
+		h.byID[ids[i]] = uint32(i)
+		layers := make([][]uint32, d.count(maxLevel+1))
+		if len(layers) == 0 && d.err == nil {
+			d.err = errCorrupt
+		}
+		for l := range layers {
+			links := make([]uint32, d.count(uint64(2*config.M)))
+			for j := range links {
+				links[j] = uint32(d.count(uint64(len(ids) - 1)))
+			}
+			layers[l] = links
+		}
+		h.links = append(h.links, layers)
+	}
+	if d.err == nil && len(ids) > 0 && len(h.links[h.entry]) <= h.maxLevel {
+		d.err = errCorrupt
+	}
+	// A link must point to a node that exists on its layer.
+	for _, layers := range h.links {
+		for l, links := range layers {
+			for _, n := range links {
+				if d.err == nil && len(h.links[n]) <= l {
+					d.err = errCorrupt
+				}
+			}
+		}
+	}
+	return h
+}
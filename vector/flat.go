@@ -0,0 +1,100 @@
+package vector
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Flat is an exact index that compares the query with every vector. It is
+// the right choice up to a few hundred thousand vectors.
+type Flat struct {
+	mu       sync.RWMutex
+	dims     int
+	vectors  []float32
+	ids      []string
+	metadata []map[string]string
+	byID     map[string]int
+}
+
+// NewFlat returns an empty index. Zero dims takes the dimensions of the
+// first vector added.
+func NewFlat(dims int) *Flat {
+	return &Flat{dims: dims, byID: make(map[string]int)}
+}
+
+func (f *Flat) Add(id string, v []float32, metadata map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.byID[id]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateID, id)
+	}
+	unit, err := normalized(v, f.dims)
+	if err != nil {
+		return err
+	}
+	f.dims = len(unit)
+	f.byID[id] = len(f.ids)
+	f.ids = append(f.ids, id)
+	f.metadata = append(f.metadata, metadata)
+	f.vectors = append(f.vectors, unit...)
+	return nil
+}
+
+// Remove deletes id from the index and reports whether it was present.
+func (f *Flat) Remove(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	i, ok := f.byID[id]
+	if !ok {
+		return false
+	}
+	last := len(f.ids) - 1
+	if i != last {
+		// Move the last vector into the hole.
+		copy(f.vector(i), f.vector(last))
+		f.ids[i], f.metadata[i] = f.ids[last], f.metadata[last]
+		f.byID[f.ids[i]] = i
+	}
+	delete(f.byID, id)
+	f.ids, f.metadata = f.ids[:last], f.metadata[:last]
+	f.vectors = f.vectors[:last*f.dims]
+	return true
+}
+
+func (f *Flat) vector(i int) []float32 {
+	return f.vectors[i*f.dims : (i+1)*f.dims]
+}
+
+func (f *Flat) Search(query []float32, k int) ([]Hit, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.ids) == 0 || k <= 0 {
+		return nil, nil
+	}
+	q, err := normalized(query, f.dims)
+	if err != nil {
+		return nil, err
+	}
+	top := make(minHeap, 0, min(k, len(f.ids)))
+	for i := range f.ids {
+		top.offer(scored{node: uint32(i), score: Dot(q, f.vector(i))}, k)
+	}
+	best := top.sorted()
+	hits := make([]Hit, len(best))
+	for i, s := range best {
+		hits[i] = Hit{ID: f.ids[s.node], Score: s.score, Metadata: f.metadata[s.node]}
+	}
+	return hits, nil
+}
+
+func (f *Flat) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.ids)
+}
+
+func (f *Flat) Dims() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.dims
+}
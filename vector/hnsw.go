@@ -0,0 +1,223 @@
+package vector
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// HNSWConfig configures an HNSW index.
+type HNSWConfig struct {
+	// M is the number of links per node above the bottom layer, which has
+	// 2*M. Zero means 16; values below 2 are raised to 2.
+	M int
+	// EfConstruction is the candidate list size while inserting. Zero
+	// means 200.
+	EfConstruction int
+	// EfSearch is the candidate list size while searching; higher is more
+	// accurate and slower. Zero means 64.
+	EfSearch int
+	// Seed makes the layer assignment reproducible.
+	Seed uint64
+}
+
+// maxLevel caps the layer of a node.
+const maxLevel = 64
+
+// HNSW is an approximate index over a hierarchical navigable small world
+// graph. Search cost grows roughly with the logarithm of the size. Adds
+// are serialized; searches run concurrently.
+type HNSW struct {
+	mu       sync.RWMutex
+	config   HNSWConfig
+	rng      *rand.Rand
+	dims     int
+	vectors  []float32
+	ids      []string
+	metadata []map[string]string
+	byID     map[string]uint32
+	// links[node][layer] are the neighbours of node on layer.
+	links    [][][]uint32
+	entry    uint32
+	maxLevel int
+}
+
+// NewHNSW returns an empty index. Zero dims takes the dimensions of the
+// first vector added.
+func NewHNSW(dims int, config HNSWConfig) *HNSW {
+	if config.M <= 0 {
+		config.M = 16
+	}
+	config.M = max(config.M, 2)
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = 200
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = 64
+	}
+	return &HNSW{
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed^0x9e3779b97f4a7c15)),
+		dims:   dims,
+		byID:   make(map[string]uint32),
+	}
+}
+
+func (h *HNSW) vector(node uint32) []float32 {
+	return h.vectors[int(node)*h.dims : (int(node)+1)*h.dims]
+}
+
+func (h *HNSW) maxLinks(layer int) int {
+	if layer == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+func (h *HNSW) Add(id string, v []float32, metadata map[string]string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.byID[id]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateID, id)
+	}
+	unit, err := normalized(v, h.dims)
+	if err != nil {
+		return err
+	}
+	h.dims = len(unit)
+
+	node := uint32(len(h.ids))
+	level := min(int(-math.Log(1-h.rng.Float64())/math.Log(float64(h.config.M))), maxLevel)
+	h.byID[id] = node
+	h.ids = append(h.ids, id)
+	h.metadata = append(h.metadata, metadata)
+	h.vectors = append(h.vectors, unit...)
+	h.links = append(h.links, make([][]uint32, level+1))
+	if node == 0 {
+		h.entry, h.maxLevel = 0, level
+		return nil
+	}
+
+	entry := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.greedy(unit, entry, layer)
+	}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(unit, entry, h.config.EfConstruction, layer)
+		neighbours := candidates[:min(len(candidates), h.config.M)]
+		for _, n := range neighbours {
+			h.links[node][layer] = append(h.links[node][layer], n.node)
+			h.link(n.node, node, layer)
+		}
+		entry = candidates[0].node
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = node, level
+	}
+	return nil
+}
+
+// link adds to as a neighbour of from, keeping the closest neighbours when
+// from has too many.
+func (h *HNSW) link(from, to uint32, layer int) {
+	links := append(h.links[from][layer], to)
+	if len(links) > h.maxLinks(layer) {
+		base := h.vector(from)
+		slices.SortFunc(links, func(a, b uint32) int {
+			sa, sb := Dot(base, h.vector(a)), Dot(base, h.vector(b))
+			switch {
+			case sa > sb:
+				return -1
+			case sa < sb:
+				return 1
+			}
+			return 0
+		})
+		links = links[:h.maxLinks(layer)]
+	}
+	h.links[from][layer] = links
+}
+
+// greedy walks layer towards q and returns the closest node it finds.
+func (h *HNSW) greedy(q []float32, entry uint32, layer int) uint32 {
+	best := Dot(q, h.vector(entry))
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.links[entry][layer] {
+			if s := Dot(q, h.vector(n)); s > best {
+				best, entry, changed = s, n, true
+			}
+		}
+	}
+	return entry
+}
+
+// searchLayer returns up to ef nodes close to q on layer, best first.
+func (h *HNSW) searchLayer(q []float32, entry uint32, ef, layer int) []scored {
+	visited := make([]uint64, (len(h.ids)+63)/64)
+	visit := func(n uint32) bool {
+		word, bit := n/64, uint64(1)<<(n%64)
+		seen := visited[word]&bit != 0
+		visited[word] |= bit
+		return !seen
+	}
+	visit(entry)
+	start := scored{node: entry, score: Dot(q, h.vector(entry))}
+	candidates := maxHeap{start}
+	results := minHeap{start}
+	for candidates.Len() > 0 {
+		c := heap.Pop(&candidates).(scored)
+		if results.Len() >= ef && c.score < results[0].score {
+			break
+		}
+		for _, n := range h.links[c.node][layer] {
+			if !visit(n) {
+				continue
+			}
+			s := scored{node: n, score: Dot(q, h.vector(n))}
+			if results.Len() < ef || s.score > results[0].score {
+				heap.Push(&candidates, s)
+				results.offer(s, ef)
+			}
+		}
+	}
+	return results.sorted()
+}
+
+func (h *HNSW) Search(query []float32, k int) ([]Hit, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.ids) == 0 || k <= 0 {
+		return nil, nil
+	}
+	q, err := normalized(query, h.dims)
+	if err != nil {
+		return nil, err
+	}
+	entry := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.greedy(q, entry, layer)
+	}
+	best := h.searchLayer(q, entry, max(h.config.EfSearch, k), 0)
+	best = best[:min(len(best), k)]
+	hits := make([]Hit, len(best))
+	for i, s := range best {
+		hits[i] = Hit{ID: h.ids[s.node], Score: s.score, Metadata: h.metadata[s.node]}
+	}
+	return hits, nil
+}
+
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.ids)
+}
+
+func (h *HNSW) Dims() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.dims
+}
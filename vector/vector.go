@@ -0,0 +1,156 @@
+// Package vector searches embeddings in memory. Flat compares the query
+// with every vector; HNSW searches an approximate nearest-neighbour graph.
+// Both score by cosine similarity, are safe for concurrent use and save to a
+// compact binary file. Collection ties an index to an embeddings client so
+// documents and queries are embedded with the same model.
+package vector
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+var (
+	// ErrDimensions is returned for a vector whose length differs from the
+	// index's.
+	ErrDimensions = errors.New("vector: dimension mismatch")
+	// ErrDuplicateID is returned when adding an ID that is already indexed.
+	ErrDuplicateID = errors.New("vector: duplicate id")
+	// ErrZeroVector is returned for a vector that cannot be normalized.
+	ErrZeroVector = errors.New("vector: zero vector")
+)
+
+// Dot returns the dot product of a and b, which must have the same length.
+func Dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// Norm returns the Euclidean length of v.
+func Norm(v []float32) float32 {
+	return float32(math.Sqrt(float64(Dot(v, v))))
+}
+
+// Normalize scales v to unit length in place and returns it. A zero vector
+// is left unchanged.
+func Normalize(v []float32) []float32 {
+	n := Norm(v)
+	if n == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= n
+	}
+	return v
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 if either is zero.
+func Cosine(a, b []float32) float32 {
+	na, nb := Norm(a), Norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return Dot(a, b) / (na * nb)
+}
+
+// Hit is a search result. Score is the cosine similarity to the query.
+type Hit struct {
+	ID       string
+	Score    float32
+	Metadata map[string]string
+}
+
+// Index is a searchable set of vectors with IDs and metadata. Vectors are
+// normalized on the way in, so Search scores are cosine similarities.
+type Index interface {
+	// Add indexes v under id. The first vector fixes the dimensions of an
+	// index created with zero dimensions.
+	Add(id string, v []float32, metadata map[string]string) error
+	// Search returns up to k hits, most similar first.
+	Search(query []float32, k int) ([]Hit, error)
+	Len() int
+	Dims() int
+
+	kind() byte
+	encode(w *encoder)
+}
+
+// normalized returns a unit-length copy of v.
+func normalized(v []float32, dims int) ([]float32, error) {
+	if dims > 0 && len(v) != dims {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrDimensions, len(v), dims)
+	}
+	if len(v) == 0 || Norm(v) == 0 {
+		return nil, ErrZeroVector
+	}
+	return Normalize(slices.Clone(v)), nil
+}
+
+type scored struct {
+	node  uint32
+	score float32
+}
+
+// minHeap keeps the best results with the worst on top.
+type minHeap []scored
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(scored)) }
+
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// offer adds s if the heap holds fewer than k items or s beats the worst.
+func (h *minHeap) offer(s scored, k int) {
+	switch {
+	case h.Len() < k:
+		heap.Push(h, s)
+	case s.score > (*h)[0].score:
+		(*h)[0] = s
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted empties the heap and returns its items, best first.
+func (h *minHeap) sorted() []scored {
+	out := make([]scored, h.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(h).(scored)
+	}
+	return out
+}
+
+// maxHeap pops the best candidate first.
+type maxHeap []scored
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(scored)) }
+
+func (h *maxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,312 @@
+package vector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iamwavecut/gopenrouter"
+	"github.com/iamwavecut/gopenrouter/embeddings"
+)
+
+func randomVectors(n, dims int, seed uint64) [][]float32 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	out := make([][]float32, n)
+	for i := range out {
+		out[i] = make([]float32, dims)
+		for j := range out[i] {
+			out[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	return out
+}
+
+func TestVectorMath(t *testing.T) {
+	a := []float32{1, 2, 3, 4, 5}
+	b := []float32{5, 4, 3, 2, 1}
+	if got := Dot(a, b); got != 35 {
+		t.Fatalf("Dot = %v, want 35", got)
+	}
+	if got := Cosine(a, a); math.Abs(float64(got)-1) > 1e-6 {
+		t.Fatalf("Cosine(a, a) = %v", got)
+	}
+	if got := Cosine(a, make([]float32, 5)); got != 0 {
+		t.Fatalf("Cosine with zero = %v", got)
+	}
+	v := Normalize([]float32{3, 4})
+	if v[0] != 0.6 || v[1] != 0.8 {
+		t.Fatalf("Normalize = %v", v)
+	}
+}
+
+func TestVectorFlat(t *testing.T) {
+	index := NewFlat(0)
+	for i, v := range [][]float32{{1, 0}, {0, 1}, {1, 1}, {-1, 0}} {
+		if err := index.Add(fmt.Sprint(i), v, map[string]string{"n": fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := index.Add("0", []float32{1, 0}, nil); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+	if err := index.Add("x", []float32{1, 0, 0}, nil); !errors.Is(err, ErrDimensions) {
+		t.Fatalf("expected ErrDimensions, got %v", err)
+	}
+	if err := index.Add("x", []float32{0, 0}, nil); !errors.Is(err, ErrZeroVector) {
+		t.Fatalf("expected ErrZeroVector, got %v", err)
+	}
+
+	hits, err := index.Search([]float32{2, 0.1}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 3 || hits[0].ID != "0" || hits[1].ID != "2" || hits[2].ID != "1" || hits[0].Metadata["n"] != "0" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if hits[0].Score < hits[1].Score || hits[0].Score > 1.0001 {
+		t.Fatalf("scores not cosine similarities: %+v", hits)
+	}
+
+	if !index.Remove("0") || index.Remove("0") || index.Len() != 3 {
+		t.Fatal("Remove did not delete exactly once")
+	}
+	hits, _ = index.Search([]float32{1, 0}, 10)
+	if len(hits) != 3 || hits[0].ID != "2" || hits[2].ID != "3" {
+		t.Fatalf("unexpected hits after Remove: %+v", hits)
+	}
+}
+
+func TestVectorHNSWRecall(t *testing.T) {
+	const dims, n, k = 32, 2000, 10
+	data := randomVectors(n, dims, 1)
+	flat := NewFlat(dims)
+	hnsw := NewHNSW(dims, HNSWConfig{Seed: 7})
+	for i, v := range data {
+		id := fmt.Sprint(i)
+		if err := flat.Add(id, v, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := hnsw.Add(id, v, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queries := randomVectors(50, dims, 2)
+	var found, total int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, q := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			want, _ := flat.Search(q, k)
+			got, err := hnsw.Search(q, k)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids := map[string]bool{}
+			for _, h := range got {
+				ids[h.ID] = true
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, h := range want {
+				if ids[h.ID] {
+					found++
+				}
+			}
+			total += len(want)
+		}()
+	}
+	wg.Wait()
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Fatalf("recall %.2f, want >= 0.9", recall)
+	}
+}
+
+func TestVectorHNSWSmallM(t *testing.T) {
+	index := NewHNSW(0, HNSWConfig{M: 1})
+	for i, v := range [][]float32{{1, 0}, {0, 1}, {1, 1}} {
+		if err := index.Add(fmt.Sprint(i), v, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hits, err := index.Search([]float32{1, 0.1}, 1)
+	if err != nil || len(hits) != 1 || hits[0].ID != "0" {
+		t.Fatalf("unexpected hits: %+v, %v", hits, err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, index, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Read(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVectorSaveLoad(t *testing.T) {
+	data := randomVectors(300, 16, 3)
+	for _, index := range []Index{NewFlat(16), NewHNSW(16, HNSWConfig{M: 8, Seed: 1})} {
+		for i, v := range data {
+			if err := index.Add(fmt.Sprint(i), v, map[string]string{"i": fmt.Sprint(i), "a": "b"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, index, "test/embed"); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+		loaded, model, err := Read(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("%T: %v", index, err)
+		}
+		if model != "test/embed" || loaded.Len() != index.Len() || loaded.Dims() != 16 {
+			t.Fatalf("%T: loaded %q with %d vectors of %d dims", index, model, loaded.Len(), loaded.Dims())
+		}
+		for _, q := range randomVectors(5, 16, 4) {
+			want, _ := index.Search(q, 5)
+			got, _ := loaded.Search(q, 5)
+			if fmt.Sprint(want) != fmt.Sprint(got) {
+				t.Fatalf("%T: search differs after load:\n%v\n%v", index, want, got)
+			}
+		}
+		for _, n := range []int{0, 5, len(encoded) / 2, len(encoded) - 1} {
+			if _, _, err := Read(bytes.NewReader(encoded[:n])); err == nil {
+				t.Fatalf("%T: truncated at %d: expected an error", index, n)
+			}
+		}
+	}
+}
+
+// letterServer embeds each text as its letter counts, base64 encoded.
+func letterServer(t *testing.T, requests *[]embeddings.Request) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req embeddings.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		*requests = append(*requests, req)
+		mu.Unlock()
+		var texts []string
+		switch input := req.Input.(type) {
+		case string:
+			texts = []string{input}
+		case []any:
+			for _, s := range input {
+				texts = append(texts, s.(string))
+			}
+		}
+		resp := embeddings.Response{Object: "list", Model: req.Model}
+		for i, text := range texts {
+			v := make([]float32, 26)
+			for _, c := range strings.ToLower(text) {
+				if c >= 'a' && c <= 'z' {
+					v[c-'a']++
+				}
+			}
+			resp.Data = append(resp.Data, embeddings.Datum{Object: "embedding", Index: i, Embedding: embeddings.Value{Base64: embeddings.EncodeBase64Float32(v)}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestVectorCollection(t *testing.T) {
+	var requests []embeddings.Request
+	server := letterServer(t, &requests)
+	defer server.Close()
+	cfg := gopenrouter.DefaultConfig("test-token")
+	cfg.BaseURL = server.URL
+	client := embeddings.New(gopenrouter.NewClientWithConfig(cfg))
+	ctx := context.Background()
+
+	if _, err := NewCollection(client, embeddings.Request{}, nil, CollectionConfig{}); err == nil {
+		t.Fatal("expected an error without a model")
+	}
+	collection, err := NewCollection(client, embeddings.Request{Model: "test/embed"}, NewHNSW(0, HNSWConfig{}), CollectionConfig{
+		StoreText:         true,
+		DocumentInputType: "search_document",
+		QueryInputType:    "search_query",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = collection.Add(ctx,
+		Document{ID: "a", Text: "aaaa"},
+		Document{ID: "b", Text: "bbbb", Metadata: map[string]string{"source": "wiki"}},
+		Document{ID: "ab", Text: "abab"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits, err := collection.Query(ctx, "bbbab", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].ID != "b" || hits[0].Metadata["source"] != "wiki" || hits[0].Metadata[TextKey] != "bbbb" || hits[1].ID != "ab" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+	if len(requests) != 2 || requests[0].InputType != "search_document" || requests[1].InputType != "search_query" {
+		t.Fatalf("unexpected requests: %+v", requests)
+	}
+	for _, r := range requests {
+		if r.Model != "test/embed" || r.EncodingFormat != embeddings.EncodingBase64 {
+			t.Fatalf("request not using the collection model: %+v", r)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "index.bin")
+	if err := collection.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCollection(client, path, embeddings.Request{Model: "other/embed"}, CollectionConfig{}); err == nil {
+		t.Fatal("expected a model mismatch error")
+	}
+	loaded, err := LoadCollection(client, path, embeddings.Request{}, CollectionConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Model() != "test/embed" || loaded.Index().Len() != 3 {
+		t.Fatalf("unexpected collection: %s with %d documents", loaded.Model(), loaded.Index().Len())
+	}
+	hits, err = loaded.Query(ctx, "aaab", 1)
+	if err != nil || len(hits) != 1 || hits[0].ID != "a" {
+		t.Fatalf("unexpected hits after load: %+v, %v", hits, err)
+	}
+}
+
+func TestVectorReadRejectsDanglingLinks(t *testing.T) {
+	index := NewHNSW(0, HNSWConfig{M: 4, Seed: 1})
+	for i, v := range randomVectors(20, 4, 5) {
+		if err := index.Add(fmt.Sprint(i), v, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Link a layer the entry point has to a node that only exists on layer 0.
+	for n, layers := range index.links {
+		if len(layers) == 1 && uint32(n) != index.entry && index.maxLevel > 0 {
+			index.links[index.entry][1] = append(index.links[index.entry][1], uint32(n))
+			break
+		}
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, index, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Read(&buf); !errors.Is(err, errCorrupt) {
+		t.Fatalf("expected a corrupt index error, got %v", err)
+	}
+}